@@ -79,6 +79,7 @@ yaml_keys[spec.replaces]="$(metadata.get project.name).v$(metadata.get olm.repla
 
 declare -A vars
 vars[OCP_TARGET]="$(metadata.get 'requirements.ocp.[0]')"
+vars[VERSION]="$(metadata.get project.version)"
 
 function add_related_image {
   cat << EOF | yq write --inplace --script - "$1"
@@ -34,6 +34,13 @@ var (
 	log               = logf.Log.WithName("cmd")
 )
 
+var (
+	leaderElectionID = pflag.String("leader-election-id", "knative-serving-openshift-lock",
+		"The name of the ConfigMap that holds the leader election lock.")
+	leaderElectionNamespace = pflag.String("leader-election-namespace", "",
+		"The namespace of the leader election lock. Defaults to the namespace the operator runs in.")
+)
+
 func init() {
 	prodConf := zap.NewProductionEncoderConfig()
 	prodConf.EncodeTime = zapcore.ISO8601TimeEncoder
@@ -55,11 +62,12 @@ func main() {
 
 	// Create a new Cmd to provide shared dependencies and start components
 	mgr, err := manager.New(cfg, manager.Options{
-		Namespace:              "", // The serverless operator always watches all namespaces.
-		LeaderElection:         true,
-		LeaderElectionID:       "knative-serving-openshift-lock",
-		MetricsBindAddress:     fmt.Sprintf("%s:%d", metricsHost, metricsPort),
-		HealthProbeBindAddress: fmt.Sprintf(":%d", healthPort),
+		Namespace:               "", // The serverless operator always watches all namespaces.
+		LeaderElection:          true,
+		LeaderElectionID:        *leaderElectionID,
+		LeaderElectionNamespace: *leaderElectionNamespace,
+		MetricsBindAddress:      fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		HealthProbeBindAddress:  fmt.Sprintf(":%d", healthPort),
 	})
 	if err != nil {
 		log.Error(err, "")
@@ -147,5 +155,17 @@ func setupMonitoring(cfg *rest.Config) error {
 	if err := common.InstallHealthDashboard(cl); err != nil {
 		return fmt.Errorf("failed to setup the Knative Health Status Dashboard: %w", err)
 	}
+
+	if err := common.SetupIngressServiceMonitor(cl, operatorDeployment); err != nil {
+		return fmt.Errorf("failed to setup the ingress Service monitor: %w", err)
+	}
+
+	alerts, err := common.NewPrometheusRuleGenerator(cl, operatorDeployment)
+	if err != nil {
+		return err
+	}
+	if err := alerts.Apply(); err != nil {
+		return fmt.Errorf("failed to setup the route alerts: %w", err)
+	}
 	return nil
 }
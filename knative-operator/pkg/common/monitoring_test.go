@@ -38,12 +38,12 @@ func TestSetupMonitoringRequirements(t *testing.T) {
 	cl := fake.NewFakeClient(initObjs...)
 	err := SetupMonitoringRequirements(cl, &serverlessDeployment)
 	if err != nil {
-		t.Errorf("Failed to set up monitoring requirements: %w", err)
+		t.Errorf("Failed to set up monitoring requirements: %v", err)
 	}
 	ns := corev1.Namespace{}
 	err = cl.Get(context.TODO(), client.ObjectKey{Name: installedNS}, &ns)
 	if err != nil {
-		t.Errorf("Failed to get modified namespace: %w", err)
+		t.Errorf("Failed to get modified namespace: %v", err)
 	}
 	if actual := ns.Labels[monitoringLabel]; actual != "true" {
 		t.Errorf("got %q, want %q", actual, "true")
@@ -51,7 +51,7 @@ func TestSetupMonitoringRequirements(t *testing.T) {
 	role := v1.Role{}
 	err = cl.Get(context.TODO(), client.ObjectKey{Name: "knative-serving-prometheus-k8s", Namespace: installedNS}, &role)
 	if err != nil {
-		t.Errorf("Failed to get created role: %w", err)
+		t.Errorf("Failed to get created role: %v", err)
 	}
 	if len(role.Rules) == 0 {
 		t.Error("Rules should be non empty")
@@ -59,7 +59,7 @@ func TestSetupMonitoringRequirements(t *testing.T) {
 	rb := v1.RoleBinding{}
 	err = cl.Get(context.TODO(), client.ObjectKey{Name: "knative-serving-prometheus-k8s", Namespace: installedNS}, &rb)
 	if err != nil {
-		t.Errorf("Failed to get created rolebinding: %w", err)
+		t.Errorf("Failed to get created rolebinding: %v", err)
 	}
 	if len(rb.Subjects) == 0 {
 		t.Error("Subjects should be non empty")
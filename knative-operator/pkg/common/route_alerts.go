@@ -0,0 +1,140 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RouteAlertsName is the name of the PrometheusRule alerting on the Routes generated for
+	// Knative Ingresses.
+	RouteAlertsName = "serverless-operator-route-alerts"
+
+	// operatorVersionEnvKey contains the version of the Openshift serverless operator.
+	operatorVersionEnvKey = "OPERATOR_VERSION"
+
+	// operatorVersionAnnotation records the version of the operator that generated a resource.
+	operatorVersionAnnotation = "operator.knative.openshift.io/version"
+
+	// ingressMetricsPrefix is the prefix of the metrics of the OpenShift ingress controller.
+	ingressMetricsPrefix = "openshift_ingress_controller_"
+)
+
+var logr = Log.WithName("route alerts")
+
+// PrometheusRuleGenerator generates the PrometheusRule alerting on the Routes generated for
+// Knative Ingresses. The rule is only updated when the version of the operator changes, so that
+// tweaks of the thresholds survive restarts of the operator until the next upgrade.
+type PrometheusRuleGenerator struct {
+	client    client.Client
+	namespace string
+	version   string
+	owner     *appsv1.Deployment
+}
+
+// NewPrometheusRuleGenerator returns a generator creating the PrometheusRule in the namespace of
+// the given operator Deployment, which owns the rule.
+func NewPrometheusRuleGenerator(api client.Client, owner *appsv1.Deployment) (*PrometheusRuleGenerator, error) {
+	version, found := os.LookupEnv(operatorVersionEnvKey)
+	if !found {
+		return nil, fmt.Errorf("the environment variable %q must be set", operatorVersionEnvKey)
+	}
+	return &PrometheusRuleGenerator{
+		client:    api,
+		namespace: owner.Namespace,
+		version:   version,
+		owner:     owner,
+	}, nil
+}
+
+// Apply creates the PrometheusRule, or updates it if it was generated by another version of
+// the operator.
+func (g *PrometheusRuleGenerator) Apply() error {
+	desired := MakeRouteAlerts(g.namespace, g.version)
+	desired.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(g.owner, g.owner.GroupVersionKind())}
+
+	existing := &monitoringv1.PrometheusRule{}
+	err := g.client.Get(context.TODO(), client.ObjectKey{Namespace: g.namespace, Name: RouteAlertsName}, existing)
+	if meta.IsNoMatchError(err) {
+		logr.Info("Install prometheus-operator in your cluster to create PrometheusRule objects")
+		return nil
+	} else if apierrs.IsNotFound(err) {
+		logr.Info("Creating route alerts", "version", g.version)
+		if err := g.client.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("failed to create PrometheusRule %s: %w", RouteAlertsName, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch PrometheusRule %s: %w", RouteAlertsName, err)
+	}
+
+	if existing.Annotations[operatorVersionAnnotation] == g.version {
+		return nil
+	}
+	updated := existing.DeepCopy()
+	updated.Spec = desired.Spec
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string, 1)
+	}
+	updated.Annotations[operatorVersionAnnotation] = g.version
+	logr.Info("Updating route alerts", "from", existing.Annotations[operatorVersionAnnotation], "to", g.version)
+	if err := g.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update PrometheusRule %s: %w", RouteAlertsName, err)
+	}
+	return nil
+}
+
+// MakeRouteAlerts returns the PrometheusRule alerting on the Routes generated for Knative
+// Ingresses, based on the metrics of the OpenShift ingress controller.
+func MakeRouteAlerts(namespace, version string) *monitoringv1.PrometheusRule {
+	return &monitoringv1.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        RouteAlertsName,
+			Namespace:   namespace,
+			Annotations: map[string]string{operatorVersionAnnotation: version},
+		},
+		Spec: monitoringv1.PrometheusRuleSpec{
+			Groups: []monitoringv1.RuleGroup{{
+				Name: "knative-routes",
+				Rules: []monitoringv1.Rule{{
+					Alert: "KnativeRouteFailureRateHigh",
+					Expr: intstr.FromString(fmt.Sprintf(`sum(%[1]sroutes{state="failed"}) / sum(%[1]sroutes) > 0.05`,
+						ingressMetricsPrefix)),
+					For:    "10m",
+					Labels: map[string]string{"severity": "warning"},
+					Annotations: map[string]string{
+						"message": "More than 5% of the Routes generated for Knative Services have been rejected by the router.",
+					},
+				}, {
+					Alert: "KnativeRouteAdmissionSlow",
+					Expr: intstr.FromString(fmt.Sprintf(`histogram_quantile(0.99, sum by (le) (rate(%sroute_admission_latencies_bucket[10m]))) > 60`,
+						ingressMetricsPrefix)),
+					For:    "10m",
+					Labels: map[string]string{"severity": "warning"},
+					Annotations: map[string]string{
+						"message": "Routers take more than 60 seconds to admit Routes generated for Knative Services.",
+					},
+				}, {
+					// Orphaned Routes are garbage collected every 10 minutes by default, so they
+					// only pile up if the garbage collection fails.
+					Alert:  "KnativeOrphanRoutes",
+					Expr:   intstr.FromString(fmt.Sprintf(`sum(%sorphan_routes) > 10`, ingressMetricsPrefix)),
+					For:    "30m",
+					Labels: map[string]string{"severity": "warning"},
+					Annotations: map[string]string{
+						"message": "More than 10 Routes generated for Knative Services outlived their Ingress and were not garbage collected.",
+					},
+				}},
+			}},
+		},
+	}
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	monitoringv1.AddToScheme(scheme.Scheme)
+}
+
+func operatorDeployment() *appsv1.Deployment {
+	d := serverlessDeployment.DeepCopy()
+	d.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	return d
+}
+
+func TestPrometheusRuleGenerator(t *testing.T) {
+	tweaked := MakeRouteAlerts(installedNS, "1.13.0")
+	tweaked.Spec.Groups[0].Rules[0].For = "1h"
+
+	tests := []struct {
+		name     string
+		version  string
+		existing *monitoringv1.PrometheusRule
+		wantFor  string
+	}{{
+		name:    "create",
+		version: "1.13.0",
+		wantFor: "10m",
+	}, {
+		name:     "same version keeps tweaks",
+		version:  "1.13.0",
+		existing: tweaked,
+		wantFor:  "1h",
+	}, {
+		name:     "upgrade",
+		version:  "1.14.0",
+		existing: tweaked,
+		wantFor:  "10m",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			os.Setenv(operatorVersionEnvKey, test.version)
+			defer os.Unsetenv(operatorVersionEnvKey)
+
+			var objs []runtime.Object
+			if test.existing != nil {
+				objs = append(objs, test.existing.DeepCopy())
+			}
+			cl := fake.NewFakeClient(objs...)
+
+			generator, err := NewPrometheusRuleGenerator(cl, operatorDeployment())
+			if err != nil {
+				t.Fatalf("NewPrometheusRuleGenerator() = %v", err)
+			}
+			if err := generator.Apply(); err != nil {
+				t.Fatalf("Apply() = %v", err)
+			}
+
+			got := &monitoringv1.PrometheusRule{}
+			if err := cl.Get(context.TODO(), client.ObjectKey{Namespace: installedNS, Name: RouteAlertsName}, got); err != nil {
+				t.Fatalf("Failed to get PrometheusRule: %v", err)
+			}
+			if v := got.Annotations[operatorVersionAnnotation]; v != test.version {
+				t.Errorf("version = %q, want: %q", v, test.version)
+			}
+			if f := got.Spec.Groups[0].Rules[0].For; f != test.wantFor {
+				t.Errorf("for = %q, want: %q", f, test.wantFor)
+			}
+			if test.existing == nil && len(got.OwnerReferences) != 1 {
+				t.Errorf("OwnerReferences = %v, want the operator deployment", got.OwnerReferences)
+			}
+		})
+	}
+}
+
+func TestPrometheusRuleGeneratorWithoutVersion(t *testing.T) {
+	os.Unsetenv(operatorVersionEnvKey)
+	if _, err := NewPrometheusRuleGenerator(fake.NewFakeClient(), operatorDeployment()); err == nil {
+		t.Error("NewPrometheusRuleGenerator() = nil, want an error without a version")
+	}
+}
+
+func TestSetupIngressServiceMonitor(t *testing.T) {
+	os.Setenv(TestIngressServiceMonitorPath, "testdata/ingress-service-monitor.yaml")
+	defer os.Unsetenv(TestIngressServiceMonitorPath)
+
+	cl := fake.NewFakeClient()
+	if err := SetupIngressServiceMonitor(cl, operatorDeployment()); err != nil {
+		t.Fatalf("SetupIngressServiceMonitor() = %v", err)
+	}
+
+	key := client.ObjectKey{Namespace: installedNS, Name: "knative-openshift-ingress-metrics"}
+	svc := &corev1.Service{}
+	if err := cl.Get(context.TODO(), key, svc); err != nil {
+		t.Fatalf("Failed to get Service: %v", err)
+	}
+	if got := svc.Spec.Selector["name"]; got != "knative-openshift-ingress" {
+		t.Errorf("Selector = %q, want the ingress controller", got)
+	}
+	if err := cl.Get(context.TODO(), key, &monitoringv1.ServiceMonitor{}); err != nil {
+		t.Errorf("Failed to get ServiceMonitor: %v", err)
+	}
+}
@@ -28,10 +28,12 @@ const (
 	EventingBrokerServiceMonitorPath     = "deploy/resources/broker-service-monitors.yaml"
 	EventingSourceServiceMonitorPath     = "deploy/resources/source-service-monitor.yaml"
 	EventingSourcePath                   = "deploy/resources/source-service.yaml"
+	IngressServiceMonitorPath            = "deploy/resources/ingress-service-monitor.yaml"
 	SourceLabel                          = "eventing.knative.dev/source"
 	SourceNameLabel                      = "eventing.knative.dev/sourceName"
 	SourceRoleLabel                      = "sources.knative.dev/role"
 	TestEventingBrokerServiceMonitorPath = "TEST_EVENTING_BROKER_SERVICE_MONITOR_PATH"
+	TestIngressServiceMonitorPath        = "TEST_INGRESS_SERVICE_MONITOR_PATH"
 	TestMonitor                          = "TEST_MONITOR"
 	TestSourceServiceMonitorPath         = "TEST_SOURCE_SERVICE_MONITOR_PATH"
 	TestSourceServicePath                = "TEST_SOURCE_SERVICE_PATH"
@@ -85,6 +87,23 @@ func SetupEventingBrokerServiceMonitors(client client.Client, instance *eventing
 	return nil
 }
 
+// SetupIngressServiceMonitor exposes the metrics of the OpenShift ingress controller, which runs
+// next to the given operator Deployment, to Prometheus. The route alerts are based on them.
+func SetupIngressServiceMonitor(client client.Client, owner *appsv1.Deployment) error {
+	manifest, err := mf.NewManifest(getMonitorPath(TestIngressServiceMonitorPath, IngressServiceMonitorPath), mf.UseClient(mfclient.NewClient(client)))
+	if err != nil {
+		return fmt.Errorf("unable to parse ingress service monitor: %w", err)
+	}
+	transforms := []mf.Transformer{mf.InjectOwner(owner), mf.InjectNamespace(owner.Namespace)}
+	if manifest, err = manifest.Transform(transforms...); err != nil {
+		return fmt.Errorf("unable to transform ingress service monitor manifest: %w", err)
+	}
+	if err := manifest.Apply(); err != nil {
+		return fmt.Errorf("unable to create ingress service monitor: %w", err)
+	}
+	return nil
+}
+
 func SetupSourceServiceMonitor(client client.Client, instance *appsv1.Deployment) error {
 	labels := instance.Spec.Selector.MatchLabels
 
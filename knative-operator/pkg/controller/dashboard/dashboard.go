@@ -22,6 +22,7 @@ const EventingResourceDashboardPathEnvVar = "EVENTING_RESOURCES_DASHBOARD_MANIFE
 const EventingBrokerDashboardPathEnvVar = "EVENTING_BROKER_DASHBOARD_MANIFEST_PATH"
 const EventingSourceDashboardPathEnvVar = "EVENTING_SOURCE_DASHBOARD_MANIFEST_PATH"
 const ServingResourceDashboardPathEnvVar = "SERVING_RESOURCES_DASHBOARD_MANIFEST_PATH"
+const ServingRoutesDashboardPathEnvVar = "SERVING_ROUTES_DASHBOARD_MANIFEST_PATH"
 
 // Apply applies dashboard resources.
 func Apply(path string, instance operatorv1alpha1.KComponent, api client.Client) error {
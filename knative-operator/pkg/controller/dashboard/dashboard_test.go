@@ -0,0 +1,83 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	mf "github.com/manifestival/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// TestDashboardsAreValidJSON verifies that the dashboards shipped with the operator parse, as the
+// console silently drops invalid ones.
+func TestDashboardsAreValidJSON(t *testing.T) {
+	paths, err := filepath.Glob("../../../deploy/resources/dashboards/*.yaml")
+	if err != nil {
+		t.Fatal("Failed to list dashboards:", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("Found no dashboards")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			manifest, err := mf.NewManifest(path)
+			if err != nil {
+				t.Fatal("Failed to read manifest:", err)
+			}
+			for _, resource := range manifest.Filter(mf.ByKind("ConfigMap")).Resources() {
+				data, _, err := unstructured.NestedStringMap(resource.Object, "data")
+				if err != nil {
+					t.Fatalf("Failed to read data of ConfigMap %s: %v", resource.GetName(), err)
+				}
+				if len(data) == 0 {
+					t.Errorf("ConfigMap %s contains no dashboard", resource.GetName())
+				}
+				for key, dashboard := range data {
+					if !json.Valid([]byte(dashboard)) {
+						t.Errorf("Dashboard %s of ConfigMap %s is not valid JSON", key, resource.GetName())
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRoutesDashboard(t *testing.T) {
+	manifest, err := mf.NewManifest("../../../deploy/resources/dashboards/grafana-dash-knative-serving-routes.yaml")
+	if err != nil {
+		t.Fatal("Failed to read manifest:", err)
+	}
+	resources := manifest.Resources()
+	if len(resources) != 1 {
+		t.Fatalf("Got %d resources, want a single ConfigMap", len(resources))
+	}
+	cm := resources[0]
+	if got, want := cm.GetLabels()["console.openshift.io/dashboard"], "true"; got != want {
+		t.Errorf("Dashboard label = %q, want: %q", got, want)
+	}
+
+	raw, _, _ := unstructured.NestedString(cm.Object, "data", "routes-dashboard.json")
+	var dashboard struct {
+		Panels []struct {
+			Title string `json:"title"`
+		} `json:"panels"`
+	}
+	if err := json.Unmarshal([]byte(raw), &dashboard); err != nil {
+		t.Fatal("Failed to parse dashboard:", err)
+	}
+	var titles []string
+	for _, panel := range dashboard.Panels {
+		titles = append(titles, panel.Title)
+	}
+	want := []string{"Route Creation Rate (per second)", "Route Admission Latency", "Route Error Rate", "Routes by State"}
+	if len(titles) != len(want) {
+		t.Fatalf("Panels = %v, want: %v", titles, want)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Errorf("Panel %d = %q, want: %q", i, titles[i], want[i])
+		}
+	}
+}
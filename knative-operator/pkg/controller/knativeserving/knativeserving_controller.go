@@ -9,6 +9,7 @@ import (
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/dashboard"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/knativeserving/consoleclidownload"
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/knativeserving/kourier"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/knativeserving/openshiftingress"
 	consolev1 "github.com/openshift/api/console/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -94,6 +95,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to the destination CAs rendered into the route generation config
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, openshiftingress.EnqueueRequestsForDestinationCA(mgr.GetClient()))
+	if err != nil {
+		return err
+	}
+
 	// Load Kourier resources to watch them
 	kourierManifest, err := kourier.RawManifest(mgr.GetClient())
 	if err != nil {
@@ -168,6 +175,7 @@ func (r *ReconcileKnativeServing) reconcileKnativeServing(instance *servingv1alp
 		r.configure,
 		r.ensureFinalizers,
 		r.ensureCustomCertsConfigMap,
+		r.configureRouteGeneration,
 		r.installKourier,
 		r.installDashboard,
 		r.ensureProxySettings,
@@ -347,14 +355,28 @@ func (r *ReconcileKnativeServing) installKourier(instance *servingv1alpha1.Knati
 	return nil
 }
 
+// configureRouteGeneration propagates the route generation config to the OpenShift ingress controller
+func (r *ReconcileKnativeServing) configureRouteGeneration(instance *servingv1alpha1.KnativeServing) error {
+	if err := openshiftingress.Apply(instance, r.client); err != nil {
+		// Surface problems the webhook can't catch, like a missing destination CA.
+		instance.Status.MarkDependencyMissing(fmt.Sprintf("route generation config: %v", err))
+		return err
+	}
+	return nil
+}
+
 // installKnConsoleCLIDownload creates CR for kn CLI download link
 func (r *ReconcileKnativeServing) installKnConsoleCLIDownload(instance *servingv1alpha1.KnativeServing) error {
 	return consoleclidownload.Apply(instance, r.client, r.scheme)
 }
 
-// installDashboard installs dashboard for OpenShift webconsole
+// installDashboard installs dashboards for OpenShift webconsole
 func (r *ReconcileKnativeServing) installDashboard(instance *servingv1alpha1.KnativeServing) error {
-	return dashboard.Apply(os.Getenv(dashboard.ServingResourceDashboardPathEnvVar), instance, r.client)
+	if err := dashboard.Apply(os.Getenv(dashboard.ServingResourceDashboardPathEnvVar), instance, r.client); err != nil {
+		return err
+	}
+	// The Routes dashboard shows the metrics of the controller generating Routes for Ingresses.
+	return dashboard.Apply(os.Getenv(dashboard.ServingRoutesDashboardPathEnvVar), instance, r.client)
 }
 
 // general clean-up, mostly resources in different namespaces from servingv1alpha1.KnativeServing.
@@ -378,10 +400,18 @@ func (r *ReconcileKnativeServing) delete(instance *servingv1alpha1.KnativeServin
 		return fmt.Errorf("failed to delete kourier: %w", err)
 	}
 
+	log.Info("Deleting route generation config")
+	if err := openshiftingress.Delete(instance, r.client); err != nil {
+		return fmt.Errorf("failed to delete route generation config: %w", err)
+	}
+
 	log.Info("Deleting dashboard")
 	if err := dashboard.Delete(os.Getenv(dashboard.ServingResourceDashboardPathEnvVar), instance, r.client); err != nil {
 		return fmt.Errorf("failed to delete dashboard configmap: %w", err)
 	}
+	if err := dashboard.Delete(os.Getenv(dashboard.ServingRoutesDashboardPathEnvVar), instance, r.client); err != nil {
+		return fmt.Errorf("failed to delete routes dashboard configmap: %w", err)
+	}
 
 	// The above might take a while, so we refetch the resource again in case it has changed.
 	refetched := &servingv1alpha1.KnativeServing{}
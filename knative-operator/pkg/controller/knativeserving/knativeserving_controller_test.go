@@ -114,6 +114,7 @@ func init() {
 	os.Setenv("OPERATOR_NAME", "TEST_OPERATOR")
 	os.Setenv("KOURIER_MANIFEST_PATH", "kourier/testdata/kourier-latest.yaml")
 	os.Setenv(dashboard.ServingResourceDashboardPathEnvVar, "../dashboard/testdata/grafana-dash-knative-serving-resources.yaml")
+	os.Setenv(dashboard.ServingRoutesDashboardPathEnvVar, "../dashboard/testdata/grafana-dash-knative-serving-routes.yaml")
 
 	apis.AddToScheme(scheme.Scheme)
 }
@@ -183,6 +184,13 @@ func TestKourierReconcile(t *testing.T) {
 				t.Fatalf("get: (%v)", err)
 			}
 
+			// Check if Serving routes dashboard configmap is available
+			routesDashboardCM := &corev1.ConfigMap{}
+			err = cl.Get(context.TODO(), types.NamespacedName{Name: "grafana-dashboard-definition-knative-serving-routes", Namespace: ns.Name}, routesDashboardCM)
+			if err != nil {
+				t.Fatalf("get: (%v)", err)
+			}
+
 			// Delete Kourier deployment.
 			err = cl.Delete(context.TODO(), deploy)
 			if err != nil {
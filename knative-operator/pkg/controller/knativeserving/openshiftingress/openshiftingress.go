@@ -0,0 +1,250 @@
+package openshiftingress
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	ingressconfig "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ConfigKey is the key in spec.config of KnativeServing that configures route generation of
+// the OpenShift ingress controller. Its values end up in the config-openshift-ingress ConfigMap.
+// Like for the ConfigMaps of Knative Serving, the "config-" prefix is optional.
+const ConfigKey = "openshift-ingress"
+
+const (
+	// defaultsConfigKey is the key in spec.config of the defaults of Knative Serving.
+	defaultsConfigKey = "defaults"
+	// maxRevisionTimeoutKey is the maximum timeout of revisions in the defaults of Knative Serving.
+	maxRevisionTimeoutKey = "max-revision-timeout-seconds"
+	// defaultRouteTimeoutKey is the timeout of Routes whose Ingress doesn't set one.
+	defaultRouteTimeoutKey = "default-route-timeout"
+
+	// destinationCAConfigMapKey references a ConfigMap in the namespace of KnativeServing that
+	// contains the CA the router verifies the gateway with on reencrypt, under the key set by
+	// destinationCAKeyKey. The operator renders the CA into destinationCACertificateKey, which
+	// the controller reads. The ConfigMap created for spec.controller-custom-certs, whose
+	// service CA is injected by OpenShift, can be referenced for example.
+	destinationCAConfigMapKey   = "destination-ca-configmap"
+	destinationCAKeyKey         = "destination-ca-key"
+	destinationCACertificateKey = "destination-ca-certificate"
+	defaultDestinationCAKey     = "service-ca.crt"
+)
+
+var log = common.Log.WithName("openshiftingress")
+
+// Validate checks the route generation config of the given KnativeServing.
+func Validate(instance *servingv1alpha1.KnativeServing) error {
+	data, ok := section(instance, ConfigKey)
+	if !ok {
+		return nil
+	}
+	if _, err := ingressconfig.NewIngressFromConfigMap(&corev1.ConfigMap{Data: data}); err != nil {
+		return fmt.Errorf("invalid spec.config.%s: %w", ConfigKey, err)
+	}
+	_, inline := data[destinationCACertificateKey]
+	_, referenced := data[destinationCAConfigMapKey]
+	if inline && referenced {
+		return fmt.Errorf("invalid spec.config.%s: only one of %s and %s can be set",
+			ConfigKey, destinationCACertificateKey, destinationCAConfigMapKey)
+	}
+	if _, ok := data[destinationCAKeyKey]; ok && !referenced {
+		return fmt.Errorf("invalid spec.config.%s: %s requires %s", ConfigKey, destinationCAKeyKey, destinationCAConfigMapKey)
+	}
+	return nil
+}
+
+// resolveDestinationCA replaces the reference to the ConfigMap containing the destination CA in
+// the given data with the CA itself. The ConfigMap is looked up in the namespace of the given
+// KnativeServing.
+func resolveDestinationCA(instance *servingv1alpha1.KnativeServing, api client.Client, data map[string]string) error {
+	name, ok := data[destinationCAConfigMapKey]
+	if !ok {
+		return nil
+	}
+	key := data[destinationCAKeyKey]
+	if key == "" {
+		key = defaultDestinationCAKey
+	}
+	delete(data, destinationCAConfigMapKey)
+	delete(data, destinationCAKeyKey)
+
+	cm := &corev1.ConfigMap{}
+	if err := api.Get(context.TODO(), client.ObjectKey{Namespace: instance.Namespace, Name: name}, cm); err != nil {
+		return fmt.Errorf("failed to fetch destination CA ConfigMap %s: %w", name, err)
+	}
+	ca, ok := cm.Data[key]
+	if !ok || ca == "" {
+		return fmt.Errorf("destination CA ConfigMap %s doesn't contain key %s", name, key)
+	}
+	data[destinationCACertificateKey] = ca
+	if _, err := ingressconfig.NewIngressFromConfigMap(&corev1.ConfigMap{Data: data}); err != nil {
+		return fmt.Errorf("invalid destination CA in ConfigMap %s: %w", name, err)
+	}
+	return nil
+}
+
+// desiredData returns the route generation config of the given KnativeServing, or nil if it
+// doesn't configure route generation.
+//
+// The timeout of Routes whose Ingress doesn't set one is taken from, in order of precedence:
+// default-route-timeout in spec.config.openshift-ingress, max-revision-timeout-seconds in
+// spec.config.defaults and the built-in default of the controller. This keeps Routes from
+// timing out requests that revisions are still allowed to serve, unless a different edge
+// timeout is set explicitly.
+func desiredData(instance *servingv1alpha1.KnativeServing) map[string]string {
+	config, configured := section(instance, ConfigKey)
+	data := make(map[string]string, len(config)+1)
+	for k, v := range config {
+		data[k] = v
+	}
+	if _, ok := data[defaultRouteTimeoutKey]; !ok {
+		defaults, _ := section(instance, defaultsConfigKey)
+		// Invalid values are rejected by Knative Serving, they aren't ours to report.
+		if seconds, err := strconv.Atoi(defaults[maxRevisionTimeoutKey]); err == nil && seconds > 0 {
+			data[defaultRouteTimeoutKey] = strconv.Itoa(seconds)
+			configured = true
+		}
+	}
+	if !configured {
+		return nil
+	}
+	return data
+}
+
+// section returns the given section of spec.config, with or without the "config-" prefix. The
+// prefixed one wins if both are set, as with Knative Serving's own ConfigMaps.
+func section(instance *servingv1alpha1.KnativeServing, key string) (map[string]string, bool) {
+	if data, ok := instance.Spec.Config["config-"+key]; ok {
+		return data, true
+	}
+	data, ok := instance.Spec.Config[key]
+	return data, ok
+}
+
+// EnqueueRequestsForDestinationCA returns an event handler enqueueing the KnativeServings that
+// reference the changed ConfigMap as their destination CA. Apply renders the CA into the config of
+// the controller, so it has to run again whenever the CA is rotated.
+func EnqueueRequestsForDestinationCA(api client.Client) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(func(obj handler.MapObject) []reconcile.Request {
+		list := &servingv1alpha1.KnativeServingList{}
+		if err := api.List(context.TODO(), list, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+			log.Error(err, "Failed to list KnativeServings referencing destination CA", "namespace", obj.Meta.GetNamespace(), "name", obj.Meta.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range list.Items {
+			data, _ := section(&list.Items[i], ConfigKey)
+			if data[destinationCAConfigMapKey] == obj.Meta.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: list.Items[i].Namespace,
+					Name:      list.Items[i].Name,
+				}})
+			}
+		}
+		return requests
+	})}
+}
+
+// Apply propagates the route generation config of the given KnativeServing to the ConfigMap of
+// the OpenShift ingress controller. A ConfigMap created by a previous Apply is deleted once the
+// config is removed from the KnativeServing, ConfigMaps created by hand are left alone.
+func Apply(instance *servingv1alpha1.KnativeServing, api client.Client) error {
+	data := desiredData(instance)
+	if data == nil {
+		return Delete(instance, api)
+	}
+	if err := Validate(instance); err != nil {
+		return err
+	}
+	if err := resolveDestinationCA(instance, api, data); err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := api.Get(context.TODO(), key(), cm)
+	if apierrors.IsNotFound(err) {
+		cm.Name = ingressconfig.IngressConfigName
+		cm.Namespace = namespace()
+		cm.Annotations = ownerAnnotations(instance)
+		cm.Data = data
+		log.Info("Creating route generation config", "namespace", cm.Namespace)
+		if err := api.Create(context.TODO(), cm); err != nil {
+			return fmt.Errorf("failed to create %s: %w", ingressconfig.IngressConfigName, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", ingressconfig.IngressConfigName, err)
+	}
+
+	if equality.Semantic.DeepEqual(cm.Data, data) && ownedBy(cm, instance) {
+		return nil
+	}
+	// Taking over a ConfigMap created by hand is intended, the KnativeServing is the source of truth.
+	updated := cm.DeepCopy()
+	updated.Data = data
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string, 2)
+	}
+	for k, v := range ownerAnnotations(instance) {
+		updated.Annotations[k] = v
+	}
+	log.Info("Updating route generation config", "namespace", cm.Namespace)
+	if err := api.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update %s: %w", ingressconfig.IngressConfigName, err)
+	}
+	return nil
+}
+
+// Delete deletes the ConfigMap of the OpenShift ingress controller, if it was created for the
+// given KnativeServing. The controller falls back to its defaults.
+func Delete(instance *servingv1alpha1.KnativeServing, api client.Client) error {
+	cm := &corev1.ConfigMap{}
+	err := api.Get(context.TODO(), key(), cm)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", ingressconfig.IngressConfigName, err)
+	}
+	if !ownedBy(cm, instance) {
+		return nil
+	}
+	log.Info("Deleting route generation config", "namespace", cm.Namespace)
+	if err := api.Delete(context.TODO(), cm); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s: %w", ingressconfig.IngressConfigName, err)
+	}
+	return nil
+}
+
+// namespace returns the namespace of the operator, which the OpenShift ingress controller is
+// deployed to as well.
+func namespace() string {
+	return os.Getenv(common.NamespaceEnvKey)
+}
+
+func key() client.ObjectKey {
+	return client.ObjectKey{Namespace: namespace(), Name: ingressconfig.IngressConfigName}
+}
+
+func ownerAnnotations(instance *servingv1alpha1.KnativeServing) map[string]string {
+	return map[string]string{
+		common.ServingOwnerName:      instance.Name,
+		common.ServingOwnerNamespace: instance.Namespace,
+	}
+}
+
+func ownedBy(cm *corev1.ConfigMap, instance *servingv1alpha1.KnativeServing) bool {
+	return cm.Annotations[common.ServingOwnerName] == instance.Name &&
+		cm.Annotations[common.ServingOwnerNamespace] == instance.Namespace
+}
@@ -0,0 +1,286 @@
+package openshiftingress
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	ingressconfig "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const operatorNamespace = "openshift-serverless"
+
+func init() {
+	os.Setenv(common.NamespaceEnvKey, operatorNamespace)
+}
+
+func knativeServing(config map[string]string) *servingv1alpha1.KnativeServing {
+	ks := &servingv1alpha1.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "knative-serving",
+			Namespace: "knative-serving",
+		},
+	}
+	if config != nil {
+		ks.Spec.Config = map[string]map[string]string{ConfigKey: config}
+	}
+	return ks
+}
+
+func configMap(data map[string]string, annotations map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ingressconfig.IngressConfigName,
+			Namespace:   operatorNamespace,
+			Annotations: annotations,
+		},
+		Data: data,
+	}
+}
+
+const testCA = `-----BEGIN CERTIFICATE-----
+MIIBszCCAVmgAwIBAgIUTEST
+-----END CERTIFICATE-----
+`
+
+// serviceCA is a ConfigMap the service CA of OpenShift has been injected into.
+var serviceCA = &corev1.ConfigMap{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "serving-certs-service-ca",
+		Namespace: "knative-serving",
+	},
+	Data: map[string]string{
+		defaultDestinationCAKey: testCA,
+		"invalid.crt":           "not a certificate",
+	},
+}
+
+var owned = map[string]string{
+	common.ServingOwnerName:      "knative-serving",
+	common.ServingOwnerNamespace: "knative-serving",
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr bool
+	}{{
+		name: "no config",
+	}, {
+		name:   "valid",
+		config: map[string]string{"skip-list": "*/*", "http2-enabled": "true"},
+	}, {
+		name:    "invalid skip-list",
+		config:  map[string]string{"skip-list": "no-namespace"},
+		wantErr: true,
+	}, {
+		name:    "invalid exposure mode",
+		config:  map[string]string{"exposure-mode": "nodeport"},
+		wantErr: true,
+	}, {
+		name:   "tls defaults",
+		config: map[string]string{"default-tls-termination": "reencrypt", "insecure-edge-termination-policy": "Redirect", destinationCAConfigMapKey: "serving-certs-service-ca"},
+	}, {
+		name:    "passthrough allowing plain HTTP",
+		config:  map[string]string{"default-tls-termination": "passthrough", "insecure-edge-termination-policy": "Allow"},
+		wantErr: true,
+	}, {
+		name:    "destination CA set inline and referenced",
+		config:  map[string]string{destinationCACertificateKey: testCA, destinationCAConfigMapKey: "serving-certs-service-ca"},
+		wantErr: true,
+	}, {
+		name:    "destination CA key without ConfigMap",
+		config:  map[string]string{destinationCAKeyKey: "ca.crt"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Validate(knativeServing(test.config)); (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]string
+		existing *corev1.ConfigMap
+		want     *corev1.ConfigMap
+		wantErr  bool
+	}{{
+		name:   "create",
+		config: map[string]string{"skip-list": "*/*"},
+		want:   configMap(map[string]string{"skip-list": "*/*"}, owned),
+	}, {
+		name:     "update",
+		config:   map[string]string{"skip-list": "*/*"},
+		existing: configMap(map[string]string{"http2-enabled": "true"}, owned),
+		want:     configMap(map[string]string{"skip-list": "*/*"}, owned),
+	}, {
+		name:     "take over a hand made config",
+		config:   map[string]string{"skip-list": "*/*"},
+		existing: configMap(map[string]string{"http2-enabled": "true"}, nil),
+		want:     configMap(map[string]string{"skip-list": "*/*"}, owned),
+	}, {
+		name:     "config removed",
+		existing: configMap(map[string]string{"skip-list": "*/*"}, owned),
+	}, {
+		name:     "hand made config left alone",
+		existing: configMap(map[string]string{"skip-list": "*/*"}, nil),
+		want:     configMap(map[string]string{"skip-list": "*/*"}, nil),
+	}, {
+		name:     "invalid config",
+		config:   map[string]string{"exposure-mode": "nodeport"},
+		existing: configMap(map[string]string{"skip-list": "*/*"}, owned),
+		want:     configMap(map[string]string{"skip-list": "*/*"}, owned),
+		wantErr:  true,
+	}, {
+		name:   "destination CA rendered",
+		config: map[string]string{"default-tls-termination": "reencrypt", destinationCAConfigMapKey: serviceCA.Name},
+		want:   configMap(map[string]string{"default-tls-termination": "reencrypt", destinationCACertificateKey: testCA}, owned),
+	}, {
+		name:     "destination CA ConfigMap missing",
+		config:   map[string]string{destinationCAConfigMapKey: "missing"},
+		existing: configMap(map[string]string{"skip-list": "*/*"}, owned),
+		want:     configMap(map[string]string{"skip-list": "*/*"}, owned),
+		wantErr:  true,
+	}, {
+		name:    "destination CA key missing",
+		config:  map[string]string{destinationCAConfigMapKey: serviceCA.Name, destinationCAKeyKey: "ca.crt"},
+		wantErr: true,
+	}, {
+		name:    "destination CA not PEM encoded",
+		config:  map[string]string{destinationCAConfigMapKey: serviceCA.Name, destinationCAKeyKey: "invalid.crt"},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objs := []runtime.Object{serviceCA.DeepCopy()}
+			if test.existing != nil {
+				objs = append(objs, test.existing)
+			}
+			api := fake.NewFakeClient(objs...)
+
+			if err := Apply(knativeServing(test.config), api); (err != nil) != test.wantErr {
+				t.Fatalf("Apply() = %v, wantErr %v", err, test.wantErr)
+			}
+
+			got := &corev1.ConfigMap{}
+			err := api.Get(context.Background(), client.ObjectKey{Namespace: operatorNamespace, Name: ingressconfig.IngressConfigName}, got)
+			if test.want == nil {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("ConfigMap wasn't deleted: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to get ConfigMap: %v", err)
+			}
+			if !cmp.Equal(got.Data, test.want.Data) || !cmp.Equal(got.Annotations, test.want.Annotations) {
+				t.Errorf("Got = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDesiredDataRouteTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]map[string]string
+		want   map[string]string
+	}{{
+		name: "nothing configured",
+	}, {
+		name: "explicit route timeout",
+		config: map[string]map[string]string{
+			ConfigKey: {defaultRouteTimeoutKey: "15m"},
+		},
+		want: map[string]string{defaultRouteTimeoutKey: "15m"},
+	}, {
+		name: "from the max revision timeout",
+		config: map[string]map[string]string{
+			defaultsConfigKey: {maxRevisionTimeoutKey: "1800"},
+		},
+		want: map[string]string{defaultRouteTimeoutKey: "1800"},
+	}, {
+		name: "prefixed defaults",
+		config: map[string]map[string]string{
+			"config-" + defaultsConfigKey: {maxRevisionTimeoutKey: "1800"},
+			defaultsConfigKey:             {maxRevisionTimeoutKey: "900"},
+		},
+		want: map[string]string{defaultRouteTimeoutKey: "1800"},
+	}, {
+		name: "explicit route timeout wins over the max revision timeout",
+		config: map[string]map[string]string{
+			ConfigKey:         {defaultRouteTimeoutKey: "15m", "http2-enabled": "true"},
+			defaultsConfigKey: {maxRevisionTimeoutKey: "1800"},
+		},
+		want: map[string]string{defaultRouteTimeoutKey: "15m", "http2-enabled": "true"},
+	}, {
+		name: "max revision timeout merged into other config",
+		config: map[string]map[string]string{
+			ConfigKey:         {"http2-enabled": "true"},
+			defaultsConfigKey: {maxRevisionTimeoutKey: "1800"},
+		},
+		want: map[string]string{defaultRouteTimeoutKey: "1800", "http2-enabled": "true"},
+	}, {
+		name: "invalid max revision timeout",
+		config: map[string]map[string]string{
+			defaultsConfigKey: {maxRevisionTimeoutKey: "forever"},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ks := knativeServing(nil)
+			ks.Spec.Config = test.config
+			if got := desiredData(ks); !cmp.Equal(got, test.want) {
+				t.Errorf("desiredData() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnqueueRequestsForDestinationCA(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := servingv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal("Failed to build scheme:", err)
+	}
+	referencing := knativeServing(map[string]string{destinationCAConfigMapKey: serviceCA.Name})
+	other := knativeServing(map[string]string{destinationCAConfigMapKey: "other-ca"})
+	other.Name = "other"
+	unconfigured := knativeServing(nil)
+	unconfigured.Name = "unconfigured"
+	api := fake.NewFakeClientWithScheme(s, referencing, other, unconfigured)
+
+	mapper := EnqueueRequestsForDestinationCA(api).(*handler.EnqueueRequestsFromMapFunc).ToRequests
+	got := mapper.Map(handler.MapObject{Meta: serviceCA, Object: serviceCA})
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: referencing.Namespace, Name: referencing.Name}}}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Enqueued requests (-want, +got) = %s", cmp.Diff(want, got))
+	}
+
+	// ConfigMaps of other namespaces aren't referenced, the CA is looked up next to the KnativeServing.
+	elsewhere := serviceCA.DeepCopy()
+	elsewhere.Namespace = "elsewhere"
+	if got := mapper.Map(handler.MapObject{Meta: elsewhere, Object: elsewhere}); len(got) != 0 {
+		t.Errorf("Enqueued requests for ConfigMap of another namespace = %v", got)
+	}
+}
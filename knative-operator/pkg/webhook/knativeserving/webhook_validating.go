@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/common"
+	"github.com/openshift-knative/serverless-operator/knative-operator/pkg/controller/knativeserving/openshiftingress"
 	servingv1alpha1 "knative.dev/operator/pkg/apis/operator/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
@@ -44,6 +45,7 @@ func (v *Validator) validate(ctx context.Context, ks *servingv1alpha1.KnativeSer
 	stages := []func(context.Context, *servingv1alpha1.KnativeServing) (bool, string, error){
 		v.validateNamespace,
 		v.validateLoneliness,
+		v.validateRouteGeneration,
 	}
 	for _, stage := range stages {
 		allowed, reason, err = stage(ctx, ks)
@@ -103,3 +105,11 @@ func (v *Validator) validateLoneliness(ctx context.Context, ks *servingv1alpha1.
 	}
 	return true, "", nil
 }
+
+// validate the route generation config, if any
+func (v *Validator) validateRouteGeneration(ctx context.Context, ks *servingv1alpha1.KnativeServing) (bool, string, error) {
+	if err := openshiftingress.Validate(ks); err != nil {
+		return false, err.Error(), nil
+	}
+	return true, "", nil
+}
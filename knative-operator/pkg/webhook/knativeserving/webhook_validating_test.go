@@ -69,3 +69,26 @@ func TestLoneliness(t *testing.T) {
 		t.Errorf("Too many KnativeServings: %v", result.AdmissionResponse)
 	}
 }
+
+func TestInvalidRouteGeneration(t *testing.T) {
+	os.Clearenv()
+
+	ks := ks1.DeepCopy()
+	ks.Spec.Config = map[string]map[string]string{
+		"openshift-ingress": {"exposure-mode": "nodeport"},
+	}
+
+	validator := Validator{}
+	validator.InjectDecoder(decoder)
+	validator.InjectClient(fake.NewFakeClient())
+
+	req, err := testutil.RequestFor(ks)
+	if err != nil {
+		t.Fatalf("Failed to generate a request for %v: %v", ks, err)
+	}
+
+	result := validator.Handle(context.Background(), req)
+	if result.Allowed {
+		t.Error("The route generation config is invalid, but the request is allowed")
+	}
+}
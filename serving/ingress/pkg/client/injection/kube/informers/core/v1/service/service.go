@@ -0,0 +1,46 @@
+// Package service injects an informer of the Services of Kourier. Unlike the informers generated
+// into knative.dev/pkg, it doesn't use the shared Kubernetes informer factory, as that would cache
+// every Service of the cluster.
+package service
+
+import (
+	context "context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	informers "k8s.io/client-go/informers"
+	v1 "k8s.io/client-go/informers/core/v1"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	controller "knative.dev/pkg/controller"
+	injection "knative.dev/pkg/injection"
+	logging "knative.dev/pkg/logging"
+)
+
+// LabelSelector selects the Services the informer watches: the gateway Services of Kourier and
+// the Services the ingress controller creates for them, which all carry Kourier's provider label.
+const LabelSelector = "networking.knative.dev/ingress-provider=kourier"
+
+func init() {
+	injection.Default.RegisterInformer(withInformer)
+}
+
+// Key is used for associating the Informer inside the context.Context.
+type Key struct{}
+
+func withInformer(ctx context.Context) (context.Context, controller.Informer) {
+	f := informers.NewSharedInformerFactoryWithOptions(kubeclient.Get(ctx), controller.GetResyncPeriod(ctx),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = LabelSelector
+		}))
+	inf := f.Core().V1().Services()
+	return context.WithValue(ctx, Key{}, inf), inf.Informer()
+}
+
+// Get extracts the typed informer from the context.
+func Get(ctx context.Context) v1.ServiceInformer {
+	untyped := ctx.Value(Key{})
+	if untyped == nil {
+		logging.FromContext(ctx).Panic(
+			"Unable to fetch k8s.io/client-go/informers/core/v1.ServiceInformer from context.")
+	}
+	return untyped.(v1.ServiceInformer)
+}
@@ -0,0 +1,47 @@
+package ingress
+
+import (
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+// RouteAdmittedAtAnnotation is the status annotation of Ingresses holding the time, in RFC 3339,
+// a router first admitted one of their Routes. It's set once and never updated.
+//
+// It's an annotation of the status, as Knative Serving resets the annotations of the Ingress to
+// the ones it desires.
+const RouteAdmittedAtAnnotation = "serving.knative.openshift.io/routeAdmittedAt"
+
+// markRouteAdmitted records when the earliest of the given Routes was admitted in the status of
+// the Ingress, unless it's recorded already. It returns true if the status changed.
+func (r *Reconciler) markRouteAdmitted(ing *v1alpha1.Ingress, routes []*routev1.Route) bool {
+	if _, ok := ing.Status.Annotations[RouteAdmittedAtAnnotation]; ok {
+		return false
+	}
+
+	var admittedAt time.Time
+	for _, desired := range routes {
+		route, err := r.routeLister.Routes(desired.Namespace).Get(desired.Name)
+		if err != nil {
+			continue
+		}
+		// Routers that don't report when they admitted a Route can't be timed.
+		since, ok := resources.RouteAdmittedSince(route)
+		if ok && !since.IsZero() && (admittedAt.IsZero() || since.Before(admittedAt)) {
+			admittedAt = since
+		}
+	}
+	if admittedAt.IsZero() {
+		return false
+	}
+
+	if ing.Status.Annotations == nil {
+		ing.Status.Annotations = make(map[string]string, 1)
+	}
+	ing.Status.Annotations[RouteAdmittedAtAnnotation] = admittedAt.UTC().Format(time.RFC3339)
+	return true
+}
@@ -0,0 +1,102 @@
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	. "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func withAdmittedAt(at time.Time) routeOption {
+	return func(r *routev1.Route) {
+		r.Status.Ingress = []routev1.RouteIngress{{
+			Host: r.Spec.Host,
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:               routev1.RouteAdmitted,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: &metav1.Time{Time: at},
+			}},
+		}}
+	}
+}
+
+func withRouteAdmittedAt(value string) ingressOption {
+	return func(i *v1alpha1.Ingress) {
+		i.Status.Annotations = map[string]string{RouteAdmittedAtAnnotation: value}
+	}
+}
+
+func TestReconcileRouteAdmittedAt(t *testing.T) {
+	key := ingNamespace + "/" + ingName
+	admittedAt := time.Date(2020, time.November, 3, 10, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	table := TableTest{{
+		Name:                    "record admission",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withAdmittedAt(admittedAt)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/annotations","value":{"serving.knative.openshift.io/routeAdmittedAt":"2020-11-03T09:04:05Z"}}]`),
+		},
+	}, {
+		// Routes readmitted later, for example after being recreated, don't change the time.
+		Name:                    "admission already recorded",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withRouteAdmittedAt("2020-11-01T00:00:00Z")),
+			route(ingressNamespace, routeName, withAdmittedAt(admittedAt)),
+		},
+	}, {
+		Name:                    "admission time unknown",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withAdmitted("")),
+		},
+	}, {
+		Name:                    "route pending",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName),
+		},
+	}}
+
+	table.Test(t, newFactory(nil))
+}
+
+func TestMarkRouteAdmittedFormat(t *testing.T) {
+	admittedAt := time.Date(2020, time.November, 3, 10, 4, 5, 0, time.UTC)
+	listers := NewListers([]runtime.Object{route(ingressNamespace, routeName, withAdmittedAt(admittedAt))})
+	r := &Reconciler{routeLister: listers.GetRouteLister()}
+	ingress := ing(ingNamespace, ingName)
+
+	if !r.markRouteAdmitted(ingress, []*routev1.Route{route(ingressNamespace, routeName)}) {
+		t.Fatal("markRouteAdmitted() = false, want true")
+	}
+	got, err := time.Parse(time.RFC3339, ingress.Status.Annotations[RouteAdmittedAtAnnotation])
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", RouteAdmittedAtAnnotation, err)
+	}
+	if !got.Equal(admittedAt) {
+		t.Errorf("%s = %v, want %v", RouteAdmittedAtAnnotation, got, admittedAt)
+	}
+
+	if r.markRouteAdmitted(ingress, []*routev1.Route{route(ingressNamespace, routeName)}) {
+		t.Error("markRouteAdmitted() = true on the second call, want false")
+	}
+}
@@ -0,0 +1,427 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	cm "knative.dev/pkg/configmap"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+const (
+	// IngressConfigName is the name of the ConfigMap configuring the OpenShift ingress controller.
+	IngressConfigName = "config-openshift-ingress"
+
+	// skipListKey contains a comma or whitespace separated list of "namespace/name" patterns of
+	// Ingresses that no Routes are generated for. Patterns support the syntax of path.Match.
+	skipListKey = "skip-list"
+
+	// internalGatewayPrefixKey is the name prefix of the gateway Services of internal Kourier
+	// instances. Routes prefer public gateways over internal ones.
+	internalGatewayPrefixKey = "internal-gateway-prefix"
+
+	// localGatewayNamesKey contains a comma or whitespace separated list of names of the Services
+	// of cluster-local gateways of other ingress implementations, like net-istio. Routes prefer
+	// public gateways over them too.
+	localGatewayNamesKey = "local-gateway-names"
+
+	// appsDomainKey is the apps domain of the cluster. Hosts outside of it are custom domains.
+	appsDomainKey = "apps-domain"
+
+	// externalDNSEnabledKey enables annotating Routes for hosts outside of the apps domain for
+	// external-dns. externalDNSTTLKey and externalDNSTargetKey configure the published records.
+	externalDNSEnabledKey = "external-dns-enabled"
+	externalDNSTTLKey     = "external-dns-ttl"
+	externalDNSTargetKey  = "external-dns-target"
+
+	// failedRouteGracePeriodKey is the time after which Routes rejected by the router are
+	// recreated. Zero disables recreating them.
+	failedRouteGracePeriodKey = "failed-route-grace-period"
+
+	defaultFailedRouteGracePeriod = 5 * time.Minute
+
+	// gatewayMigrationFromKey and gatewayMigrationToKey are the "namespace/name" of the gateway
+	// Services traffic is migrated between. gatewayMigrationWeightKey is the percentage of
+	// traffic sent to the new gateway already.
+	gatewayMigrationFromKey   = "gateway-migration-from"
+	gatewayMigrationToKey     = "gateway-migration-to"
+	gatewayMigrationWeightKey = "gateway-migration-weight"
+
+	// http2EnabledKey makes Routes request HTTP/2 by default.
+	http2EnabledKey = "http2-enabled"
+
+	// tlsTerminationKey contains a comma or whitespace separated list of "suffix=termination"
+	// entries setting the TLS termination of Routes by the suffix of their host.
+	tlsTerminationKey = "tls-termination"
+
+	// defaultTLSTerminationKey is the TLS termination of Routes whose host doesn't match
+	// tlsTerminationKey. insecurePolicyKey is how their Routes handle plain HTTP and
+	// destinationCACertificateKey is the PEM encoded CA the router verifies the gateway with on
+	// reencrypt.
+	defaultTLSTerminationKey    = "default-tls-termination"
+	insecurePolicyKey           = "insecure-edge-termination-policy"
+	destinationCACertificateKey = "destination-ca-certificate"
+
+	// defaultRouteTimeoutKey is the timeout of Routes whose Ingress doesn't set one. It defaults
+	// to the maximum revision timeout of Knative Serving.
+	defaultRouteTimeoutKey = "default-route-timeout"
+
+	// exposureModeKey configures how Ingresses are exposed outside of the cluster.
+	exposureModeKey = "exposure-mode"
+
+	// keepAnnotationPrefixesKey contains a comma or whitespace separated list of prefixes of
+	// annotations that are kept on existing Routes, as others than the controller own them.
+	keepAnnotationPrefixesKey = "keep-annotation-prefixes"
+
+	// tenantLabelKey is the key of the label Routes are labeled with the tenant of the namespace
+	// of their Ingress with. tenantMappingKey contains a comma or whitespace separated list of
+	// "namespace=tenant" entries, namespaces missing from it are their own tenant.
+	tenantLabelKey   = "tenant-label-key"
+	tenantMappingKey = "tenant-mapping"
+
+	// minimalRouteAnnotationsKey makes Routes only carry the annotations that have an effect on
+	// them, rather than all annotations of their Ingress.
+	minimalRouteAnnotationsKey = "minimal-route-annotations"
+
+	// maintenanceServiceKey is the name of the Service in the namespace of the Routes answering
+	// the requests of Routes in maintenance.
+	maintenanceServiceKey = "maintenance-service"
+
+	// externalDomainKey is the domain of Knative Serving's config-domain. Setting it generates
+	// Routes for the external hosts of rules that only have cluster-local hosts, using the
+	// template of externalDomainTemplateKey, which mirrors the domain-template of config-network.
+	externalDomainKey         = "external-domain"
+	externalDomainTemplateKey = "external-domain-template"
+
+	// directServiceModeKey makes Routes target the Services of the splits of Ingresses directly,
+	// rather than going through the gateway.
+	directServiceModeKey = "direct-service-mode"
+)
+
+// ExposureMode defines how Ingresses are exposed outside of the cluster.
+type ExposureMode string
+
+const (
+	// ExposureRoute exposes Ingresses through OpenShift Routes.
+	ExposureRoute ExposureMode = "route"
+
+	// ExposureLoadBalancer exposes Ingresses through a LoadBalancer Service in front of the
+	// Kourier gateway. No Routes are generated in this mode.
+	ExposureLoadBalancer ExposureMode = "loadbalancer"
+)
+
+// Ingress contains the configuration of the OpenShift ingress controller.
+type Ingress struct {
+	// SkipList contains "namespace/name" patterns of Ingresses no Routes are generated for.
+	SkipList []string
+
+	// KourierSelector tells internal and public Kourier gateways apart.
+	KourierSelector resources.KourierSelector
+
+	// AppsDomain is the apps domain of the cluster.
+	AppsDomain string
+
+	// FailedRouteGracePeriod is the time after which Routes rejected by the router are recreated.
+	FailedRouteGracePeriod time.Duration
+
+	// ExternalDNS configures external-dns annotations on Routes. Nil if disabled.
+	ExternalDNS *resources.ExternalDNS
+
+	// ExposureMode defines how Ingresses are exposed outside of the cluster.
+	ExposureMode ExposureMode
+
+	// HTTP2 makes Routes request HTTP/2 unless their Ingress opts out.
+	HTTP2 bool
+
+	// GatewayMigration shifts traffic of Routes between gateways. Nil if no migration is ongoing.
+	GatewayMigration *resources.GatewayMigration
+
+	// TerminationPolicy sets the TLS termination of Routes by the suffix of their host.
+	TerminationPolicy resources.TerminationPolicy
+
+	// TLSDefaults are the TLS settings of Routes that neither their Ingress nor the
+	// TerminationPolicy set.
+	TLSDefaults resources.TLSDefaults
+
+	// DefaultRouteTimeout is the timeout of Routes whose Ingress doesn't set one. Zero if unset.
+	DefaultRouteTimeout time.Duration
+
+	// KeepAnnotationPrefixes are the prefixes of annotations of existing Routes that generating
+	// Routes never overwrites. Nil if unset, which keeps resources.DefaultKeepAnnotationPrefixes.
+	KeepAnnotationPrefixes []string
+
+	// TenantLabel labels Routes with the tenant of their namespace. Nil if disabled.
+	TenantLabel *resources.TenantLabel
+
+	// MinimalRouteAnnotations drops the annotations of Ingresses that have no effect on Routes
+	// from their Routes.
+	MinimalRouteAnnotations bool
+
+	// MaintenanceService is the name of the Service answering the requests of Routes in
+	// maintenance. Empty if the router answers them with 503.
+	MaintenanceService string
+
+	// DomainTemplate generates the external hosts of Knative Routes. Nil if disabled.
+	DomainTemplate *resources.DomainTemplate
+
+	// DirectServiceMode makes Routes target the Services of the splits of Ingresses directly
+	// where possible, bypassing the gateway.
+	DirectServiceMode bool
+}
+
+// KeptAnnotationPrefixes returns the prefixes of annotations of existing Routes that generating
+// Routes never overwrites.
+func (ing *Ingress) KeptAnnotationPrefixes() []string {
+	if ing.KeepAnnotationPrefixes == nil {
+		return resources.DefaultKeepAnnotationPrefixes
+	}
+	return ing.KeepAnnotationPrefixes
+}
+
+// NewIngressFromConfigMap creates an Ingress config from the supplied ConfigMap.
+func NewIngressFromConfigMap(configMap *corev1.ConfigMap) (*Ingress, error) {
+	ing := &Ingress{
+		KourierSelector:        resources.DefaultKourierSelector,
+		FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+		ExposureMode:           ExposureRoute,
+	}
+	if prefix, ok := configMap.Data[internalGatewayPrefixKey]; ok {
+		ing.KourierSelector.InternalServicePrefix = strings.TrimSpace(prefix)
+	}
+	if value, ok := configMap.Data[localGatewayNamesKey]; ok {
+		// An empty list doesn't treat any gateway as local.
+		ing.KourierSelector.LocalGatewayNames = append([]string{}, strings.FieldsFunc(value, isListSeparator)...)
+	}
+
+	var (
+		exposureMode       = string(ing.ExposureMode)
+		externalDNSEnabled bool
+		externalDNSTTL     string
+		externalDNSTarget  string
+		migrationFrom      *types.NamespacedName
+		migrationTo        *types.NamespacedName
+		migrationWeight    int
+	)
+	if err := cm.Parse(configMap.Data,
+		cm.AsString(appsDomainKey, &ing.AppsDomain),
+		cm.AsString(destinationCACertificateKey, &ing.TLSDefaults.DestinationCACertificate),
+		cm.AsBool(directServiceModeKey, &ing.DirectServiceMode),
+		cm.AsBool(externalDNSEnabledKey, &externalDNSEnabled),
+		cm.AsBool(http2EnabledKey, &ing.HTTP2),
+		cm.AsBool(minimalRouteAnnotationsKey, &ing.MinimalRouteAnnotations),
+		cm.AsString(externalDNSTTLKey, &externalDNSTTL),
+		cm.AsString(externalDNSTargetKey, &externalDNSTarget),
+		cm.AsString(exposureModeKey, &exposureMode),
+		cm.AsString(maintenanceServiceKey, &ing.MaintenanceService),
+		cm.AsOptionalNamespacedName(gatewayMigrationFromKey, &migrationFrom),
+		cm.AsOptionalNamespacedName(gatewayMigrationToKey, &migrationTo),
+		cm.AsInt(gatewayMigrationWeightKey, &migrationWeight),
+	); err != nil {
+		return nil, fmt.Errorf("failed to parse data: %w", err)
+	}
+	switch mode := ExposureMode(exposureMode); mode {
+	case ExposureRoute, ExposureLoadBalancer:
+		ing.ExposureMode = mode
+	default:
+		return nil, fmt.Errorf("%s must be one of %q or %q, got %q", exposureModeKey, ExposureRoute, ExposureLoadBalancer, mode)
+	}
+	if ing.MaintenanceService != "" {
+		if errs := validation.IsDNS1035Label(ing.MaintenanceService); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s %q: %s", maintenanceServiceKey, ing.MaintenanceService, strings.Join(errs, ", "))
+		}
+	}
+	if value, ok := configMap.Data[failedRouteGracePeriodKey]; ok {
+		gracePeriod, err := resources.ParseTimeout(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", failedRouteGracePeriodKey, err)
+		}
+		ing.FailedRouteGracePeriod = gracePeriod
+	}
+
+	if value, ok := configMap.Data[defaultRouteTimeoutKey]; ok {
+		timeout, err := resources.ParseTimeout(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", defaultRouteTimeoutKey, err)
+		}
+		ing.DefaultRouteTimeout = timeout
+	}
+
+	if externalDNSEnabled {
+		ing.ExternalDNS = &resources.ExternalDNS{
+			Target: externalDNSTarget,
+		}
+		if externalDNSTTL != "" {
+			ttl, err := resources.ParseTimeout(externalDNSTTL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", externalDNSTTLKey, err)
+			}
+			ing.ExternalDNS.TTL = ttl
+		}
+	}
+
+	if migrationFrom != nil || migrationTo != nil {
+		ing.GatewayMigration = &resources.GatewayMigration{Weight: migrationWeight}
+		if migrationFrom != nil {
+			ing.GatewayMigration.From = *migrationFrom
+		}
+		if migrationTo != nil {
+			ing.GatewayMigration.To = *migrationTo
+		}
+		if err := ing.GatewayMigration.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid gateway migration: %w", err)
+		}
+	}
+
+	patterns := strings.FieldsFunc(configMap.Data[skipListKey], isListSeparator)
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			return nil, fmt.Errorf("invalid %s pattern %q: must be of the form namespace/name", skipListKey, pattern)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", skipListKey, pattern, err)
+		}
+	}
+	if len(patterns) > 0 {
+		ing.SkipList = patterns
+	}
+
+	if value, ok := configMap.Data[keepAnnotationPrefixesKey]; ok {
+		// An empty value keeps no annotations, unlike the default.
+		ing.KeepAnnotationPrefixes = append([]string{}, strings.FieldsFunc(value, isListSeparator)...)
+	}
+
+	if key := strings.TrimSpace(configMap.Data[tenantLabelKey]); key != "" {
+		ing.TenantLabel = &resources.TenantLabel{Key: key}
+	}
+	for _, entry := range strings.FieldsFunc(configMap.Data[tenantMappingKey], isListSeparator) {
+		i := strings.Index(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid %s entry %q: must be of the form namespace=tenant", tenantMappingKey, entry)
+		}
+		if ing.TenantLabel == nil {
+			return nil, fmt.Errorf("%s requires %s to be set", tenantMappingKey, tenantLabelKey)
+		}
+		if ing.TenantLabel.Tenants == nil {
+			ing.TenantLabel.Tenants = make(map[string]string)
+		}
+		ing.TenantLabel.Tenants[entry[:i]] = entry[i+1:]
+	}
+	if ing.TenantLabel != nil {
+		if err := ing.TenantLabel.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid tenant label: %w", err)
+		}
+	}
+
+	if domain := strings.TrimSpace(configMap.Data[externalDomainKey]); domain != "" {
+		ing.DomainTemplate = &resources.DomainTemplate{
+			Template: strings.TrimSpace(configMap.Data[externalDomainTemplateKey]),
+			Domain:   domain,
+		}
+		if err := ing.DomainTemplate.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", externalDomainTemplateKey, err)
+		}
+	} else if _, ok := configMap.Data[externalDomainTemplateKey]; ok {
+		return nil, fmt.Errorf("%s requires %s to be set", externalDomainTemplateKey, externalDomainKey)
+	}
+
+	for _, entry := range strings.FieldsFunc(configMap.Data[tlsTerminationKey], isListSeparator) {
+		suffix, value := entry, ""
+		if i := strings.Index(entry, "="); i >= 0 {
+			suffix, value = entry[:i], entry[i+1:]
+		}
+		if suffix == "" {
+			return nil, fmt.Errorf("invalid %s entry %q: must be of the form suffix=termination", tlsTerminationKey, entry)
+		}
+		termination, err := resources.ParseTermination(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", tlsTerminationKey, entry, err)
+		}
+		if ing.TerminationPolicy == nil {
+			ing.TerminationPolicy = make(resources.TerminationPolicy)
+		}
+		ing.TerminationPolicy[resources.NormalizeHost(suffix)] = termination
+	}
+
+	if value, ok := configMap.Data[defaultTLSTerminationKey]; ok {
+		termination, err := resources.ParseTermination(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", defaultTLSTerminationKey, err)
+		}
+		ing.TLSDefaults.Termination = termination
+	}
+	if value, ok := configMap.Data[insecurePolicyKey]; ok {
+		policy, err := resources.ParseInsecurePolicy(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", insecurePolicyKey, err)
+		}
+		ing.TLSDefaults.InsecurePolicy = policy
+	}
+	if err := ing.TLSDefaults.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid TLS defaults: %w", err)
+	}
+
+	return ing, nil
+}
+
+// isListSeparator returns true for the runes separating the entries of list values.
+func isListSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\n' || r == '\t'
+}
+
+// Skipped returns true if the Ingress with the given namespace and name matches the skip-list.
+func (i *Ingress) Skipped(namespace, name string) bool {
+	key := namespace + "/" + name
+	for _, pattern := range i.SkipList {
+		// Patterns are validated when parsing the config.
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DeepCopy returns a deep copy of the Ingress config.
+func (i *Ingress) DeepCopy() *Ingress {
+	out := *i
+	if i.SkipList != nil {
+		out.SkipList = append([]string(nil), i.SkipList...)
+	}
+	if i.KourierSelector.LocalGatewayNames != nil {
+		out.KourierSelector.LocalGatewayNames = append([]string{}, i.KourierSelector.LocalGatewayNames...)
+	}
+	if i.ExternalDNS != nil {
+		externalDNS := *i.ExternalDNS
+		out.ExternalDNS = &externalDNS
+	}
+	if i.GatewayMigration != nil {
+		migration := *i.GatewayMigration
+		out.GatewayMigration = &migration
+	}
+	if i.TenantLabel != nil {
+		tenantLabel := *i.TenantLabel
+		if i.TenantLabel.Tenants != nil {
+			tenantLabel.Tenants = make(map[string]string, len(i.TenantLabel.Tenants))
+			for namespace, tenant := range i.TenantLabel.Tenants {
+				tenantLabel.Tenants[namespace] = tenant
+			}
+		}
+		out.TenantLabel = &tenantLabel
+	}
+	if i.DomainTemplate != nil {
+		domainTemplate := *i.DomainTemplate
+		out.DomainTemplate = &domainTemplate
+	}
+	if i.TerminationPolicy != nil {
+		out.TerminationPolicy = make(resources.TerminationPolicy, len(i.TerminationPolicy))
+		for suffix, termination := range i.TerminationPolicy {
+			out.TerminationPolicy[suffix] = termination
+		}
+	}
+	return &out
+}
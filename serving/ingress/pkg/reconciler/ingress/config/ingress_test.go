@@ -0,0 +1,473 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+const testCA = `-----BEGIN CERTIFICATE-----
+MIIBszCCAVmgAwIBAgIUTEST
+-----END CERTIFICATE-----
+`
+
+func TestNewIngressFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    *Ingress
+		wantErr bool
+	}{{
+		name: "defaults",
+		want: &Ingress{KourierSelector: resources.DefaultKourierSelector, FailedRouteGracePeriod: defaultFailedRouteGracePeriod, ExposureMode: ExposureRoute},
+	}, {
+		name: "skip-list",
+		data: map[string]string{
+			skipListKey: "ns1/foo, ns2/*\n  *-migrated/bar-*",
+		},
+		want: &Ingress{
+			SkipList:               []string{"ns1/foo", "ns2/*", "*-migrated/bar-*"},
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+		},
+	}, {
+		name: "internal gateway prefix",
+		data: map[string]string{
+			internalGatewayPrefixKey: "private-kourier",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.KourierSelector{InternalServicePrefix: "private-kourier"},
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+		},
+	}, {
+		name: "no internal gateways",
+		data: map[string]string{
+			internalGatewayPrefixKey: "",
+		},
+		want: &Ingress{FailedRouteGracePeriod: defaultFailedRouteGracePeriod, ExposureMode: ExposureRoute},
+	}, {
+		name: "local gateway names",
+		data: map[string]string{
+			localGatewayNamesKey: "istio-local, private-gateway",
+		},
+		want: &Ingress{
+			KourierSelector: resources.KourierSelector{
+				InternalServicePrefix: resources.DefaultKourierSelector.InternalServicePrefix,
+				LocalGatewayNames:     []string{"istio-local", "private-gateway"},
+			},
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+		},
+	}, {
+		name: "no local gateways",
+		data: map[string]string{
+			localGatewayNamesKey: "",
+		},
+		want: &Ingress{
+			KourierSelector: resources.KourierSelector{
+				InternalServicePrefix: resources.DefaultKourierSelector.InternalServicePrefix,
+				LocalGatewayNames:     []string{},
+			},
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+		},
+	}, {
+		name: "external-dns",
+		data: map[string]string{
+			appsDomainKey:         "apps.example.com",
+			externalDNSEnabledKey: "true",
+			externalDNSTTLKey:     "5m",
+			externalDNSTargetKey:  "lb.example.com",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			AppsDomain:             "apps.example.com",
+			ExternalDNS: &resources.ExternalDNS{
+				TTL:    5 * time.Minute,
+				Target: "lb.example.com",
+			},
+		},
+	}, {
+		name: "http2",
+		data: map[string]string{
+			http2EnabledKey: "true",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			HTTP2:                  true,
+		},
+	}, {
+		name: "tls termination",
+		data: map[string]string{
+			tlsTerminationKey: "secure.example.com=passthrough,\n internal.example.com=Reencrypt",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			TerminationPolicy: resources.TerminationPolicy{
+				"secure.example.com":   routev1.TLSTerminationPassthrough,
+				"internal.example.com": routev1.TLSTerminationReencrypt,
+			},
+		},
+	}, {
+		name: "invalid tls termination",
+		data: map[string]string{
+			tlsTerminationKey: "secure.example.com=offload",
+		},
+		wantErr: true,
+	}, {
+		name: "tls termination without suffix",
+		data: map[string]string{
+			tlsTerminationKey: "passthrough",
+		},
+		wantErr: true,
+	}, {
+		name: "tls defaults",
+		data: map[string]string{
+			defaultTLSTerminationKey:    "reencrypt",
+			insecurePolicyKey:           "redirect",
+			destinationCACertificateKey: testCA,
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			TLSDefaults: resources.TLSDefaults{
+				Termination:              routev1.TLSTerminationReencrypt,
+				InsecurePolicy:           routev1.InsecureEdgeTerminationPolicyRedirect,
+				DestinationCACertificate: testCA,
+			},
+		},
+	}, {
+		name: "passthrough allowing plain HTTP",
+		data: map[string]string{
+			defaultTLSTerminationKey: "passthrough",
+			insecurePolicyKey:        "Allow",
+		},
+		wantErr: true,
+	}, {
+		name: "unset insecure policy",
+		data: map[string]string{
+			insecurePolicyKey: "Unset",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			TLSDefaults:            resources.TLSDefaults{InsecurePolicy: resources.InsecurePolicyUnset},
+		},
+	}, {
+		name: "passthrough leaving insecure policy unset",
+		data: map[string]string{
+			defaultTLSTerminationKey: "passthrough",
+			insecurePolicyKey:        "Unset",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid insecure policy",
+		data: map[string]string{
+			insecurePolicyKey: "Upgrade",
+		},
+		wantErr: true,
+	}, {
+		name: "destination CA not PEM encoded",
+		data: map[string]string{
+			destinationCACertificateKey: "not a certificate",
+		},
+		wantErr: true,
+	}, {
+		name: "default route timeout",
+		data: map[string]string{
+			defaultRouteTimeoutKey: "15m",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			DefaultRouteTimeout:    15 * time.Minute,
+		},
+	}, {
+		name: "invalid default route timeout",
+		data: map[string]string{
+			defaultRouteTimeoutKey: "forever",
+		},
+		wantErr: true,
+	}, {
+		name: "keep annotation prefixes",
+		data: map[string]string{
+			keepAnnotationPrefixesKey: "openshift.io/, example.com/",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			KeepAnnotationPrefixes: []string{"openshift.io/", "example.com/"},
+		},
+	}, {
+		name: "keep no annotations",
+		data: map[string]string{
+			keepAnnotationPrefixesKey: "",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			KeepAnnotationPrefixes: []string{},
+		},
+	}, {
+		name: "tenant label",
+		data: map[string]string{
+			tenantLabelKey:   "tenant",
+			tenantMappingKey: "shop-dev=shop, shop-prod=shop",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			TenantLabel: &resources.TenantLabel{
+				Key:     "tenant",
+				Tenants: map[string]string{"shop-dev": "shop", "shop-prod": "shop"},
+			},
+		},
+	}, {
+		name: "invalid tenant label key",
+		data: map[string]string{
+			tenantLabelKey: "tenant!",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid tenant",
+		data: map[string]string{
+			tenantLabelKey:   "tenant",
+			tenantMappingKey: "shop-dev=shop/dev",
+		},
+		wantErr: true,
+	}, {
+		name: "tenant mapping without label key",
+		data: map[string]string{
+			tenantMappingKey: "shop-dev=shop",
+		},
+		wantErr: true,
+	}, {
+		name: "minimal route annotations",
+		data: map[string]string{
+			minimalRouteAnnotationsKey: "true",
+		},
+		want: &Ingress{
+			KourierSelector:         resources.DefaultKourierSelector,
+			FailedRouteGracePeriod:  defaultFailedRouteGracePeriod,
+			ExposureMode:            ExposureRoute,
+			MinimalRouteAnnotations: true,
+		},
+	}, {
+		name: "maintenance service",
+		data: map[string]string{
+			maintenanceServiceKey: "maintenance-page",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			MaintenanceService:     "maintenance-page",
+		},
+	}, {
+		name: "invalid maintenance service",
+		data: map[string]string{
+			maintenanceServiceKey: "knative-serving-ingress/maintenance-page",
+		},
+		wantErr: true,
+	}, {
+		name: "external domain",
+		data: map[string]string{
+			externalDomainKey: "apps.example.com",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			DomainTemplate:         &resources.DomainTemplate{Domain: "apps.example.com"},
+		},
+	}, {
+		name: "external domain template",
+		data: map[string]string{
+			externalDomainKey:         "apps.example.com",
+			externalDomainTemplateKey: "{{.Name}}-{{.Namespace}}.{{.Domain}}",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			DomainTemplate: &resources.DomainTemplate{
+				Template: "{{.Name}}-{{.Namespace}}.{{.Domain}}",
+				Domain:   "apps.example.com",
+			},
+		},
+	}, {
+		name: "malformed external domain template",
+		data: map[string]string{
+			externalDomainKey:         "apps.example.com",
+			externalDomainTemplateKey: "{{.Name",
+		},
+		wantErr: true,
+	}, {
+		name: "external domain template without domain",
+		data: map[string]string{
+			externalDomainTemplateKey: "{{.Name}}.{{.Domain}}",
+		},
+		wantErr: true,
+	}, {
+		name: "direct service mode",
+		data: map[string]string{
+			directServiceModeKey: "true",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			DirectServiceMode:      true,
+		},
+	}, {
+		name: "gateway migration",
+		data: map[string]string{
+			gatewayMigrationFromKey:   "knative-serving-ingress/kourier",
+			gatewayMigrationToKey:     "knative-serving-ingress/istio-ingressgateway",
+			gatewayMigrationWeightKey: "25",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureRoute,
+			GatewayMigration: &resources.GatewayMigration{
+				From:   types.NamespacedName{Namespace: "knative-serving-ingress", Name: "kourier"},
+				To:     types.NamespacedName{Namespace: "knative-serving-ingress", Name: "istio-ingressgateway"},
+				Weight: 25,
+			},
+		},
+	}, {
+		name: "incomplete gateway migration",
+		data: map[string]string{
+			gatewayMigrationFromKey: "knative-serving-ingress/kourier",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid gateway migration weight",
+		data: map[string]string{
+			gatewayMigrationFromKey:   "knative-serving-ingress/kourier",
+			gatewayMigrationToKey:     "knative-serving-ingress/istio-ingressgateway",
+			gatewayMigrationWeightKey: "150",
+		},
+		wantErr: true,
+	}, {
+		name: "external-dns disabled",
+		data: map[string]string{
+			externalDNSEnabledKey: "false",
+			externalDNSTTLKey:     "5m",
+		},
+		want: &Ingress{KourierSelector: resources.DefaultKourierSelector, FailedRouteGracePeriod: defaultFailedRouteGracePeriod, ExposureMode: ExposureRoute},
+	}, {
+		name: "failed route grace period",
+		data: map[string]string{
+			failedRouteGracePeriodKey: "90",
+		},
+		want: &Ingress{KourierSelector: resources.DefaultKourierSelector, FailedRouteGracePeriod: 90 * time.Second, ExposureMode: ExposureRoute},
+	}, {
+		name: "loadbalancer exposure",
+		data: map[string]string{
+			exposureModeKey: "loadbalancer",
+		},
+		want: &Ingress{
+			KourierSelector:        resources.DefaultKourierSelector,
+			FailedRouteGracePeriod: defaultFailedRouteGracePeriod,
+			ExposureMode:           ExposureLoadBalancer,
+		},
+	}, {
+		name: "invalid exposure mode",
+		data: map[string]string{
+			exposureModeKey: "nodeport",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid failed route grace period",
+		data: map[string]string{
+			failedRouteGracePeriodKey: "-1m",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid external-dns ttl",
+		data: map[string]string{
+			externalDNSEnabledKey: "true",
+			externalDNSTTLKey:     "soon",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid external-dns flag",
+		data: map[string]string{
+			externalDNSEnabledKey: "yes please",
+		},
+		wantErr: true,
+	}, {
+		name: "missing namespace",
+		data: map[string]string{
+			skipListKey: "foo",
+		},
+		wantErr: true,
+	}, {
+		name: "malformed pattern",
+		data: map[string]string{
+			skipListKey: "ns/[foo",
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewIngressFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: IngressConfigName},
+				Data:       test.data,
+			})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewIngressFromConfigMap() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("NewIngressFromConfigMap() (-want, +got) = %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
+
+func TestSkipped(t *testing.T) {
+	ing := &Ingress{SkipList: []string{"ns1/foo", "ns2/*", "*-migrated/bar-*"}}
+
+	tests := []struct {
+		namespace string
+		name      string
+		want      bool
+	}{
+		{"ns1", "foo", true},
+		{"ns1", "bar", false},
+		{"ns2", "anything", true},
+		{"team-migrated", "bar-1", true},
+		{"team-migrated", "foo-1", false},
+		{"ns3", "foo", false},
+	}
+
+	for _, test := range tests {
+		if got := ing.Skipped(test.namespace, test.name); got != test.want {
+			t.Errorf("Skipped(%q, %q) = %v, want: %v", test.namespace, test.name, got, test.want)
+		}
+	}
+}
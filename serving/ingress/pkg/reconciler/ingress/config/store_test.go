@@ -0,0 +1,29 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestStore(t *testing.T) {
+	store := NewStore(logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: IngressConfigName},
+		Data:       map[string]string{skipListKey: "ns/foo"},
+	})
+
+	cfg := FromContext(store.ToContext(context.Background()))
+	if !cfg.Ingress.Skipped("ns", "foo") {
+		t.Error("Skipped() = false, want: true")
+	}
+
+	// Loaded configs must not share state with the store.
+	cfg.Ingress.SkipList[0] = "ns/bar"
+	if !store.Load().Ingress.Skipped("ns", "foo") {
+		t.Error("Load() returned a config sharing state with the store")
+	}
+}
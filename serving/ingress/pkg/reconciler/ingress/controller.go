@@ -2,22 +2,64 @@ package ingress
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"strconv"
 
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/networking/pkg/apis/networking"
+	networkingclient "knative.dev/networking/pkg/client/injection/client"
 	ingressinformer "knative.dev/networking/pkg/client/injection/informers/networking/v1alpha1/ingress"
 	ingressreconciler "knative.dev/networking/pkg/client/injection/reconciler/networking/v1alpha1/ingress"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection/clients/dynamicclient"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
 	"knative.dev/serving/pkg/apis/serving"
 
 	routeclient "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/injection/client"
 	routeinformer "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/injection/informers/route/v1/route"
+	serviceinformer "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/injection/kube/informers/core/v1/service"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
 )
 
-const kourierIngressClassName = "kourier.ingress.networking.knative.dev"
+const (
+	kourierIngressClassName = "kourier.ingress.networking.knative.dev"
+
+	// controllerAgentName is the source of the events of the controller, which is the default of
+	// the generated reconciler.
+	controllerAgentName = "ingress-controller"
+
+	// gatewayNamespaceEnvKey and gatewayNameEnvKey configure the gateway Service Routes target
+	// until the LoadBalancer status of their Ingress is populated.
+	gatewayNamespaceEnvKey = "KOURIER_GATEWAY_NAMESPACE"
+	gatewayNameEnvKey      = "KOURIER_GATEWAY_NAME"
+
+	// gatewayHTTPPortEnvKey configures the name of the port of gateway Services Routes preferably
+	// target. Other HTTP ports of the Services are detected if they have no port of the name.
+	gatewayHTTPPortEnvKey = "KOURIER_GATEWAY_HTTP_PORT"
+
+	defaultGatewayNamespace = "knative-serving-ingress"
+	defaultGatewayName      = "kourier"
+
+	// strictTLSEnvKey enables failing Route generation for hosts without a certificate.
+	strictTLSEnvKey = "STRICT_TLS"
+
+	// longLabelPolicyEnvKey configures how hosts with labels longer than 63 characters are handled.
+	longLabelPolicyEnvKey = "LONG_LABEL_POLICY"
+
+	// externalNameIndirectionEnvKey enables reaching gateways outside of the gateway namespace
+	// through ExternalName Services instead of failing Route generation.
+	externalNameIndirectionEnvKey = "EXTERNAL_NAME_INDIRECTION"
+)
 
 // NewController returns a new Ingress controller for Ingress on Openshift.
 func NewController(
@@ -26,25 +68,109 @@ func NewController(
 ) *controller.Impl {
 	logger := logging.FromContext(ctx)
 
+	if err := NewRBACValidator(kubeclient.Get(ctx).AuthorizationV1()).Validate(ctx); err != nil {
+		logger.Fatalw("Missing permissions to manage routes", zap.Error(err))
+	}
+
+	tracing, err := tracingConfigFromEnv()
+	if err != nil {
+		logger.Fatalw("Failed to read tracing configuration", zap.Error(err))
+	}
+	if err := setupTracing(ctx, tracing); err != nil {
+		logger.Fatalw("Failed to set up tracing", zap.Error(err))
+	}
+
 	ingressInformer := ingressinformer.Get(ctx)
 	routeInformer := routeinformer.Get(ctx)
+	serviceInformer := serviceinformer.Get(ctx)
+
+	strictTLS, err := boolFromEnv(strictTLSEnvKey)
+	if err != nil {
+		logger.Fatalw("Failed to read strict TLS mode", zap.Error(err))
+	}
+
+	longLabelPolicy, err := longLabelPolicyFromEnv()
+	if err != nil {
+		logger.Fatalw("Failed to read long label policy", zap.Error(err))
+	}
+
+	externalNameIndirection, err := boolFromEnv(externalNameIndirectionEnvKey)
+	if err != nil {
+		logger.Fatalw("Failed to read ExternalName indirection flag", zap.Error(err))
+	}
+
+	networkObservability, err := boolFromEnv(networkObservabilityEnvKey)
+	if err != nil {
+		logger.Fatalw("Failed to read network observability flag", zap.Error(err))
+	}
+
+	output, err := routeOutputFromEnv()
+	if err != nil {
+		logger.Fatalw("Failed to read route output", zap.Error(err))
+	}
 
 	c := &Reconciler{
-		routeLister: routeInformer.Lister(),
-		routeClient: routeclient.Get(ctx).RouteV1(),
+		routeLister:     routeInformer.Lister(),
+		routeClient:     routeclient.Get(ctx).RouteV1(),
+		ingressClient:   networkingclient.Get(ctx).NetworkingV1alpha1(),
+		serviceLister:   serviceInformer.Lister(),
+		serviceClient:   kubeclient.Get(ctx).CoreV1(),
+		secretClient:    kubeclient.Get(ctx).CoreV1(),
+		configMapClient: kubeclient.Get(ctx).CoreV1(),
+		fallbackGateway: &types.NamespacedName{
+			Namespace: envOrDefault(gatewayNamespaceEnvKey, defaultGatewayNamespace),
+			Name:      envOrDefault(gatewayNameEnvKey, defaultGatewayName),
+		},
+		gatewayHTTPPort:         envOrDefault(gatewayHTTPPortEnvKey, resources.KourierHTTPPort),
+		strictTLS:               strictTLS,
+		longLabelPolicy:         longLabelPolicy,
+		externalNameIndirection: externalNameIndirection,
+		routeOutput:             output,
+		clock:                   clock.RealClock{},
+	}
+	if networkObservability {
+		c.flowCollector = &cachedFlowCollectorClient{
+			client: &dynamicFlowCollectorClient{client: dynamicclient.Get(ctx)},
+			clock:  c.clock,
+		}
 	}
 
+	var kserviceInformer cache.SharedIndexInformer
+	if keys := serviceLabelsFromEnv(); len(keys) > 0 {
+		kserviceInformer = newKServiceInformer(ctx, dynamicclient.Get(ctx), controller.GetResyncPeriod(ctx))
+		c.serviceLabels = &serviceLabelCopier{keys: keys, indexer: kserviceInformer.GetIndexer()}
+		go kserviceInformer.Run(ctx.Done())
+		// Routes generated before the cache is filled would lose the copied labels.
+		if !cache.WaitForCacheSync(ctx.Done(), kserviceInformer.HasSynced) {
+			logger.Fatal("Failed to wait for the Knative Service cache to sync")
+		}
+	}
+
+	// Events of Ingresses name the users of their Knative Service, for auditing.
+	ctx = withUserAwareEventRecorder(ctx, controllerAgentName)
+
+	var configStore *config.Store
 	impl := ingressreconciler.NewImpl(ctx, c, kourierIngressClassName, func(impl *controller.Impl) controller.Options {
+		// Changes to the skip-list affect arbitrary Ingresses, so all of them are resynced.
+		configStore = config.NewStore(logger.Named("config-store"), func(string, interface{}) {
+			impl.GlobalResync(ingressInformer.Informer())
+		})
+		watchConfigs(cmw, configStore)
 		return controller.Options{
+			// Kourier owns the status of Ingresses, the reconciler only patches its own parts.
 			SkipStatusUpdates: true,
 			FinalizerName:     "ocp-ingress",
+			ConfigStore:       configStore,
 		}
 	})
 
+	c.enqueueAfter = impl.EnqueueAfter
+
 	logger.Info("Setting up event handlers")
 
+	classFilter := reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, kourierIngressClassName, false)
 	ingressInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
-		FilterFunc: reconciler.AnnotationFilterFunc(networking.IngressClassAnnotationKey, kourierIngressClassName, false),
+		FilterFunc: classFilter,
 		Handler:    controller.HandleAll(impl.Enqueue),
 	})
 
@@ -56,5 +182,102 @@ func NewController(
 		)),
 	})
 
+	// LoadBalancer Services are shared by all Ingresses served by the same gateway, so only those
+	// are resynced. The label filter rejects tombstones, so the handler only sees Services.
+	serviceInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: reconciler.LabelFilterFunc(resources.ExposureLabelKey, resources.ExposureLoadBalancerValue, false),
+		Handler: controller.HandleAll(func(obj interface{}) {
+			filter := c.loadBalancerIngressFilter(configStore.ToContext(ctx), obj.(*corev1.Service))
+			impl.FilteredGlobalResync(reconciler.ChainFilterFuncs(classFilter, filter), ingressInformer.Informer())
+		}),
+	})
+
+	// Labels of Knative Services are only copied onto the Routes of their own Ingresses.
+	if kserviceInformer != nil {
+		kserviceInformer.AddEventHandler(controller.HandleAll(func(obj interface{}) {
+			impl.FilteredGlobalResync(reconciler.ChainFilterFuncs(classFilter, c.serviceLabels.ingressFilter(obj)), ingressInformer.Informer())
+		}))
+	}
+
+	gcInterval, err := gcIntervalFromEnv()
+	if err != nil {
+		logger.Fatalw("Failed to read garbage collection interval", zap.Error(err))
+	}
+	// Resyncing all Ingresses deletes Routes they no longer desire. Routes of Ingresses that
+	// don't exist anymore are deleted by the orphan collector.
+	orphans := &orphanCollector{
+		routeLister:   routeInformer.Lister(),
+		ingressLister: ingressInformer.Lister(),
+		routeClient:   c.routeClient,
+		kservices:     &dynamicKServiceClient{client: dynamicclient.Get(ctx)},
+	}
+	go runGC(ctx, gcInterval, func() {
+		logger.Info("Garbage collecting stale routes")
+		impl.GlobalResync(ingressInformer.Informer())
+		// Exported Routes are deleted by the GitOps pipeline applying them.
+		if c.routeOutput == routeOutputConfigMap {
+			return
+		}
+		if err := orphans.collect(ctx); err != nil {
+			logger.Errorw("Failed to garbage collect orphaned routes", zap.Error(err))
+		}
+	})
+
+	go runRouteStatus(ctx, routeStatusInterval, newRouteStatusRecorder(routeInformer.Lister(), ingressInformer.Lister(), c.clock))
+
+	debugAddress, err := debugAddressFromEnv()
+	if err != nil {
+		logger.Fatalw("Failed to read debug endpoint address", zap.Error(err))
+	}
+	go serveDebug(ctx, debugAddress, &debugHandler{
+		reconciler:    c,
+		ingressLister: ingressInformer.Lister(),
+		toContext:     configStore.ToContext,
+	})
+
 	return impl
 }
+
+// watchConfigs watches the ConfigMaps of the given store. They are optional if the watcher
+// supports defaults.
+func watchConfigs(cmw configmap.Watcher, store *config.Store) {
+	dw, ok := cmw.(configmap.DefaultingWatcher)
+	if !ok {
+		store.WatchConfigs(cmw)
+		return
+	}
+	dw.WatchWithDefault(corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.IngressConfigName},
+	}, store.OnConfigChanged)
+}
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// boolFromEnv reads a feature flag from the given environment variable. Flags are disabled by default.
+func boolFromEnv(key string) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	return enabled, nil
+}
+
+// longLabelPolicyFromEnv reads the policy for hosts with too long labels. Such hosts fail by default.
+func longLabelPolicyFromEnv() (resources.LongLabelPolicy, error) {
+	switch policy := resources.LongLabelPolicy(envOrDefault(longLabelPolicyEnvKey, string(resources.LongLabelFail))); policy {
+	case resources.LongLabelFail, resources.LongLabelHash:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%s must be one of %q or %q, got %q",
+			longLabelPolicyEnvKey, resources.LongLabelFail, resources.LongLabelHash, policy)
+	}
+}
@@ -0,0 +1,193 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"go.uber.org/zap"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/logging"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+const (
+	// debugAddressEnvKey configures the address the debug endpoint listens on. It must stay on
+	// localhost, the endpoint isn't authenticated.
+	debugAddressEnvKey  = "DEBUG_ADDRESS"
+	defaultDebugAddress = "localhost:8009"
+
+	debugIngressPath = "/debug/ingress/"
+)
+
+// debugRoute is a Route as reported by the debug endpoint.
+type debugRoute struct {
+	Name       string                          `json:"name"`
+	Namespace  string                          `json:"namespace"`
+	Spec       routev1.RouteSpec               `json:"spec"`
+	Admitted   bool                            `json:"admitted"`
+	Conditions []routev1.RouteIngressCondition `json:"conditions,omitempty"`
+}
+
+// debugReport is the response of the debug endpoint for a single Ingress.
+type debugReport struct {
+	Ingress  string   `json:"ingress"`
+	Skipped  []string `json:"skipped,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	// CanonicalHostname is the hostname of the router that admitted the observed Routes, which
+	// the Knative Service is externally reachable under.
+	CanonicalHostname string       `json:"canonicalHostname,omitempty"`
+	Desired           []debugRoute `json:"desired"`
+	Observed          []debugRoute `json:"observed"`
+}
+
+// debugHandler serves the Routes the controller would generate for an Ingress right now next to
+// the Routes in the informer cache, at /debug/ingress/<namespace>/<name>.
+//
+// The handler only reads from listers: it neither emits events nor records metrics, and
+// never reads from or writes to the API server. Secrets, ConfigMaps and the Services of splits
+// aren't cached, so the Routes depending on them are reported as if they weren't looked up.
+type debugHandler struct {
+	reconciler    *Reconciler
+	ingressLister networkinglisters.IngressLister
+	toContext     func(context.Context) context.Context
+}
+
+func (h *debugHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, debugIngressPath), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, "expected "+debugIngressPath+"<namespace>/<name>", http.StatusNotFound)
+		return
+	}
+
+	ing, err := h.ingressLister.Ingresses(parts[0]).Get(parts[1])
+	if apierrs.IsNotFound(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := req.Context()
+	if h.toContext != nil {
+		ctx = h.toContext(ctx)
+	}
+	report := debugReport{Ingress: parts[0] + "/" + parts[1]}
+
+	if cfg := config.FromContext(ctx); cfg != nil && cfg.Ingress.Skipped(ing.Namespace, ing.Name) {
+		report.Skipped = append(report.Skipped, "ingress is on the skip-list")
+	} else {
+		opts := append(h.reconciler.cachedRouteOptions(ctx), h.reconciler.serviceLabelOptions(ing)...)
+		opts = append(opts,
+			resources.WithSkipFunc(func(reason string) {
+				report.Skipped = append(report.Skipped, reason)
+			}),
+			resources.WithWarningFunc(func(reason, message string) {
+				report.Warnings = append(report.Warnings, reason+": "+message)
+			}))
+		// MakeRoutes is called directly to not record metrics of a reconciliation.
+		desired, err := resources.MakeRoutes(ing, opts...)
+		if err != nil {
+			report.Error = err.Error()
+		}
+		report.Desired = debugRoutes(desired, false)
+	}
+
+	observed, err := h.reconciler.routeList(ing)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	report.Observed = debugRoutes(observed, true)
+	for _, route := range observed {
+		if hostname := resources.RouterCanonicalHostname(route); hostname != "" {
+			report.CanonicalHostname = hostname
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		logging.FromContext(ctx).Warnw("Failed to write debug report", zap.Error(err))
+	}
+}
+
+// debugRoutes converts the given Routes for the debug endpoint. TLS keys and certificates are
+// never included.
+func debugRoutes(routes []*routev1.Route, withStatus bool) []debugRoute {
+	out := make([]debugRoute, 0, len(routes))
+	for _, route := range routes {
+		dr := debugRoute{
+			Name:      route.Name,
+			Namespace: route.Namespace,
+			Spec:      *route.Spec.DeepCopy(),
+		}
+		if tls := dr.Spec.TLS; tls != nil {
+			tls.Key = ""
+			tls.Certificate = ""
+			tls.CACertificate = ""
+		}
+		if withStatus {
+			dr.Admitted = resources.IsRouteAdmitted(route)
+			for _, ingress := range route.Status.Ingress {
+				dr.Conditions = append(dr.Conditions, ingress.Conditions...)
+			}
+		}
+		out = append(out, dr)
+	}
+	return out
+}
+
+// serveDebug serves the given handler on the given address until the context is done.
+func serveDebug(ctx context.Context, addr string, handler http.Handler) {
+	logger := logging.FromContext(ctx)
+	mux := http.NewServeMux()
+	mux.Handle(debugIngressPath, handler)
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Warnw("Failed to shut down debug endpoint", zap.Error(err))
+		}
+	}()
+
+	logger.Infof("Serving debug endpoint on %s", addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Errorw("Debug endpoint failed", zap.Error(err))
+	}
+}
+
+// debugAddressFromEnv returns the address of the debug endpoint. Only loopback addresses are
+// accepted.
+func debugAddressFromEnv() (string, error) {
+	addr := envOrDefault(debugAddressEnvKey, defaultDebugAddress)
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", debugAddressEnvKey, err)
+	}
+	switch host {
+	case "localhost", "127.0.0.1", "::1":
+		return addr, nil
+	default:
+		return "", fmt.Errorf("%s must be a localhost address, was %q", debugAddressEnvKey, addr)
+	}
+}
@@ -0,0 +1,121 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+// fakeBackendServices returns the given Services of splits, regardless of their namespace.
+// Calls to methods it doesn't implement panic on the embedded nil interface.
+type fakeBackendServices struct {
+	corev1client.ServiceInterface
+
+	services map[string]*corev1.Service
+}
+
+func (f *fakeBackendServices) Services(string) corev1client.ServiceInterface {
+	return f
+}
+
+func (f *fakeBackendServices) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.Service, error) {
+	if svc, ok := f.services[name]; ok {
+		return svc, nil
+	}
+	return nil, apierrs.NewNotFound(corev1.Resource("services"), name)
+}
+
+func TestReconcileDirectServiceMode(t *testing.T) {
+	revision := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ingNamespace, Name: "test-00001"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(8012)}},
+		},
+	}
+	withSplits := func(names ...string) ingressOption {
+		return func(i *v1alpha1.Ingress) {
+			for _, name := range names {
+				i.Spec.Rules[0].HTTP.Paths[0].Splits = append(i.Spec.Rules[0].HTTP.Paths[0].Splits, v1alpha1.IngressBackendSplit{
+					IngressBackend: v1alpha1.IngressBackend{
+						ServiceNamespace: ingNamespace,
+						ServiceName:      name,
+						ServicePort:      intstr.FromInt(80),
+					},
+					Percent: 100 / len(names),
+				})
+			}
+		}
+	}
+
+	table := TableTest{{
+		Name:                    "target service of split directly",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withSplits("test-00001")),
+			route(ingressNamespace, routeName),
+		},
+		WantCreates: []runtime.Object{
+			route(ingNamespace, routeName, func(r *routev1.Route) {
+				r.Spec.To.Name = "test-00001"
+				r.Spec.Port.TargetPort = intstr.FromString("http")
+			}),
+		},
+		// The Route targeting the gateway is replaced by the one in the namespace of the split.
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
+		}},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
+	}, {
+		// Only Kourier adds the headers the activator needs to find the revision while scaled to
+		// zero, so the Route keeps targeting the gateway.
+		Name:                    "split appending activator headers",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withSplits("test-00001"), func(i *v1alpha1.Ingress) {
+				i.Spec.Rules[0].HTTP.Paths[0].Splits[0].AppendHeaders = map[string]string{
+					"Knative-Serving-Revision":  "test-00001",
+					"Knative-Serving-Namespace": ingNamespace,
+				}
+			}),
+			route(ingressNamespace, routeName),
+		},
+	}, {
+		Name:                    "service of split doesn't exist",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		WantErr:                 true,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withSplits("test-00001", "test-00002")),
+			route(ingressNamespace, routeName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "BackendServiceNotFound",
+				"Failed to generate routes: backend service %s/test-00002 doesn't exist", ingNamespace),
+		},
+	}}
+	table.Test(t, newFactoryWithConfig(&config.Ingress{
+		KourierSelector:   resources.DefaultKourierSelector,
+		DirectServiceMode: true,
+	}, func(r *Reconciler) {
+		r.serviceClient = &fakeBackendServices{services: map[string]*corev1.Service{revision.Name: revision}}
+	}))
+}
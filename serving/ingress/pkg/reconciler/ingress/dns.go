@@ -0,0 +1,134 @@
+package ingress
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+const (
+	// CNAMETargetsAnnotation is the status annotation of Ingresses listing the hosts outside of
+	// the apps domain of the cluster, each with the canonical hostname of the router that
+	// admitted its Route, as "<host>=<canonical hostname>" separated by commas. The DNS records
+	// of these hosts have to point to the router, which is up to the user.
+	CNAMETargetsAnnotation = "serving.knative.openshift.io/cnameTargets"
+
+	// RouterHostnamesAnnotation is the status annotation of Ingresses listing all hosts of their
+	// admitted Routes, each with the canonical hostname of the router that admitted it, in the
+	// format of the CNAMETargetsAnnotation. The LoadBalancer status only reports the gateway,
+	// as it's owned by Kourier.
+	RouterHostnamesAnnotation = "serving.knative.openshift.io/routerHostnames"
+
+	cnameRequiredReason = "CNAMERequired"
+)
+
+// markCNAMETargets records the hosts of the given Routes that need a CNAME record pointing to
+// their router in the status of the Ingress, and reminds the user of newly listed ones with an
+// event. Hosts are only listed once their Route is admitted, as the router reports its
+// canonical hostname then. It returns true if the status changed.
+func (r *Reconciler) markCNAMETargets(ctx context.Context, ing *v1alpha1.Ingress, routes []*routev1.Route) bool {
+	// Without the apps domain, hosts of the cluster can't be told apart from others.
+	appsDomain := config.FromContext(ctx).Ingress.AppsDomain
+	if appsDomain == "" {
+		return false
+	}
+
+	previous := parseCNAMETargets(ing.Status.Annotations[CNAMETargetsAnnotation])
+	targets := make(map[string]string)
+	for _, desired := range routes {
+		route, err := r.routeLister.Routes(desired.Namespace).Get(desired.Name)
+		if err != nil {
+			continue
+		}
+		host := resources.NormalizeHost(route.Spec.Host)
+		// external-dns creates the records of annotated Routes.
+		if host == "" || resources.InDomain(host, appsDomain) || route.Annotations[resources.ExternalDNSHostnameAnnotation] != "" {
+			continue
+		}
+		canonical := resources.RouterCanonicalHostname(route)
+		if canonical == "" {
+			continue
+		}
+		targets[host] = canonical
+		if previous[host] != canonical {
+			controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeNormal, cnameRequiredReason,
+				"Host %s is outside of the apps domain %s, create a CNAME record pointing it to %s", host, appsDomain, canonical)
+		}
+	}
+
+	value := formatCNAMETargets(targets)
+	if value == ing.Status.Annotations[CNAMETargetsAnnotation] {
+		return false
+	}
+	if value == "" {
+		delete(ing.Status.Annotations, CNAMETargetsAnnotation)
+		return true
+	}
+	if ing.Status.Annotations == nil {
+		ing.Status.Annotations = make(map[string]string, 1)
+	}
+	ing.Status.Annotations[CNAMETargetsAnnotation] = value
+	return true
+}
+
+// markRouterHostnames records the canonical hostnames of the routers that admitted the given
+// Routes by their host in the status of the Ingress. It returns true if the status changed.
+func (r *Reconciler) markRouterHostnames(ing *v1alpha1.Ingress, routes []*routev1.Route) bool {
+	hostnames := make(map[string]string)
+	for _, desired := range routes {
+		route, err := r.routeLister.Routes(desired.Namespace).Get(desired.Name)
+		if err != nil {
+			continue
+		}
+		// Routes with generated hosts only learn their host from the API server.
+		host := resources.NormalizeHost(route.Spec.Host)
+		if canonical := resources.RouterCanonicalHostname(route); host != "" && canonical != "" {
+			hostnames[host] = canonical
+		}
+	}
+
+	value := formatCNAMETargets(hostnames)
+	if value == ing.Status.Annotations[RouterHostnamesAnnotation] {
+		return false
+	}
+	if value == "" {
+		delete(ing.Status.Annotations, RouterHostnamesAnnotation)
+		return true
+	}
+	if ing.Status.Annotations == nil {
+		ing.Status.Annotations = make(map[string]string, 1)
+	}
+	ing.Status.Annotations[RouterHostnamesAnnotation] = value
+	return true
+}
+
+// parseCNAMETargets parses the value of the CNAMETargetsAnnotation into canonical hostnames by
+// host.
+func parseCNAMETargets(value string) map[string]string {
+	targets := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		if i := strings.Index(entry, "="); i > 0 {
+			targets[entry[:i]] = entry[i+1:]
+		}
+	}
+	return targets
+}
+
+// formatCNAMETargets formats the given canonical hostnames by host as the value of the
+// CNAMETargetsAnnotation or the RouterHostnamesAnnotation, sorted by host.
+func formatCNAMETargets(targets map[string]string) string {
+	entries := make([]string, 0, len(targets))
+	for host, canonical := range targets {
+		entries = append(entries, host+"="+canonical)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
@@ -0,0 +1,122 @@
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func withCNAMETargets(value string) ingressOption {
+	return withStatusAnnotation(CNAMETargetsAnnotation, value)
+}
+
+func withRouterHostnames(value string) ingressOption {
+	return withStatusAnnotation(RouterHostnamesAnnotation, value)
+}
+
+func withStatusAnnotation(key, value string) ingressOption {
+	return func(i *v1alpha1.Ingress) {
+		if i.Status.Annotations == nil {
+			i.Status.Annotations = make(map[string]string, 1)
+		}
+		i.Status.Annotations[key] = value
+	}
+}
+
+func TestReconcileCNAMETargets(t *testing.T) {
+	key := ingNamespace + "/" + ingName
+	canonical := "router-default.apps.example.com"
+	cnameRequired := Eventf(corev1.EventTypeNormal, cnameRequiredReason,
+		"Host %s is outside of the apps domain apps.example.com, create a CNAME record pointing it to %s", routeHost, canonical)
+
+	table := TableTest{{
+		Name:                    "remind of the CNAME record",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withRouterHostnames(routeHost+"="+canonical)),
+			route(ingressNamespace, routeName, withAdmitted(canonical)),
+		},
+		WantEvents: []string{cnameRequired},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/annotations/serving.knative.openshift.io~1cnameTargets","value":"test.testns.default.domainname=router-default.apps.example.com"}]`),
+		},
+	}, {
+		Name:                    "reminded already",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withCNAMETargets(routeHost+"="+canonical), withRouterHostnames(routeHost+"="+canonical)),
+			route(ingressNamespace, routeName, withAdmitted(canonical)),
+		},
+	}, {
+		Name:                    "admitted by another router",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withCNAMETargets(routeHost+"=router-old.apps.example.com"), withRouterHostnames(routeHost+"="+canonical)),
+			route(ingressNamespace, routeName, withAdmitted(canonical)),
+		},
+		WantEvents: []string{cnameRequired},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/annotations/serving.knative.openshift.io~1cnameTargets","value":"test.testns.default.domainname=router-default.apps.example.com"}]`),
+		},
+	}, {
+		// The canonical hostname of the router is only known once it admitted the Route.
+		Name:                    "route pending",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName),
+		},
+	}, {
+		Name:                    "host no longer served",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withCNAMETargets("shop.example.com="+canonical)),
+			route(ingressNamespace, routeName),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"remove","path":"/status/annotations/serving.knative.openshift.io~1cnameTargets"}]`),
+		},
+	}}
+	table.Test(t, newFactoryWithConfig(&config.Ingress{
+		AppsDomain:             "apps.example.com",
+		FailedRouteGracePeriod: time.Minute,
+	}, nil))
+
+	inAppsDomain := TableTest{{
+		Name:                    "host in the apps domain",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withRouterHostnames(routeHost+"="+canonical)),
+			route(ingressNamespace, routeName, withAdmitted(canonical)),
+		},
+	}}
+	inAppsDomain.Test(t, newFactoryWithConfig(&config.Ingress{
+		AppsDomain:             "default.domainname",
+		FailedRouteGracePeriod: time.Minute,
+	}, nil))
+
+	// Without the apps domain, hosts of the cluster can't be told apart from others.
+	noAppsDomain := TableTest{{
+		Name:                    "apps domain unknown",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withRouterHostnames(routeHost+"="+canonical)),
+			route(ingressNamespace, routeName, withAdmitted(canonical)),
+		},
+	}}
+	noAppsDomain.Test(t, newFactory(nil))
+}
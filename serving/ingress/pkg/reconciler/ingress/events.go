@@ -0,0 +1,85 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// UserAwareEventRecorder appends the users that created and last modified an object to the
+// messages of its events. Ingresses carry the users of their Knative Service, which allows
+// auditing who triggered changes to Routes.
+type UserAwareEventRecorder struct {
+	record.EventRecorder
+}
+
+var _ record.EventRecorder = (*UserAwareEventRecorder)(nil)
+
+// Event implements record.EventRecorder.
+func (r *UserAwareEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, message+userInfo(object))
+}
+
+// Eventf implements record.EventRecorder.
+func (r *UserAwareEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf implements record.EventRecorder.
+func (r *UserAwareEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", fmt.Sprintf(messageFmt, args...)+userInfo(object))
+}
+
+// withUserAwareEventRecorder returns a context whose event recorder appends user info to event
+// messages. A recorder is created if the context has none, like the generated reconcilers do.
+func withUserAwareEventRecorder(ctx context.Context, agentName string) context.Context {
+	recorder := controller.GetEventRecorder(ctx)
+	if recorder == nil {
+		logger := logging.FromContext(ctx)
+		broadcaster := record.NewBroadcaster()
+		watches := []watch.Interface{
+			broadcaster.StartLogging(logger.Named("event-broadcaster").Infof),
+			broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeclient.Get(ctx).CoreV1().Events("")}),
+		}
+		recorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: agentName})
+		go func() {
+			<-ctx.Done()
+			for _, w := range watches {
+				w.Stop()
+			}
+		}()
+	}
+	return controller.WithEventRecorder(ctx, &UserAwareEventRecorder{EventRecorder: recorder})
+}
+
+// userInfo describes the users that created and last modified the given object, as recorded in
+// its annotations by the Knative webhook.
+func userInfo(object runtime.Object) string {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return ""
+	}
+	creator := accessor.GetAnnotations()[serving.CreatorAnnotation]
+	modifier := accessor.GetAnnotations()[serving.UpdaterAnnotation]
+	switch {
+	case creator != "" && modifier != "":
+		return fmt.Sprintf(" (created by %s, last modified by %s)", creator, modifier)
+	case creator != "":
+		return fmt.Sprintf(" (created by %s)", creator)
+	case modifier != "":
+		return fmt.Sprintf(" (last modified by %s)", modifier)
+	default:
+		return ""
+	}
+}
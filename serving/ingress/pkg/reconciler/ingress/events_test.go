@@ -0,0 +1,74 @@
+package ingress
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+func withUsers(creator, modifier string) func(*v1alpha1.Ingress) {
+	return func(i *v1alpha1.Ingress) {
+		if creator != "" {
+			i.Annotations[serving.CreatorAnnotation] = creator
+		}
+		if modifier != "" {
+			i.Annotations[serving.UpdaterAnnotation] = modifier
+		}
+	}
+}
+
+func TestUserInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		creator  string
+		modifier string
+		want     string
+	}{{
+		name: "no users",
+	}, {
+		name:    "creator",
+		creator: "alice",
+		want:    " (created by alice)",
+	}, {
+		name:     "modifier",
+		modifier: "bob",
+		want:     " (last modified by bob)",
+	}, {
+		name:     "creator and modifier",
+		creator:  "alice",
+		modifier: "bob",
+		want:     " (created by alice, last modified by bob)",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := userInfo(ing(ingNamespace, ingName, withUsers(test.creator, test.modifier))); got != test.want {
+				t.Errorf("userInfo() = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUserAwareEventRecorder(t *testing.T) {
+	fake := record.NewFakeRecorder(3)
+	recorder := &UserAwareEventRecorder{EventRecorder: fake}
+	ing := ing(ingNamespace, ingName, withUsers("alice", "bob"))
+
+	recorder.Event(ing, corev1.EventTypeNormal, "Reason", "message")
+	// Messages are formatted before the users are appended.
+	recorder.Eventf(ing, corev1.EventTypeNormal, "Reason", "message %d%%", 100)
+	recorder.AnnotatedEventf(ing, nil, corev1.EventTypeNormal, "Reason", "message %s", "arg")
+
+	for _, want := range []string{
+		"Normal Reason message (created by alice, last modified by bob)",
+		"Normal Reason message 100% (created by alice, last modified by bob)",
+		"Normal Reason message arg (created by alice, last modified by bob)",
+	} {
+		if got := <-fake.Events; got != want {
+			t.Errorf("Event = %q, want: %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+const (
+	// routeOutputEnvKey configures whether Routes are applied or exported.
+	routeOutputEnvKey = "ROUTE_OUTPUT"
+
+	// routeExportConfigMapName is the name of the ConfigMap Routes are exported to, in the
+	// namespace of their Ingress. It holds the Routes of each Ingress under "<name>.yaml".
+	routeExportConfigMapName = "serverless-ingress-routes"
+)
+
+// routeOutput defines what the controller does with the Routes it generates.
+type routeOutput string
+
+const (
+	// routeOutputAPI creates, updates and deletes Routes through the API server.
+	routeOutputAPI routeOutput = "api"
+
+	// routeOutputConfigMap exports Routes to a ConfigMap for a GitOps pipeline to apply them,
+	// without writing any Route.
+	routeOutputConfigMap routeOutput = "configmap"
+)
+
+// routeOutputFromEnv reads what to do with generated Routes. They're applied by default.
+func routeOutputFromEnv() (routeOutput, error) {
+	switch output := routeOutput(envOrDefault(routeOutputEnvKey, string(routeOutputAPI))); output {
+	case routeOutputAPI, routeOutputConfigMap:
+		return output, nil
+	default:
+		return "", fmt.Errorf("%s must be one of %q or %q, got %q",
+			routeOutputEnvKey, routeOutputAPI, routeOutputConfigMap, output)
+	}
+}
+
+// exportRoutes writes the Routes desired for the given Ingress, and the reasons of the Routes
+// that were skipped, to the export ConfigMap. Failing to generate Routes keeps the previous
+// export.
+func (r *Reconciler) exportRoutes(ctx context.Context, ing *v1alpha1.Ingress) reconciler.Event {
+	var skipped []string
+	if config.FromContext(ctx).Ingress.Skipped(ing.Namespace, ing.Name) {
+		skipped = append(skipped, "ingress is on the skip-list")
+	}
+	routes, err := r.desiredRoutes(ctx, ing, resources.WithSkipFunc(func(reason string) {
+		recordSkippedRoute(ctx)
+		skipped = append(skipped, reason)
+	}))
+	if err != nil {
+		return routeGenerationEvent(ctx, err)
+	}
+	// Exported Routes may target the ExternalName Services, which are no Routes.
+	indirectionCtx, span := startSpan(ctx, "ReconcileGatewayIndirection")
+	err = r.reconcileGatewayIndirection(indirectionCtx, ing)
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := resources.MarshalRoutes(routes)
+	if err != nil {
+		return fmt.Errorf("failed to export routes: %w", err)
+	}
+	var export strings.Builder
+	for _, reason := range skipped {
+		export.WriteString("# Skipped: " + reason + "\n")
+	}
+	export.Write(manifests)
+
+	changed, err := r.writeExport(ctx, ing, export.String())
+	if err != nil {
+		return fmt.Errorf("failed to export routes: %w", err)
+	}
+	if changed {
+		controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeNormal, "Exported",
+			"Exported %d routes to configmap %q", len(routes), routeExportConfigMapName)
+	}
+	return nil
+}
+
+// writeExport sets the export of the given Ingress in the export ConfigMap. It returns whether
+// the export changed.
+func (r *Reconciler) writeExport(ctx context.Context, ing *v1alpha1.Ingress, export string) (bool, error) {
+	key := exportKey(ing)
+	configMaps := r.configMapClient.ConfigMaps(ing.Namespace)
+	cm, err := configMaps.Get(ctx, routeExportConfigMapName, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      routeExportConfigMapName,
+				Namespace: ing.Namespace,
+				Labels:    map[string]string{resources.IngressProviderLabelKey: resources.KourierIngressProvider},
+			},
+			Data: map[string]string{key: export},
+		}
+		_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+		return err == nil, err
+	} else if err != nil {
+		return false, err
+	}
+	if current, ok := cm.Data[key]; ok && current == export {
+		return false, nil
+	}
+
+	// Ingresses of the same namespace share the ConfigMap. Conflicting updates fail and are
+	// retried by the work queue.
+	cm = cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = make(map[string]string, 1)
+	}
+	cm.Data[key] = export
+	_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	return err == nil, err
+}
+
+// deleteExport removes the export of the given Ingress from the export ConfigMap, and deletes
+// the ConfigMap once it holds no exports anymore.
+func (r *Reconciler) deleteExport(ctx context.Context, ing *v1alpha1.Ingress) error {
+	key := exportKey(ing)
+	configMaps := r.configMapClient.ConfigMaps(ing.Namespace)
+	cm, err := configMaps.Get(ctx, routeExportConfigMapName, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get exported routes: %w", err)
+	}
+	if _, ok := cm.Data[key]; !ok {
+		return nil
+	}
+
+	logging.FromContext(ctx).Infof("Deleting exported routes of ingress %s", ing.Name)
+	if len(cm.Data) == 1 {
+		// The resource version makes the deletion fail if another Ingress exported meanwhile.
+		err = configMaps.Delete(ctx, cm.Name, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &cm.ResourceVersion},
+		})
+		if apierrs.IsNotFound(err) {
+			return nil
+		}
+	} else {
+		cm = cm.DeepCopy()
+		delete(cm.Data, key)
+		_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete exported routes: %w", err)
+	}
+	return nil
+}
+
+// exportKey returns the key of the export ConfigMap the Routes of the given Ingress are
+// exported under.
+func exportKey(ing *v1alpha1.Ingress) string {
+	return ing.Name + ".yaml"
+}
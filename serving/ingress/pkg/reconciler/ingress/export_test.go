@@ -0,0 +1,251 @@
+package ingress
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+// fakeExportClient stores ConfigMaps of a single namespace by name. Calls to methods it doesn't
+// implement panic.
+type fakeExportClient struct {
+	corev1client.ConfigMapInterface
+
+	configMaps map[string]*corev1.ConfigMap
+	writes     int
+}
+
+func (f *fakeExportClient) ConfigMaps(string) corev1client.ConfigMapInterface {
+	return f
+}
+
+func (f *fakeExportClient) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.ConfigMap, error) {
+	cm, ok := f.configMaps[name]
+	if !ok {
+		return nil, apierrs.NewNotFound(corev1.Resource("configmaps"), name)
+	}
+	return cm.DeepCopy(), nil
+}
+
+func (f *fakeExportClient) Create(_ context.Context, cm *corev1.ConfigMap, _ metav1.CreateOptions) (*corev1.ConfigMap, error) {
+	f.writes++
+	f.configMaps[cm.Name] = cm.DeepCopy()
+	return cm, nil
+}
+
+func (f *fakeExportClient) Update(_ context.Context, cm *corev1.ConfigMap, _ metav1.UpdateOptions) (*corev1.ConfigMap, error) {
+	f.writes++
+	f.configMaps[cm.Name] = cm.DeepCopy()
+	return cm, nil
+}
+
+func (f *fakeExportClient) Delete(_ context.Context, name string, _ metav1.DeleteOptions) error {
+	f.writes++
+	delete(f.configMaps, name)
+	return nil
+}
+
+func exportConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: routeExportConfigMapName, Namespace: ingNamespace},
+		Data:       data,
+	}
+}
+
+func TestReconcileExport(t *testing.T) {
+	deleted := func(i *v1alpha1.Ingress) {
+		i.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	}
+
+	tests := []struct {
+		name     string
+		row      TableRow
+		existing *corev1.ConfigMap
+		// want is the expected export ConfigMap, nil if it must not exist.
+		want       func(t *testing.T, cm *corev1.ConfigMap)
+		wantWrites int
+	}{{
+		name: "export routes",
+		row: TableRow{
+			Objects: []runtime.Object{
+				ing(ingNamespace, ingName),
+				// Routes applied by the pipeline are left alone, even if they're outdated.
+				route(ingressNamespace, routeName, func(r *routev1.Route) {
+					r.Spec.To.Kind = "foo"
+				}),
+			},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, "Exported", "Exported 1 routes to configmap %q", routeExportConfigMapName),
+			},
+		},
+		want: func(t *testing.T, cm *corev1.ConfigMap) {
+			export := cm.Data[ingName+".yaml"]
+			for _, want := range []string{"kind: Route", "name: " + routeName, "host: " + routeHost} {
+				if !strings.Contains(export, want) {
+					t.Errorf("Export = %s, want it to contain %q", export, want)
+				}
+			}
+		},
+		wantWrites: 1,
+	}, {
+		name: "keep exports of other ingresses",
+		row: TableRow{
+			Objects: []runtime.Object{ing(ingNamespace, ingName)},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, "Exported", "Exported 1 routes to configmap %q", routeExportConfigMapName),
+			},
+		},
+		existing: exportConfigMap(map[string]string{"other.yaml": "---\n"}),
+		want: func(t *testing.T, cm *corev1.ConfigMap) {
+			if cm.Data["other.yaml"] != "---\n" || cm.Data[ingName+".yaml"] == "" {
+				t.Errorf("Data = %v, want exports of both ingresses", cm.Data)
+			}
+		},
+		wantWrites: 1,
+	}, {
+		name: "export skip reasons",
+		row: TableRow{
+			Key:     "skipped/" + ingName,
+			Objects: []runtime.Object{ing("skipped", ingName)},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, "Exported", "Exported 0 routes to configmap %q", routeExportConfigMapName),
+			},
+		},
+		want: func(t *testing.T, cm *corev1.ConfigMap) {
+			if got, want := cm.Data[ingName+".yaml"], "# Skipped: ingress is on the skip-list\n"; got != want {
+				t.Errorf("Export = %q, want %q", got, want)
+			}
+		},
+		wantWrites: 1,
+	}, {
+		name: "keep previous export on invalid annotation",
+		row: TableRow{
+			Objects: []runtime.Object{ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.DisableRouteAnnotation] = "maybe"
+			})},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeWarning, "InvalidAnnotation",
+					`Failed to generate routes: invalid annotation %s: value "maybe" must be one of "true" or "false"`, resources.DisableRouteAnnotation),
+			},
+		},
+		existing: exportConfigMap(map[string]string{ingName + ".yaml": "---\n"}),
+		want: func(t *testing.T, cm *corev1.ConfigMap) {
+			if got := cm.Data[ingName+".yaml"]; got != "---\n" {
+				t.Errorf("Export = %q, want the previous one", got)
+			}
+		},
+	}, {
+		name: "delete last export without deleting routes",
+		row: TableRow{
+			Objects: []runtime.Object{
+				ing(ingNamespace, ingName, deleted),
+				route(ingressNamespace, routeName),
+			},
+			WantPatches: []clientgotesting.PatchActionImpl{{
+				Name:       ingName,
+				ActionImpl: clientgotesting.ActionImpl{Namespace: ingNamespace},
+				Patch:      []byte(`{"metadata":{"finalizers":[],"resourceVersion":""}}`),
+			}},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", ingName),
+			},
+		},
+		existing:   exportConfigMap(map[string]string{ingName + ".yaml": "---\n"}),
+		wantWrites: 1,
+	}, {
+		name: "delete export of one ingress",
+		row: TableRow{
+			Objects: []runtime.Object{ing(ingNamespace, ingName, deleted)},
+			WantPatches: []clientgotesting.PatchActionImpl{{
+				Name:       ingName,
+				ActionImpl: clientgotesting.ActionImpl{Namespace: ingNamespace},
+				Patch:      []byte(`{"metadata":{"finalizers":[],"resourceVersion":""}}`),
+			}},
+			WantEvents: []string{
+				Eventf(corev1.EventTypeNormal, "FinalizerUpdate", "Updated %q finalizers", ingName),
+			},
+		},
+		existing: exportConfigMap(map[string]string{ingName + ".yaml": "---\n", "other.yaml": "---\n"}),
+		want: func(t *testing.T, cm *corev1.ConfigMap) {
+			if _, ok := cm.Data[ingName+".yaml"]; ok || len(cm.Data) != 1 {
+				t.Errorf("Data = %v, want only the export of the other ingress", cm.Data)
+			}
+		},
+		wantWrites: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &fakeExportClient{configMaps: map[string]*corev1.ConfigMap{}}
+			if test.existing != nil {
+				client.configMaps[test.existing.Name] = test.existing
+			}
+
+			row := test.row
+			row.Name = test.name
+			row.SkipNamespaceValidation = true
+			if row.Key == "" {
+				row.Key = ingNamespace + "/" + ingName
+			}
+			TableTest{row}.Test(t, newFactory(func(r *Reconciler) {
+				r.configMapClient = client
+				r.routeOutput = routeOutputConfigMap
+			}))
+
+			if client.writes != test.wantWrites {
+				t.Errorf("Writes = %d, want %d", client.writes, test.wantWrites)
+			}
+			cm, ok := client.configMaps[routeExportConfigMapName]
+			switch {
+			case test.want == nil && ok:
+				t.Errorf("Export configmap = %v, want none", cm.Data)
+			case test.want != nil && !ok:
+				t.Error("Export configmap doesn't exist")
+			case test.want != nil:
+				test.want(t, cm)
+			}
+		})
+	}
+}
+
+func TestRouteOutputFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    routeOutput
+		wantErr bool
+	}{
+		{value: "", want: routeOutputAPI},
+		{value: "api", want: routeOutputAPI},
+		{value: "configmap", want: routeOutputConfigMap},
+		{value: "annotation", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			os.Setenv(routeOutputEnvKey, test.value)
+			defer os.Unsetenv(routeOutputEnvKey)
+
+			got, err := routeOutputFromEnv()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("routeOutputFromEnv() = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("routeOutputFromEnv() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+// reconcileGatewayIndirection ensures the ExternalName Service the Routes of the given Ingress
+// target, if its gateway lives outside of the namespace Routes are created in.
+func (r *Reconciler) reconcileGatewayIndirection(ctx context.Context, ing *v1alpha1.Ingress) error {
+	if !r.externalNameIndirection || r.fallbackGateway == nil {
+		return nil
+	}
+
+	gateway, err := resources.Gateway(ing, r.routeOptions(ctx)...)
+	if errors.Is(err, resources.ErrNoValidLoadbalancerDomain) {
+		// Routes can't be generated either, which is handled by the caller.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if gateway.Namespace == r.fallbackGateway.Namespace {
+		return nil
+	}
+
+	gatewayService, err := r.serviceLister.Services(gateway.Namespace).Get(gateway.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get gateway service %s: %w", gateway, err)
+	}
+	_, err = r.reconcileService(ctx, resources.MakeExternalNameService(r.fallbackGateway.Namespace, gatewayService))
+	return err
+}
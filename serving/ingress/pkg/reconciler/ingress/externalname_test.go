@@ -0,0 +1,74 @@
+package ingress
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+const foreignGatewayNamespace = "kourier-system"
+
+func withForeignGateway(i *v1alpha1.Ingress) {
+	i.Status.PublicLoadBalancer.Ingress[0].DomainInternal = svcName + "." + foreignGatewayNamespace + ".svc.cluster.local"
+}
+
+func foreignGatewayService() *corev1.Service {
+	svc := gatewayService()
+	svc.Namespace = foreignGatewayNamespace
+	return svc
+}
+
+func TestReconcileGatewayNamespaceMismatch(t *testing.T) {
+	table := TableTest{{
+		Name:                    "gateway outside of the route namespace",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withForeignGateway),
+			foreignGatewayService(),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "GatewayNamespaceMismatch",
+				"Failed to generate routes: gateway %s/%s is not in namespace %q routes are created in",
+				foreignGatewayNamespace, svcName, ingressNamespace),
+		},
+	}}
+	table.Test(t, newFactory(nil))
+}
+
+func TestReconcileExternalNameIndirection(t *testing.T) {
+	gateway := types.NamespacedName{Namespace: foreignGatewayNamespace, Name: svcName}
+	services := &fakeServiceClient{}
+
+	table := TableTest{{
+		Name:                    "create route through indirection",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withForeignGateway),
+			foreignGatewayService(),
+		},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, routeName, withPortSelection(resources.PortSelectedByName), func(r *routev1.Route) {
+				r.Spec.To.Name = resources.ExternalNameServiceName(gateway)
+			}),
+		},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
+	}}
+	table.Test(t, newFactory(func(r *Reconciler) {
+		r.externalNameIndirection = true
+		r.serviceClient = services
+	}))
+
+	if want := []string{resources.ExternalNameServiceName(gateway)}; !cmp.Equal(services.created, want) {
+		t.Errorf("Created services (-want, +got) = %s", cmp.Diff(want, services.created))
+	}
+}
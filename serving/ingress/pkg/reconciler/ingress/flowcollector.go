@@ -0,0 +1,131 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/dynamic"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// networkObservabilityEnvKey enables collecting the flows of Routes with the Network
+	// Observability Operator.
+	networkObservabilityEnvKey = "NETWORK_OBSERVABILITY_ENABLED"
+
+	// flowCollectorName is the name of the cluster-wide FlowCollector.
+	flowCollectorName = "cluster"
+
+	// flowCollectorCacheTTL is how long the existence of the FlowCollector is cached, rather
+	// than looking it up on every reconciliation.
+	flowCollectorCacheTTL = 5 * time.Minute
+)
+
+var flowCollectorResource = schema.GroupVersionResource{
+	Group:    "flows.netobserv.io",
+	Version:  "v1beta1",
+	Resource: "flowcollectors",
+}
+
+// FlowCollectorClient looks up the FlowCollector of the Network Observability Operator, which
+// collects the flows of Routes.
+type FlowCollectorClient interface {
+	// FlowCollectorExists returns whether the cluster-wide FlowCollector exists.
+	FlowCollectorExists(ctx context.Context) (bool, error)
+}
+
+// dynamicFlowCollectorClient looks up the cluster-wide FlowCollector. The Network Observability
+// Operator only supports that single FlowCollector, which deploys agents to all nodes. It's
+// owned by the cluster admin and therefore never created by us.
+type dynamicFlowCollectorClient struct {
+	client dynamic.Interface
+}
+
+var _ FlowCollectorClient = (*dynamicFlowCollectorClient)(nil)
+
+// FlowCollectorExists implements FlowCollectorClient.
+func (c *dynamicFlowCollectorClient) FlowCollectorExists(ctx context.Context) (bool, error) {
+	_, err := c.client.Resource(flowCollectorResource).Get(ctx, flowCollectorName, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get FlowCollector: %w", err)
+	}
+	return true, nil
+}
+
+// cachedFlowCollectorClient caches the existence of the FlowCollector for flowCollectorCacheTTL.
+// Failed lookups aren't cached.
+type cachedFlowCollectorClient struct {
+	client FlowCollectorClient
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	exists  bool
+	checked time.Time
+}
+
+var _ FlowCollectorClient = (*cachedFlowCollectorClient)(nil)
+
+// FlowCollectorExists implements FlowCollectorClient.
+func (c *cachedFlowCollectorClient) FlowCollectorExists(ctx context.Context) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checked.IsZero() && c.clock.Since(c.checked) < flowCollectorCacheTTL {
+		return c.exists, nil
+	}
+	exists, err := c.client.FlowCollectorExists(ctx)
+	if err != nil {
+		return false, err
+	}
+	c.exists = exists
+	c.checked = c.clock.Now()
+	return exists, nil
+}
+
+// flowCollectorState tracks whether the FlowCollector was found missing, so that's reported
+// once rather than on every reconciliation.
+type flowCollectorState struct {
+	mu      sync.Mutex
+	missing bool
+}
+
+// update records whether the FlowCollector is missing and returns true if that changed.
+func (s *flowCollectorState) update(missing bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.missing != missing
+	s.missing = missing
+	return changed
+}
+
+// checkFlowCollection warns if the flows of the Routes of the given Ingress aren't collected.
+// The Routes serve traffic regardless, so a missing FlowCollector never fails the reconciliation.
+// It's looked up once per reconciliation rather than once per Route, and reported on the
+// Ingress reconciled when it went missing.
+func (r *Reconciler) checkFlowCollection(ctx context.Context, ing *v1alpha1.Ingress) {
+	exists, err := r.flowCollector.FlowCollectorExists(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Warnw("Failed to check flow collection of routes", zap.Error(err))
+		return
+	}
+	if !r.flowCollectorState.update(!exists) {
+		return
+	}
+	if exists {
+		logging.FromContext(ctx).Infof("FlowCollector %q found, flows of routes are collected", flowCollectorName)
+		return
+	}
+	controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, "FlowCollectorNotFound",
+		"FlowCollector %q not found, the Network Observability Operator has to be set up to collect flows of routes", flowCollectorName)
+}
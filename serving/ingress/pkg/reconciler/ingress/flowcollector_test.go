@@ -0,0 +1,139 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/controller"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+type fakeFlowCollectorClient struct {
+	exists bool
+	err    error
+	calls  int
+}
+
+func (f *fakeFlowCollectorClient) FlowCollectorExists(context.Context) (bool, error) {
+	f.calls++
+	return f.exists, f.err
+}
+
+func TestReconcileFlowCollection(t *testing.T) {
+	key := ingNamespace + "/" + ingName
+	annotated := func(r *routev1.Route) {
+		r.Annotations[resources.FlowCollectionAnnotation] = "true"
+	}
+
+	tests := []struct {
+		name          string
+		flowCollector *fakeFlowCollectorClient
+		wantEvents    []string
+	}{{
+		name:          "flow collector exists",
+		flowCollector: &fakeFlowCollectorClient{exists: true},
+	}, {
+		name:          "missing flow collector",
+		flowCollector: &fakeFlowCollectorClient{},
+		wantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "FlowCollectorNotFound",
+				`FlowCollector "cluster" not found, the Network Observability Operator has to be set up to collect flows of routes`),
+		},
+	}, {
+		name:          "flow collector lookup fails",
+		flowCollector: &fakeFlowCollectorClient{err: errors.New("forbidden")},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := TableTest{{
+				Name:                    test.name,
+				SkipNamespaceValidation: true,
+				Key:                     key,
+				Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
+				// The Route is served regardless of the FlowCollector.
+				WantCreates: []runtime.Object{route(ingressNamespace, routeName, annotated)},
+				WantEvents:  append([]string{routeCreated(routeName, routeHost)}, test.wantEvents...),
+			}}
+			table.Test(t, newFactory(func(r *Reconciler) {
+				r.flowCollector = test.flowCollector
+			}))
+
+			if test.flowCollector.calls != 1 {
+				t.Errorf("FlowCollector looked up %d times, want once per reconciliation", test.flowCollector.calls)
+			}
+		})
+	}
+}
+
+func TestCheckFlowCollectionReportsChanges(t *testing.T) {
+	flowCollector := &fakeFlowCollectorClient{}
+	r := &Reconciler{flowCollector: flowCollector}
+	recorder := record.NewFakeRecorder(10)
+	ctx := controller.WithEventRecorder(context.Background(), recorder)
+	ingress := ing(ingNamespace, ingName)
+
+	for i := 0; i < 3; i++ {
+		r.checkFlowCollection(ctx, ingress)
+	}
+	// Once the FlowCollector exists, going missing again is reported again.
+	flowCollector.exists = true
+	r.checkFlowCollection(ctx, ingress)
+	flowCollector.exists = false
+	r.checkFlowCollection(ctx, ingress)
+	r.checkFlowCollection(ctx, ingress)
+
+	want := `Warning FlowCollectorNotFound FlowCollector "cluster" not found, the Network Observability Operator has to be set up to collect flows of routes`
+	for i := 0; i < 2; i++ {
+		if got := <-recorder.Events; got != want {
+			t.Errorf("Event = %q, want: %q", got, want)
+		}
+	}
+	if got := len(recorder.Events); got != 0 {
+		t.Errorf("Got %d more events, want one per change", got)
+	}
+}
+
+func TestCachedFlowCollectorClient(t *testing.T) {
+	fakeClock := clock.NewFakeClock(now)
+	flowCollector := &fakeFlowCollectorClient{err: errors.New("forbidden")}
+	c := &cachedFlowCollectorClient{client: flowCollector, clock: fakeClock}
+	ctx := context.Background()
+
+	// Failed lookups are retried.
+	for i := 0; i < 2; i++ {
+		if _, err := c.FlowCollectorExists(ctx); err == nil {
+			t.Error("FlowCollectorExists() = nil, want the error of the lookup")
+		}
+	}
+	flowCollector.err = nil
+	flowCollector.exists = true
+	for i := 0; i < 2; i++ {
+		if exists, err := c.FlowCollectorExists(ctx); err != nil || !exists {
+			t.Errorf("FlowCollectorExists() = %v, %v, want: true, nil", exists, err)
+		}
+	}
+	if flowCollector.calls != 3 {
+		t.Errorf("FlowCollector looked up %d times, want 3", flowCollector.calls)
+	}
+
+	// Removing the FlowCollector is noticed once the cached lookup expired.
+	flowCollector.exists = false
+	fakeClock.Step(flowCollectorCacheTTL - time.Second)
+	if exists, _ := c.FlowCollectorExists(ctx); !exists {
+		t.Error("FlowCollectorExists() = false before the cache expired, want: true")
+	}
+	fakeClock.Step(time.Second)
+	if exists, _ := c.FlowCollectorExists(ctx); exists {
+		t.Error("FlowCollectorExists() = true after the cache expired, want: false")
+	}
+}
@@ -0,0 +1,68 @@
+package ingress
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/metrics"
+)
+
+const (
+	// IngressConditionGateway is false while no Routes can be generated for the Ingress, as the
+	// gateway serving it is unknown. Its last transition time is when that started. It's
+	// informational and doesn't affect the readiness of the Ingress, which is owned by Kourier.
+	IngressConditionGateway apis.ConditionType = "Gateway"
+
+	// gatewayUnavailableReason separates Ingresses failing because of the platform, like Kourier
+	// not reporting its gateway, from those failing because of their configuration.
+	gatewayUnavailableReason = "GatewayUnavailable"
+)
+
+// markGateway records in the Gateway condition of the given Ingress whether the gateway serving
+// it is known. It returns true if the status changed.
+func markGateway(ing *v1alpha1.Ingress, available bool) bool {
+	existing := ing.Status.GetCondition(IngressConditionGateway)
+	cond := apis.Condition{
+		Type:     IngressConditionGateway,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	}
+	if available {
+		// Only report the gateway once it was unavailable.
+		if existing == nil || existing.IsTrue() {
+			return false
+		}
+	} else {
+		// The transition time has to stay when the Ingress keeps failing.
+		if existing != nil && existing.IsFalse() {
+			return false
+		}
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = gatewayUnavailableReason
+		cond.Message = "The gateway serving the ingress is not reported in its LoadBalancer status"
+	}
+	ing.GetConditionSet().Manage(&ing.Status).SetCondition(cond)
+	return true
+}
+
+// recordGatewayUnavailable records how many of the given Ingresses are failing, as their gateway
+// is unknown, and for how long the one failing the longest has been.
+func recordGatewayUnavailable(ctx context.Context, ingresses []*v1alpha1.Ingress, now time.Time) {
+	count := int64(0)
+	oldest := time.Duration(0)
+	for _, ing := range ingresses {
+		cond := ing.Status.GetCondition(IngressConditionGateway)
+		if cond == nil || !cond.IsFalse() || cond.Reason != gatewayUnavailableReason {
+			continue
+		}
+		count++
+		if age := now.Sub(cond.LastTransitionTime.Inner.Time); age > oldest {
+			oldest = age
+		}
+	}
+	metrics.Record(ctx, gatewayUnavailableIngressesM.M(count))
+	metrics.Record(ctx, gatewayUnavailableAgeM.M(oldest.Seconds()))
+}
@@ -0,0 +1,70 @@
+package ingress
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func withGateway(status corev1.ConditionStatus) ingressOption {
+	return func(i *v1alpha1.Ingress) {
+		cond := apis.Condition{
+			Type:     IngressConditionGateway,
+			Status:   status,
+			Severity: apis.ConditionSeverityInfo,
+		}
+		if status == corev1.ConditionFalse {
+			cond.Reason = gatewayUnavailableReason
+			cond.Message = "The gateway serving the ingress is not reported in its LoadBalancer status"
+		}
+		i.GetConditionSet().Manage(&i.Status).SetCondition(cond)
+	}
+}
+
+// withoutGatewayDomain makes Kourier report a gateway without its internal domain.
+func withoutGatewayDomain(i *v1alpha1.Ingress) {
+	i.Status.PublicLoadBalancer.Ingress[0].DomainInternal = ""
+}
+
+func TestReconcileGatewayUnavailable(t *testing.T) {
+	key := ingNamespace + "/" + ingName
+
+	table := TableTest{{
+		Name:                    "gateway unavailable",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withoutGatewayDomain),
+			route(ingressNamespace, routeName),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/conditions","value":[{"type":"Gateway","status":"False","severity":"Info","lastTransitionTime":"2020-12-01T00:00:00Z","reason":"GatewayUnavailable","message":"The gateway serving the ingress is not reported in its LoadBalancer status"}]}]`),
+		},
+	}, {
+		Name:                    "gateway still unavailable",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withoutGatewayDomain, withGateway(corev1.ConditionFalse)),
+			route(ingressNamespace, routeName),
+		},
+	}, {
+		Name:                    "gateway available again",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withGateway(corev1.ConditionFalse)),
+			route(ingressNamespace, routeName),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"test","path":"/status/conditions/0/type","value":"Gateway"},{"op":"replace","path":"/status/conditions/0","value":{"type":"Gateway","status":"True","severity":"Info","lastTransitionTime":"2020-12-01T00:00:00Z"}}]`),
+		},
+	}}
+	table.Test(t, newFactory(nil))
+}
@@ -0,0 +1,166 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/dynamic"
+	"knative.dev/networking/pkg/apis/networking"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/serving"
+
+	routev1client "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/clientset/versioned/typed/route/v1"
+	routev1lister "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/listers/route/v1"
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+const (
+	// gcIntervalEnvKey is the environment variable to configure the interval of the garbage
+	// collection of stale Routes.
+	gcIntervalEnvKey = "GC_INTERVAL"
+
+	// defaultGCInterval is used if no interval is configured.
+	defaultGCInterval = 10 * time.Minute
+
+	// minGCInterval is the smallest accepted interval. Each garbage collection resyncs all
+	// Ingresses in the cluster, so running it too often puts a lot of load on the API server.
+	minGCInterval = time.Minute
+)
+
+// gcIntervalFromEnv returns the garbage collection interval configured via the environment.
+//
+// Stale Routes are usually cleaned up as part of the reconciliation of their Ingress. The
+// garbage collection is a safety net for Routes that were missed, for example because they
+// were created while the controller was down. Shorter intervals clean up faster, at the
+// cost of listing and reconciling every Ingress more often.
+func gcIntervalFromEnv() (time.Duration, error) {
+	raw := os.Getenv(gcIntervalEnvKey)
+	if raw == "" {
+		return defaultGCInterval, nil
+	}
+
+	interval, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", gcIntervalEnvKey, err)
+	}
+	if interval < minGCInterval {
+		return 0, fmt.Errorf("%s must be at least %v, was %v", gcIntervalEnvKey, minGCInterval, interval)
+	}
+	return interval, nil
+}
+
+// runGC calls gc every interval until the context is done.
+func runGC(ctx context.Context, interval time.Duration, gc func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			gc()
+		}
+	}
+}
+
+// KServiceClient looks up the Knative Services Routes were generated for.
+type KServiceClient interface {
+	// GCDisabled returns whether the garbage collection of the given Knative Service is disabled
+	// by the no-gc annotation. Services that don't exist don't disable it.
+	GCDisabled(ctx context.Context, namespace, name string) (bool, error)
+}
+
+// dynamicKServiceClient gets Knative Services on demand. Only orphaned Routes need them, which
+// are rare, so they aren't cached.
+type dynamicKServiceClient struct {
+	client dynamic.Interface
+}
+
+var _ KServiceClient = (*dynamicKServiceClient)(nil)
+
+// GCDisabled implements KServiceClient.
+func (c *dynamicKServiceClient) GCDisabled(ctx context.Context, namespace, name string) (bool, error) {
+	svc, err := c.client.Resource(kserviceResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get knative service: %w", err)
+	}
+	return svc.GetAnnotations()[serving.RevisionPreservedAnnotationKey] == "true", nil
+}
+
+// orphanCollector deletes generated Routes whose Ingress no longer exists. Resyncing Ingresses
+// only cleans up after Ingresses that still exist, Routes are left behind if the finalizer of
+// their Ingress was bypassed, for example by removing it by hand.
+type orphanCollector struct {
+	routeLister   routev1lister.RouteLister
+	ingressLister networkinglisters.IngressLister
+	routeClient   routev1client.RouteV1Interface
+	// kservices keeps the Routes of Knative Services annotated with
+	// serving.knative.dev/no-gc: "true", just like Knative keeps their Revisions.
+	kservices KServiceClient
+}
+
+// collect deletes all orphaned Routes.
+func (c *orphanCollector) collect(ctx context.Context) error {
+	routes, err := listGeneratedRoutes(c.routeLister)
+	if err != nil {
+		return err
+	}
+
+	logger := logging.FromContext(ctx)
+	for _, route := range routes {
+		if !isOrphan(c.ingressLister, route) {
+			continue
+		}
+		if disabled, err := c.gcDisabled(ctx, route); err != nil {
+			return err
+		} else if disabled {
+			logger.Infof("Keeping route %s/%s(%s) of deleted ingress, garbage collection of its service is disabled", route.Namespace, route.Name, route.Spec.Host)
+			continue
+		}
+		logger.Infof("Deleting route %s/%s(%s) of deleted ingress", route.Namespace, route.Name, route.Spec.Host)
+		err := c.routeClient.Routes(route.Namespace).Delete(ctx, route.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete orphaned route: %w", err)
+		}
+	}
+	return nil
+}
+
+// gcDisabled returns whether the Knative Service the given Route was generated for disables its
+// garbage collection. Routes not generated for a Knative Service are always collected.
+func (c *orphanCollector) gcDisabled(ctx context.Context, route *routev1.Route) (bool, error) {
+	name := route.Labels[serving.ServiceLabelKey]
+	if c.kservices == nil || name == "" {
+		return false, nil
+	}
+	return c.kservices.GCDisabled(ctx, route.Labels[serving.RouteNamespaceLabelKey], name)
+}
+
+// listGeneratedRoutes lists all Routes generated for Ingresses.
+func listGeneratedRoutes(lister routev1lister.RouteLister) ([]*routev1.Route, error) {
+	generated, err := labels.NewRequirement(networking.IngressLabelKey, selection.Exists, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select generated routes: %w", err)
+	}
+	routes, err := lister.List(labels.NewSelector().Add(*generated))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routes: %w", err)
+	}
+	return routes, nil
+}
+
+// isOrphan returns true if the Ingress the given Route was generated for doesn't exist anymore.
+func isOrphan(lister networkinglisters.IngressLister, route *routev1.Route) bool {
+	_, err := lister.Ingresses(route.Labels[serving.RouteNamespaceLabelKey]).Get(route.Labels[networking.IngressLabelKey])
+	return apierrs.IsNotFound(err)
+}
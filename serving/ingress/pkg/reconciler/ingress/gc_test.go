@@ -0,0 +1,182 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/serving/pkg/apis/serving"
+
+	fakerouteclientset "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/clientset/versioned/fake"
+	. "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/testing"
+)
+
+func TestGCIntervalFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{{
+		name: "unset",
+		want: defaultGCInterval,
+	}, {
+		name:  "valid",
+		value: "30m",
+		want:  30 * time.Minute,
+	}, {
+		name:  "minimum",
+		value: "1m",
+		want:  time.Minute,
+	}, {
+		name:    "below minimum",
+		value:   "30s",
+		wantErr: true,
+	}, {
+		name:    "garbage",
+		value:   "often",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.value != "" {
+				os.Setenv(gcIntervalEnvKey, test.value)
+				defer os.Unsetenv(gcIntervalEnvKey)
+			}
+
+			got, err := gcIntervalFromEnv()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("gcIntervalFromEnv() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("gcIntervalFromEnv() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRunGC(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fired := make(chan struct{}, 1)
+	go runGC(ctx, 10*time.Millisecond, func() {
+		select {
+		case fired <- struct{}{}:
+		default:
+		}
+	})
+
+	select {
+	case <-fired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("garbage collection did not fire")
+	}
+}
+
+func TestOrphanCollector(t *testing.T) {
+	orphaned := route(ingressNamespace, "orphaned", func(r *routev1.Route) {
+		r.Labels[networking.IngressLabelKey] = "gone"
+	})
+	unrelated := route(ingressNamespace, "unrelated", func(r *routev1.Route) {
+		delete(r.Labels, networking.IngressLabelKey)
+	})
+	objs := []runtime.Object{ing(ingNamespace, ingName), route(ingressNamespace, routeName), orphaned, unrelated}
+
+	listers := NewListers(objs)
+	client := fakerouteclientset.NewSimpleClientset(listers.GetRouteObjects()...)
+	collector := &orphanCollector{
+		routeLister:   listers.GetRouteLister(),
+		ingressLister: listers.GetIngressLister(),
+		routeClient:   client.RouteV1(),
+	}
+	if err := collector.collect(context.Background()); err != nil {
+		t.Fatalf("collect() = %v", err)
+	}
+
+	var deleted []string
+	for _, action := range client.Actions() {
+		if action, ok := action.(clientgotesting.DeleteAction); ok {
+			deleted = append(deleted, action.GetNamespace()+"/"+action.GetName())
+		}
+	}
+	if want := ingressNamespace + "/orphaned"; len(deleted) != 1 || deleted[0] != want {
+		t.Errorf("Deleted %v, want: [%s]", deleted, want)
+	}
+}
+
+// fakeKServiceClient disables the garbage collection of the given Knative Services.
+type fakeKServiceClient struct {
+	disabled map[string]bool
+	err      error
+}
+
+func (f *fakeKServiceClient) GCDisabled(_ context.Context, namespace, name string) (bool, error) {
+	return f.disabled[namespace+"/"+name], f.err
+}
+
+func TestOrphanCollectorNoGC(t *testing.T) {
+	orphanOf := func(name, service string) *routev1.Route {
+		return route(ingressNamespace, name, func(r *routev1.Route) {
+			r.Labels[networking.IngressLabelKey] = "gone"
+			r.Labels[serving.ServiceLabelKey] = service
+		})
+	}
+
+	tests := []struct {
+		name        string
+		kservices   *fakeKServiceClient
+		wantDeleted []string
+		wantErr     bool
+	}{{
+		name:        "gc disabled",
+		kservices:   &fakeKServiceClient{disabled: map[string]bool{ingNamespace + "/preserved": true}},
+		wantDeleted: []string{ingressNamespace + "/collected"},
+	}, {
+		name:        "gc enabled",
+		kservices:   &fakeKServiceClient{},
+		wantDeleted: []string{ingressNamespace + "/collected", ingressNamespace + "/preserved"},
+	}, {
+		name:      "services unknown",
+		kservices: &fakeKServiceClient{err: errors.New("forbidden")},
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objs := []runtime.Object{orphanOf("collected", "collected"), orphanOf("preserved", "preserved")}
+			listers := NewListers(objs)
+			client := fakerouteclientset.NewSimpleClientset(listers.GetRouteObjects()...)
+			collector := &orphanCollector{
+				routeLister:   listers.GetRouteLister(),
+				ingressLister: listers.GetIngressLister(),
+				routeClient:   client.RouteV1(),
+				kservices:     test.kservices,
+			}
+			if err := collector.collect(context.Background()); (err != nil) != test.wantErr {
+				t.Fatalf("collect() = %v, want error: %v", err, test.wantErr)
+			}
+
+			var deleted []string
+			for _, action := range client.Actions() {
+				if action, ok := action.(clientgotesting.DeleteAction); ok {
+					deleted = append(deleted, action.GetNamespace()+"/"+action.GetName())
+				}
+			}
+			sort.Strings(deleted)
+			if !cmp.Equal(deleted, test.wantDeleted, cmpopts.EquateEmpty()) {
+				t.Errorf("Deleted (-want, +got) = %s", cmp.Diff(test.wantDeleted, deleted))
+			}
+		})
+	}
+}
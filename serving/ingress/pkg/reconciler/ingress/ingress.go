@@ -2,29 +2,81 @@ package ingress
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"go.opencensus.io/trace"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	networkingv1alpha1client "knative.dev/networking/pkg/client/clientset/versioned/typed/networking/v1alpha1"
 	ingressreconciler "knative.dev/networking/pkg/client/injection/reconciler/networking/v1alpha1/ingress"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/reconciler"
 	"knative.dev/serving/pkg/apis/serving"
 
 	routev1client "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/clientset/versioned/typed/route/v1"
 	routev1lister "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/listers/route/v1"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
 	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
 	routev1 "github.com/openshift/api/route/v1"
 )
 
+// hostMismatchCorrectedReason is the reason of events about Routes recreated because their host
+// was changed by hand.
+const hostMismatchCorrectedReason = "HostMismatchCorrected"
+
 // Reconciler implements controller.Reconciler for Ingress resources.
 type Reconciler struct {
-	routeLister routev1lister.RouteLister
-	routeClient routev1client.RouteV1Interface
+	routeLister   routev1lister.RouteLister
+	routeClient   routev1client.RouteV1Interface
+	ingressClient networkingv1alpha1client.NetworkingV1alpha1Interface
+	serviceLister corev1listers.ServiceLister
+	serviceClient corev1client.ServicesGetter
+	// secretClient is nil if secrets of certificates are not looked up.
+	secretClient corev1client.SecretsGetter
+	// configMapClient is nil if ConfigMaps referenced by Ingresses are not looked up.
+	configMapClient corev1client.ConfigMapsGetter
+
+	fallbackGateway *types.NamespacedName
+	// gatewayHTTPPort is the name of the port of gateway Services Routes preferably target.
+	gatewayHTTPPort string
+	strictTLS       bool
+	longLabelPolicy resources.LongLabelPolicy
+
+	// externalNameIndirection makes Routes reach gateways outside of the namespace of the
+	// fallback gateway through an ExternalName Service.
+	externalNameIndirection bool
+
+	// flowCollector is nil if flows of Routes are not collected.
+	flowCollector FlowCollectorClient
+	// flowCollectorState tracks whether a missing FlowCollector was reported already.
+	flowCollectorState flowCollectorState
+
+	// serviceLabels is nil if no labels of Knative Services are copied onto Routes.
+	serviceLabels *serviceLabelCopier
+
+	// routeOutput defines whether Routes are applied or exported.
+	routeOutput routeOutput
+
+	// loadBalancerSweep tracks whether LoadBalancer Services may be left over from the
+	// LoadBalancer exposure mode.
+	loadBalancerSweep loadBalancerSweep
+
+	clock        clock.Clock
+	enqueueAfter func(obj interface{}, after time.Duration)
 }
 
 var _ ingressreconciler.Interface = (*Reconciler)(nil)
@@ -32,6 +84,18 @@ var _ ingressreconciler.Finalizer = (*Reconciler)(nil)
 
 // FinalizeKind finalizes ingress resource.
 func (r *Reconciler) FinalizeKind(ctx context.Context, ing *v1alpha1.Ingress) reconciler.Event {
+	ctx, span := startSpan(ctx, "FinalizeKind", ingressAttributes(ing)...)
+	err := r.finalize(ctx, ing)
+	endSpan(span, err)
+	return err
+}
+
+func (r *Reconciler) finalize(ctx context.Context, ing *v1alpha1.Ingress) reconciler.Event {
+	// Exported Routes are applied by others, who delete them along with the export.
+	if r.routeOutput == routeOutputConfigMap {
+		return r.deleteExport(ctx, ing)
+	}
+
 	routes, err := r.routeList(ing)
 	if err != nil {
 		return fmt.Errorf("failed to list routes for deletion: %w", err)
@@ -47,28 +111,93 @@ func (r *Reconciler) FinalizeKind(ctx context.Context, ing *v1alpha1.Ingress) re
 
 // ReconcileKind reconciles ingress resource.
 func (r *Reconciler) ReconcileKind(ctx context.Context, ing *v1alpha1.Ingress) reconciler.Event {
+	ctx, span := startSpan(ctx, "ReconcileKind", ingressAttributes(ing)...)
+	err := r.reconcile(ctx, ing)
+	endSpan(span, err)
+	return err
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, ing *v1alpha1.Ingress) reconciler.Event {
 	logger := logging.FromContext(ctx)
 
 	existing, err := r.routeList(ing)
 	if err != nil {
 		return fmt.Errorf("failed to list routes: %w", err)
 	}
-	existingMap := make(map[string]*routev1.Route, len(existing))
+	// Routes are keyed by namespace and name, as Routes targeting Services directly are created in
+	// the namespace of the Services rather than the gateway's.
+	existingMap := make(map[types.NamespacedName]*routev1.Route, len(existing))
+	existingByKey := make(map[string]*routev1.Route, len(existing))
 	for _, route := range existing {
-		existingMap[route.Name] = route
+		existingMap[routeNamespacedName(route)] = route
+		existingByKey[routeKey(route)] = route
+	}
+
+	if config.FromContext(ctx).Ingress.ExposureMode == config.ExposureLoadBalancer {
+		ctx, span := startSpan(ctx, "ReconcileLoadBalancer")
+		err := r.reconcileLoadBalancer(ctx, ing, existing)
+		endSpan(span, err)
+		return err
+	}
+	// Clean up after the LoadBalancer exposure mode, in case it was used before.
+	if err := r.sweepLoadBalancerServices(ctx); err != nil {
+		return err
+	}
+
+	if err := r.checkGatewayMigration(ctx); err != nil {
+		return err
+	}
+	if r.routeOutput == routeOutputConfigMap {
+		ctx, span := startSpan(ctx, "ExportRoutes")
+		err := r.exportRoutes(ctx, ing)
+		endSpan(span, err)
+		return err
+	}
+	// Routes of hosts that became cluster-local keep exposing them to the internet. They're
+	// deleted even if generating the other Routes fails below.
+	if err := r.deleteClusterLocalRoutes(ctx, ing, existingMap); err != nil {
+		return err
 	}
 
-	routes, err := resources.MakeRoutes(ing)
+	routes, err := r.desiredRoutes(ctx, ing)
 	if err != nil {
-		logger.Warnf("Failed to generate routes from ingress %v", err)
-		// Returning nil aborts the reconciliation. It will be retriggered once the status of the ingress changes.
-		return nil
+		before := ing.Status.DeepCopy()
+		if errors.Is(err, resources.ErrNoValidLoadbalancerDomain) && markGateway(ing, false) {
+			if err := r.patchStatus(ctx, ing, before); err != nil {
+				return err
+			}
+		}
+		return routeGenerationEvent(ctx, err)
+	}
+	indirectionCtx, span := startSpan(ctx, "ReconcileGatewayIndirection")
+	err = r.reconcileGatewayIndirection(indirectionCtx, ing)
+	endSpan(span, err)
+	if err != nil {
+		return err
 	}
+	var conflicts []*resources.HostOwnershipConflict
 	for _, route := range routes {
-		if err := r.reconcileRoute(ctx, route); err != nil {
+		// Routes created before hosts were normalized are named after the raw host, and Routes
+		// restored along with their Ingress are named after its previous UID. Adopt them rather
+		// than creating a second route the router refuses, as the existing one holds the host.
+		if _, ok := existingMap[routeNamespacedName(route)]; !ok {
+			if adopted, ok := existingByKey[routeKey(route)]; ok && sameTarget(adopted, route) {
+				logger.Infof("Adopting route %s for host %s", adopted.Name, route.Spec.Host)
+				route.Name = adopted.Name
+			}
+		}
+		if err := r.reconcileRoute(ctx, ing, route); err != nil {
+			if conflict, ok := resources.APIHostOwnershipConflict(err); ok {
+				conflicts = append(conflicts, conflict)
+				delete(existingMap, routeNamespacedName(route))
+				continue
+			}
 			return err
 		}
-		delete(existingMap, route.Name)
+		delete(existingMap, routeNamespacedName(route))
+	}
+	if r.flowCollector != nil && len(routes) > 0 {
+		r.checkFlowCollection(ctx, ing)
 	}
 	// If routes remains in existingMap, it must be obsoleted routes. Clean them up.
 	for _, rt := range existingMap {
@@ -76,41 +205,367 @@ func (r *Reconciler) ReconcileKind(ctx context.Context, ing *v1alpha1.Ingress) r
 			return err
 		}
 	}
+	// Reported once, when the Routes of an Ingress that lost its rules are deleted, rather than on
+	// every reconciliation of an Ingress without rules.
+	if len(ing.Spec.Rules) == 0 && len(existingMap) > 0 {
+		controller.GetEventRecorder(ctx).Event(ing, corev1.EventTypeNormal, "NoRulesFound",
+			"The ingress has no rules, its routes are deleted")
+	}
 
-	return nil
+	conflicts = append(conflicts, r.routeHostOwnershipConflicts(routes)...)
+	// The router publishes the canonical hostname the Routes are reachable under in their
+	// status. It's not propagated to the LoadBalancer status of the Ingress, which Kourier owns.
+	before := ing.Status.DeepCopy()
+	changed := markHostOwnership(ctx, ing, conflicts)
+	if r.markRouteAdmitted(ing, routes) {
+		changed = true
+	}
+	if r.markCNAMETargets(ctx, ing, routes) {
+		changed = true
+	}
+	if r.markRouterHostnames(ing, routes) {
+		changed = true
+	}
+	if markMaintenance(ing) {
+		changed = true
+	}
+	// Left behind by the LoadBalancer exposure mode, in case it was used before.
+	if markLoadBalancerAddress(ing, "", "") {
+		changed = true
+	}
+	if markGateway(ing, true) {
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return r.patchStatus(ctx, ing, before)
+}
+
+// routeGenerationEvent returns the event the reconciliation fails with if generating Routes
+// failed with the given error. Errors resolving on their own are retried with backoff, errors
+// the user has to fix are reported with a warning and not retried until the Ingress changes.
+// Errors resolved by a change of the Ingress status, like a gateway Kourier hasn't reported
+// yet, abort the reconciliation without an event.
+func routeGenerationEvent(ctx context.Context, err error) reconciler.Event {
+	var certErr *resources.MissingCertificateError
+	var pendingErr *resources.PendingCertificateError
+	var missingWhitelistErr *resources.MissingIPWhitelistError
+	var whitelistErr *resources.InvalidIPWhitelistError
+	var labelErr *resources.HostLabelTooLongError
+	var mismatchErr *resources.GatewayNamespaceMismatchError
+	var portErr *resources.NoGatewayPortError
+	var prefixErr *resources.PathPrefixConflictError
+	var aliasErr *resources.AliasHostConflictError
+	var backendErr *resources.MissingBackendServiceError
+	var gatewayErr *resources.MissingGatewayServiceError
+	var lookupErr *lookupError
+	switch {
+	case errors.As(err, &lookupErr):
+		// Returning the error retries the reconciliation with backoff.
+		return err
+	case errors.As(err, &pendingErr):
+		// The certificate is being issued. Wrapping the event makes the reconciliation fail, so
+		// it's retried with the backoff of the work queue rather than waiting for the next change.
+		return fmt.Errorf("certificate not ready: %w",
+			reconciler.NewEvent(corev1.EventTypeNormal, "CertificatePending", "Waiting for certificate: %v", err))
+	case errors.As(err, &missingWhitelistErr):
+		// The ConfigMap may be created after the Ingress, so retry with backoff as well. Existing
+		// Routes keep their previous whitelist meanwhile.
+		return fmt.Errorf("IP whitelist not found: %w",
+			reconciler.NewEvent(corev1.EventTypeWarning, "IPWhitelistNotFound", "Failed to generate routes: %v", err))
+	case errors.As(err, &gatewayErr):
+		// The gateway may be deployed after the Ingress was annotated. Gateway Services aren't
+		// watched, so retry with backoff. Existing Routes keep their previous gateway meanwhile.
+		return fmt.Errorf("gateway service not found: %w",
+			reconciler.NewEvent(corev1.EventTypeWarning, "GatewayServiceNotFound", "Failed to generate routes: %v", err))
+	case errors.As(err, &backendErr):
+		// Knative creates the Services of revisions alongside the Ingress. They aren't watched,
+		// so retry with backoff. Existing Routes keep their previous backends meanwhile.
+		return fmt.Errorf("backend service not found: %w",
+			reconciler.NewEvent(corev1.EventTypeWarning, "BackendServiceNotFound", "Failed to generate routes: %v", err))
+	// The user has to fix the Ingress or its Knative Service in the following cases, retrying won't help.
+	case errors.Is(err, resources.ErrInvalidAnnotation):
+		return reconciler.NewEvent(corev1.EventTypeWarning, "InvalidAnnotation", "Failed to generate routes: %v", err)
+	case errors.As(err, &certErr):
+		return reconciler.NewEvent(corev1.EventTypeWarning, "MissingCertificate", "Failed to generate routes: %v", err)
+	case errors.As(err, &whitelistErr):
+		return reconciler.NewEvent(corev1.EventTypeWarning, "InvalidIPWhitelist", "Failed to generate routes: %v", err)
+	case errors.As(err, &labelErr):
+		return reconciler.NewEvent(corev1.EventTypeWarning, "HostLabelTooLong", "Failed to generate routes: %v", err)
+	case errors.As(err, &mismatchErr):
+		// The gateway is misconfigured, which the admin has to fix.
+		return reconciler.NewEvent(corev1.EventTypeWarning, "GatewayNamespaceMismatch", "Failed to generate routes: %v", err)
+	case errors.As(err, &portErr):
+		return reconciler.NewEvent(corev1.EventTypeWarning, "NoGatewayPort", "Failed to generate routes: %v", err)
+	case errors.As(err, &prefixErr):
+		return reconciler.NewEvent(corev1.EventTypeWarning, "PathPrefixConflict", "Failed to generate routes: %v", err)
+	case errors.As(err, &aliasErr):
+		return reconciler.NewEvent(corev1.EventTypeWarning, "AliasHostConflict", "Failed to generate routes: %v", err)
+	case errors.Is(err, resources.ErrNoValidLoadbalancerDomain):
+		// Kourier reports the gateway in the status of the Ingress, which retriggers the reconciliation.
+		logging.FromContext(ctx).Infof("Waiting for the gateway of ingress: %v", err)
+		return nil
+	default:
+		logging.FromContext(ctx).Warnf("Failed to generate routes from ingress %v", err)
+		// Returning nil aborts the reconciliation. It will be retriggered once the status of the ingress changes.
+		return nil
+	}
+}
+
+// desiredRoutes returns the Routes that should exist for the given Ingress. The given options
+// take precedence over the ones of the Reconciler.
+func (r *Reconciler) desiredRoutes(ctx context.Context, ing *v1alpha1.Ingress, extra ...resources.Option) ([]*routev1.Route, error) {
+	if config.FromContext(ctx).Ingress.Skipped(ing.Namespace, ing.Name) {
+		logging.FromContext(ctx).Info("Ingress is on the skip-list, not generating routes")
+		return nil, nil
+	}
+	if len(ing.Spec.Rules) == 0 {
+		logging.FromContext(ctx).Debug("Ingress has no rules, not generating routes")
+	}
+	opts := append(r.routeOptions(ctx), r.serviceLabelOptions(ing)...)
+	opts = append(opts, resources.WithWarningFunc(func(reason, message string) {
+		controller.GetEventRecorder(ctx).Event(ing, corev1.EventTypeWarning, reason, message)
+	}))
+	opts = append(opts, extra...)
+	ctx, span := startSpan(ctx, "GenerateRoutes")
+	routes, err := makeRoutes(ctx, ing, opts...)
+	span.AddAttributes(trace.Int64Attribute(routeCountAttribute, int64(len(routes))))
+	endSpan(span, err)
+	return routes, err
+}
+
+// routeOptions returns the options to generate the Routes of an Ingress with.
+func (r *Reconciler) routeOptions(ctx context.Context) []resources.Option {
+	opts := r.cachedRouteOptions(ctx)
+	if cfg := config.FromContext(ctx); cfg != nil && cfg.Ingress.DirectServiceMode && r.serviceClient != nil {
+		opts = append(opts, resources.WithDirectServiceMode(func(namespace, name string, port intstr.IntOrString) (string, bool, error) {
+			return r.backendTargetPort(ctx, namespace, name, port)
+		}))
+	}
+	if r.secretClient != nil {
+		opts = append(opts, resources.WithSecretExistsFunc(func(namespace, name string) (bool, error) {
+			return r.secretExists(ctx, namespace, name)
+		}))
+	}
+	if r.configMapClient != nil {
+		opts = append(opts, resources.WithConfigMapFunc(func(namespace, name string) (map[string]string, bool) {
+			return r.configMapData(ctx, namespace, name)
+		}))
+	}
+	return opts
+}
+
+// cachedRouteOptions returns the options to generate the Routes of an Ingress with, leaving out
+// the ones reading secrets, ConfigMaps and the Services of splits from the API. Routes depending
+// on them are generated as if they weren't looked up.
+func (r *Reconciler) cachedRouteOptions(ctx context.Context) []resources.Option {
+	logger := logging.FromContext(ctx)
+	opts := []resources.Option{
+		resources.WithSkipFunc(func(reason string) {
+			logger.Infof("Skipping route generation: %s", reason)
+			recordSkippedRoute(ctx)
+		}),
+	}
+	if cfg := config.FromContext(ctx); cfg != nil {
+		opts = append(opts,
+			resources.WithKourierSelector(cfg.Ingress.KourierSelector),
+			resources.WithAppsDomain(cfg.Ingress.AppsDomain),
+			resources.WithHTTP2(cfg.Ingress.HTTP2))
+		if cfg.Ingress.DomainTemplate != nil {
+			opts = append(opts, resources.WithDomainTemplate(*cfg.Ingress.DomainTemplate))
+		}
+		if cfg.Ingress.ExternalDNS != nil {
+			opts = append(opts, resources.WithExternalDNS(*cfg.Ingress.ExternalDNS))
+		}
+		if cfg.Ingress.GatewayMigration != nil {
+			opts = append(opts, resources.WithGatewayMigration(*cfg.Ingress.GatewayMigration))
+		}
+		if cfg.Ingress.DefaultRouteTimeout > 0 {
+			opts = append(opts, resources.WithDefaultTimeout(cfg.Ingress.DefaultRouteTimeout))
+		}
+		if cfg.Ingress.MaintenanceService != "" {
+			opts = append(opts, resources.WithMaintenanceService(cfg.Ingress.MaintenanceService))
+		}
+		if cfg.Ingress.MinimalRouteAnnotations {
+			opts = append(opts, resources.WithMinimalAnnotations())
+		}
+		if cfg.Ingress.TenantLabel != nil {
+			opts = append(opts, resources.WithTenantLabel(*cfg.Ingress.TenantLabel))
+		}
+		if cfg.Ingress.TerminationPolicy != nil {
+			opts = append(opts, resources.WithTerminationPolicy(cfg.Ingress.TerminationPolicy))
+		}
+		opts = append(opts, resources.WithTLSDefaults(cfg.Ingress.TLSDefaults))
+	}
+	if r.serviceLister != nil {
+		opts = append(opts,
+			resources.WithTargetPortFunc(r.gatewayTargetPort),
+			resources.WithServiceExistsFunc(r.serviceExists))
+	}
+	if r.fallbackGateway != nil {
+		opts = append(opts,
+			resources.WithFallbackGateway(r.fallbackGateway.Namespace, r.fallbackGateway.Name),
+			resources.WithRouteNamespace(r.fallbackGateway.Namespace))
+	}
+	if r.externalNameIndirection {
+		opts = append(opts, resources.WithExternalNameIndirection())
+	}
+	if r.strictTLS {
+		opts = append(opts, resources.WithStrictTLS(true))
+	}
+	if r.routeLister != nil {
+		opts = append(opts, resources.WithHostRoutesFunc(r.hostRoutes))
+	}
+	if r.flowCollector != nil {
+		opts = append(opts, resources.WithFlowCollection())
+	}
+	if r.longLabelPolicy != "" {
+		opts = append(opts, resources.WithLongLabelPolicy(r.longLabelPolicy))
+	}
+	return opts
+}
+
+// serviceLabelOptions returns the options copying labels of the Knative Service of the given
+// Ingress onto its Routes.
+func (r *Reconciler) serviceLabelOptions(ing *v1alpha1.Ingress) []resources.Option {
+	if r.serviceLabels == nil {
+		return nil
+	}
+	return []resources.Option{resources.WithServiceLabels(r.serviceLabels.labels(ing))}
+}
+
+// gatewayTargetPort returns the port of the given gateway Service Routes have to target, as
+// declared by the Service, and how it was selected. Route generation falls back to
+// resources.KourierHTTPPort if the Service isn't known.
+func (r *Reconciler) gatewayTargetPort(namespace, name string) (string, string, error) {
+	svc, err := r.serviceLister.Services(namespace).Get(name)
+	if err != nil {
+		return "", "", nil
+	}
+	preferred := r.gatewayHTTPPort
+	if preferred == "" {
+		preferred = resources.KourierHTTPPort
+	}
+	return resources.GatewayHTTPPort(svc, preferred)
+}
+
+// backendTargetPort returns the target port of a Route reaching the given port of the given
+// Service of a split, and whether the Service exists. The Services of splits are read from the
+// API, as the informer only caches the gateway Services.
+func (r *Reconciler) backendTargetPort(ctx context.Context, namespace, name string, port intstr.IntOrString) (string, bool, error) {
+	svc, err := r.serviceClient.Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get backend service %s/%s: %w", namespace, name, err)
+	}
+	return resources.ServicePortTarget(svc, port), true, nil
+}
+
+// serviceExists returns true unless the given Service is known to not exist.
+func (r *Reconciler) serviceExists(namespace, name string) bool {
+	_, err := r.serviceLister.Services(namespace).Get(name)
+	return !apierrs.IsNotFound(err)
+}
+
+// secretExists returns whether the given secret exists. Secrets are read from the API rather
+// than cached, as only the few of certificates served by the gateway are of interest.
+func (r *Reconciler) secretExists(ctx context.Context, namespace, name string) (bool, error) {
+	_, err := r.secretClient.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, &lookupError{err: fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)}
+	}
+	return true, nil
+}
+
+// lookupError wraps errors reading objects from the API while generating Routes, like a
+// missing permission of the controller. Unlike errors of the Ingress, they're retried.
+type lookupError struct {
+	err error
+}
+
+func (e *lookupError) Error() string {
+	return e.err.Error()
+}
+
+func (e *lookupError) Unwrap() error {
+	return e.err
+}
+
+// configMapData returns the data of the given ConfigMap, and whether it could be read. ConfigMaps
+// are read from the API rather than cached, as only the few referenced by Ingresses are of
+// interest. Changes of them are picked up by the periodic resync of the Ingresses.
+func (r *Reconciler) configMapData(ctx context.Context, namespace, name string) (map[string]string, bool) {
+	cm, err := r.configMapClient.ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, false
+	}
+	return cm.Data, true
 }
 
 func (r *Reconciler) deleteRoute(ctx context.Context, route *routev1.Route) error {
 	logger := logging.FromContext(ctx)
 	logger.Infof("Deleting route %s(%s)", route.Name, route.Spec.Host)
-	if err := r.routeClient.Routes(route.Namespace).Delete(ctx, route.Name, metav1.DeleteOptions{}); err != nil {
+	ctx, span := startSpan(ctx, "DeleteRoute", routeAttributes(route)...)
+	err := r.routeClient.Routes(route.Namespace).Delete(ctx, route.Name, metav1.DeleteOptions{})
+	endSpan(span, err)
+	if err != nil {
 		return fmt.Errorf("failed to delete route: %w", err)
 	}
 	return nil
 }
 
-func (r *Reconciler) reconcileRoute(ctx context.Context, desired *routev1.Route) error {
+func (r *Reconciler) reconcileRoute(ctx context.Context, ing *v1alpha1.Ingress, desired *routev1.Route) error {
+	ctx, span := startSpan(ctx, "ReconcileRoute", routeAttributes(desired)...)
+	err := r.applyRoute(ctx, ing, desired)
+	endSpan(span, err)
+	return err
+}
+
+func (r *Reconciler) applyRoute(ctx context.Context, ing *v1alpha1.Ingress, desired *routev1.Route) error {
 	logger := logging.FromContext(ctx)
 
 	// Check if this Route already exists
 	route, err := r.routeLister.Routes(desired.Namespace).Get(desired.Name)
-	if errors.IsNotFound(err) {
+	if apierrs.IsNotFound(err) {
 		logger.Infof("Creating route %s(%s)", desired.Name, desired.Spec.Host)
-		if _, err := r.routeClient.Routes(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+		if err := r.createRoute(ctx, ing, desired); err != nil {
 			return fmt.Errorf("failed to create route :%w", err)
 		}
 	} else if err != nil {
 		return fmt.Errorf("failed to get route: %w", err)
-	} else if !equality.Semantic.DeepEqual(route.Spec, desired.Spec) ||
-		!equality.Semantic.DeepEqual(route.Annotations, desired.Annotations) ||
-		!equality.Semantic.DeepEqual(route.Labels, desired.Labels) {
-		// Don't modify the informers copy
-		existing := route.DeepCopy()
-		existing.Spec = desired.Spec
-		existing.Annotations = desired.Annotations
-		existing.Labels = desired.Labels
-
-		if _, err := r.routeClient.Routes(existing.Namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+	} else if r.routeFailed(ctx, ing, route) {
+		// The router never retries a rejected Route, so it has to be recreated.
+		logger.Infof("Recreating route %s(%s) rejected by the router", route.Name, route.Spec.Host)
+		if err := r.deleteRoute(ctx, route); err != nil {
+			return err
+		}
+		if err := r.createRoute(ctx, ing, desired); err != nil {
+			return fmt.Errorf("failed to recreate route :%w", err)
+		}
+	} else if existing, changed := diffRoute(ctx, route, desired); changed && resources.RequiresRecreation(route, desired) {
+		// Some fields, like the host, can't be changed in place.
+		logger.Infof("Recreating route %s(%s) as it can't be updated in place", route.Name, route.Spec.Host)
+		if err := r.deleteRoute(ctx, route); err != nil {
+			return err
+		}
+		if err := r.createRoute(ctx, ing, desired); err != nil {
+			return fmt.Errorf("failed to recreate route :%w", err)
+		}
+		if resources.NormalizeHost(route.Spec.Host) != resources.NormalizeHost(desired.Spec.Host) {
+			controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeWarning, hostMismatchCorrectedReason,
+				"Recreated route %q: host %q did not match desired %q", route.Name, route.Spec.Host, desired.Spec.Host)
+		}
+	} else if changed {
+		_, span := startSpan(ctx, "UpdateRoute", routeAttributes(existing)...)
+		_, err := r.routeClient.Routes(existing.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
+		endSpan(span, err)
+		if err != nil {
 			return fmt.Errorf("failed to update route :%w", err)
 		}
 	}
@@ -118,6 +573,147 @@ func (r *Reconciler) reconcileRoute(ctx context.Context, desired *routev1.Route)
 	return nil
 }
 
+func (r *Reconciler) createRoute(ctx context.Context, ing *v1alpha1.Ingress, route *routev1.Route) error {
+	ctx, span := startSpan(ctx, "CreateRoute", routeAttributes(route)...)
+	_, err := r.routeClient.Routes(route.Namespace).Create(ctx, route, metav1.CreateOptions{})
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	recordCreatedRoute(ctx)
+	controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeNormal, "Created", "Created route %q for host %q", route.Name, route.Spec.Host)
+	return nil
+}
+
+// diffRoute returns the given existing Route updated to the desired state, and whether that
+// differs from the existing one.
+func diffRoute(ctx context.Context, route, desired *routev1.Route) (*routev1.Route, bool) {
+	_, span := startSpan(ctx, "DiffRoute", routeAttributes(route)...)
+	defer span.End()
+
+	// cert-manager owns the certificate of the Route, if it issued it.
+	resources.PreserveCertificate(desired, route)
+	resources.PreserveGeneratedHost(desired, route)
+	resources.PreserveDefaultWeight(desired, route)
+	resources.PreserveTimeout(desired, route)
+	resources.PreserveAnnotations(desired, route, config.FromContext(ctx).Ingress.KeptAnnotationPrefixes())
+	if equality.Semantic.DeepEqual(route.Spec, desired.Spec) &&
+		equality.Semantic.DeepEqual(route.Annotations, desired.Annotations) &&
+		equality.Semantic.DeepEqual(route.Labels, desired.Labels) {
+		return nil, false
+	}
+	// Don't modify the informers copy
+	existing := route.DeepCopy()
+	existing.Spec = desired.Spec
+	existing.Annotations = desired.Annotations
+	existing.Labels = desired.Labels
+	return existing, true
+}
+
+// routeFailed returns true if the given Route has been rejected by the router for longer than
+// the grace period. Routes that haven't failed for long enough are checked again after the
+// grace period passed.
+func (r *Reconciler) routeFailed(ctx context.Context, ing *v1alpha1.Ingress, route *routev1.Route) bool {
+	_, span := startSpan(ctx, "CheckRouteAdmission", routeAttributes(route)...)
+	defer span.End()
+
+	gracePeriod := config.FromContext(ctx).Ingress.FailedRouteGracePeriod
+	if gracePeriod == 0 {
+		return false
+	}
+	since, failed := resources.RouteFailedSince(route)
+	if !failed {
+		return false
+	}
+	// The router admits the Route on its own once the host is released.
+	if _, conflict := resources.RouteHostOwnershipConflict(route); conflict {
+		return false
+	}
+
+	if remaining := gracePeriod - r.clock.Since(since); remaining > 0 {
+		if r.enqueueAfter != nil {
+			r.enqueueAfter(ing, remaining)
+		}
+		return false
+	}
+	return true
+}
+
+// deleteClusterLocalRoutes deletes the Routes of hosts the Ingress only serves inside of the
+// cluster, and removes them from the given existing Routes.
+func (r *Reconciler) deleteClusterLocalRoutes(ctx context.Context, ing *v1alpha1.Ingress, existing map[types.NamespacedName]*routev1.Route) error {
+	local := clusterLocalHosts(ing)
+	if local.Len() == 0 {
+		return nil
+	}
+	for key, route := range existing {
+		host := resources.NormalizeHost(route.Spec.Host)
+		if !local.Has(host) {
+			continue
+		}
+		if err := r.deleteRoute(ctx, route); err != nil {
+			return err
+		}
+		delete(existing, key)
+		controller.GetEventRecorder(ctx).Eventf(ing, corev1.EventTypeNormal, "Deleted",
+			"Deleted route %q, host %q is only visible inside of the cluster", route.Name, host)
+	}
+	return nil
+}
+
+// clusterLocalHosts returns the normalized hosts of the cluster-local rules of the given
+// Ingress, which no external rule serves as well.
+func clusterLocalHosts(ing *v1alpha1.Ingress) sets.String {
+	local, external := sets.NewString(), sets.NewString()
+	for _, rule := range ing.Spec.Rules {
+		for _, host := range rule.Hosts {
+			if rule.Visibility == v1alpha1.IngressVisibilityClusterLocal {
+				local.Insert(resources.NormalizeHost(host))
+			} else {
+				external.Insert(resources.NormalizeHost(host))
+			}
+		}
+	}
+	return local.Difference(external)
+}
+
+// routeKey returns the host and path the given Route serves, which identify the Routes of an
+// Ingress independent of their names. All Routes with generated hosts serve the rules without
+// hosts.
+func routeKey(route *routev1.Route) string {
+	host := resources.NormalizeHost(route.Spec.Host)
+	if route.Annotations[resources.HostGeneratedAnnotation] == "true" {
+		host = ""
+	}
+	return host + route.Spec.Path
+}
+
+// routeNamespacedName returns the namespace and name of the given Route.
+func routeNamespacedName(route *routev1.Route) types.NamespacedName {
+	return types.NamespacedName{Namespace: route.Namespace, Name: route.Name}
+}
+
+// sameTarget returns true if both Routes send traffic to the same gateway. Only then an existing
+// Route can be adopted for the desired one, as it's updated in place.
+func sameTarget(existing, desired *routev1.Route) bool {
+	return existing.Namespace == desired.Namespace && existing.Spec.To.Name == desired.Spec.To.Name
+}
+
+// hostRoutes returns the Routes of the given host in all namespaces.
+func (r *Reconciler) hostRoutes(host string) []*routev1.Route {
+	routes, err := r.routeLister.List(labels.Everything())
+	if err != nil {
+		return nil
+	}
+	var matching []*routev1.Route
+	for _, route := range routes {
+		if route.Spec.Host == host {
+			matching = append(matching, route)
+		}
+	}
+	return matching
+}
+
 func (r *Reconciler) routeList(ing *v1alpha1.Ingress) ([]*routev1.Route, error) {
 	ingressLabels := ing.GetLabels()
 	return r.routeLister.List(labels.SelectorFromSet(map[string]string{
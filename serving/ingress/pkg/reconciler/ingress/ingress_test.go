@@ -2,15 +2,23 @@ package ingress
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	fakerouteclient "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/injection/client/fake"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
 	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	clientgotesting "k8s.io/client-go/testing"
 	"knative.dev/networking/pkg/apis/networking"
 	"knative.dev/networking/pkg/apis/networking/v1alpha1"
@@ -36,9 +44,27 @@ const (
 
 	svcName    = "kourier-ingressgateway"
 	domainName = ingName + "." + ingNamespace + ".default.domainName"
-	routeName  = "route-" + ingUID + "-306330363338"
+
+	// routeHost and routeName are the host and name of the Route generated for domainName,
+	// which is normalized before being hashed.
+	routeHost = "test.testns.default.domainname"
+	routeName = "route-" + ingUID + "-653034346535"
+	// aliasRouteName is the name of the Route generated for the alias host www.example.com.
+	aliasRouteName = "route-" + ingUID + "-383066633066"
+	// generatedRouteName is the name of the route of rules without hosts, see generatedHostRouteName.
+	generatedRouteName = ingName + "-61b6a9"
+
+	// legacyRouteName is the name of the Route generated for domainName before hosts were
+	// normalized.
+	legacyRouteName = "route-" + ingUID + "-306330363338"
+	// restoredRouteName is the name of the route of the host generated for a previous UID of the Ingress.
+	restoredRouteName = "route-0a2b3c4d-fbc6-11e9-a88e-0261aff8d6d8-653034346535"
+
+	canonicalHostname = "router-default.apps.example.com"
 )
 
+var now = time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+
 func TestReconcile(t *testing.T) {
 	key := ingNamespace + "/" + ingName
 
@@ -46,92 +72,753 @@ func TestReconcile(t *testing.T) {
 		Name: "bad workqueue key",
 		Key:  "too/many/parts",
 	}, {
-		Name: "key not found",
-		Key:  "foo/not-found",
+		Name: "key not found",
+		Key:  "foo/not-found",
+	}, {
+		Name:    "steady state",
+		Key:     key,
+		Objects: []runtime.Object{ing(ingNamespace, ingName), route(ingressNamespace, routeName)},
+	}, {
+		Name:                    "create route",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
+		WantCreates:             []runtime.Object{route(ingressNamespace, routeName)},
+		WantEvents:              []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "name the users of the service in the route creation event",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName, withUsers("alice", "bob"))},
+		WantCreates: []runtime.Object{route(ingressNamespace, routeName, func(r *routev1.Route) {
+			r.Annotations[serving.CreatorAnnotation] = "alice"
+			r.Annotations[serving.UpdaterAnnotation] = "bob"
+		})},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", "Created route %q for host %q (created by alice, last modified by bob)", routeName, routeHost),
+		},
+	}, {
+		Name:                    "create route before the ingress is ready",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Status = v1alpha1.IngressStatus{}
+			}),
+		},
+		WantCreates: []runtime.Object{route(ingressNamespace, routeName)},
+		WantEvents:  []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "remove outdated routes",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName),
+			route(ingressNamespace, "foo"), // This gets deleted.
+			route(ingressNamespace, "foo2", func(r *routev1.Route) {
+				r.Labels[serving.RouteLabelKey] = "foo"
+			}), // This doesn't cause the label doesn't match.
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: "foo",
+		}},
+	}, {
+		Name:                    "ingress without rules",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules = nil
+			}),
+			route(ingressNamespace, routeName),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "NoRulesFound", "The ingress has no rules, its routes are deleted"),
+		},
+	}, {
+		// No events are recorded on every reconciliation.
+		Name:                    "ingress without rules and routes",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules = nil
+			}),
+		},
+	}, {
+		Name:                    "copy annotations and labels",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations["foo.bar/baz"] = "baz"
+				i.Labels["foo.bar/baz"] = "baz"
+			}),
+		},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations["foo.bar/baz"] = "baz"
+				r.Labels["foo.bar/baz"] = "baz"
+			}),
+		},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "copy annotations and labels on update too",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations["foo.bar/baz"] = "baz"
+				i.Labels["foo.bar/baz"] = "baz"
+			}),
+			route(ingressNamespace, routeName),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations["foo.bar/baz"] = "baz"
+				r.Labels["foo.bar/baz"] = "baz"
+			}),
+		}},
+	}, {
+		Name:                    "fix spec",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Spec.To.Kind = "foo"
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName),
+		}},
+	}, {
+		Name:                    "add missing timeout",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules[0].HTTP = nil
+			}),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				delete(r.Annotations, resources.TimeoutAnnotation)
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.TimeoutAnnotation] = "10m"
+			}),
+		}},
+	}, {
+		// Earlier versions formatted the default timeout in seconds.
+		Name:                    "keep timeout of the same duration after upgrade",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules[0].HTTP = nil
+			}),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.TimeoutAnnotation] = "600s"
+			}),
+		},
+	}, {
+		Name:                    "remove health check interval if unset",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.HealthCheckIntervalAnnotation] = "5m"
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName),
+		}},
+	}, {
+		Name:                    "remove external-dns annotations if disabled",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.ExternalDNSHostnameAnnotation] = routeHost
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName),
+		}},
+	}, {
+		Name:                    "keep annotations added by cluster admins",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations["openshift.io/admin-note"] = "managed by ops"
+				r.Spec.To.Kind = "foo"
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations["openshift.io/admin-note"] = "managed by ops"
+			}),
+		}},
+	}, {
+		Name:                    "path prefix taken by another ingress",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.PathPrefixAnnotation] = "/payments"
+			}),
+			route(ingressNamespace, "other-route", func(r *routev1.Route) {
+				r.Labels[networking.IngressLabelKey] = "other"
+				r.Spec.Path = "/payments"
+			}),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "PathPrefixConflict",
+				"Failed to generate routes: path /payments of host %s overlaps with path /payments of route %s/other-route", routeHost, ingressNamespace),
+		},
+	}, {
+		Name:                    "create route of alias host",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.AliasHostsAnnotation] = "www.example.com"
+			}),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.AliasHostsAnnotation] = "www.example.com"
+			}),
+		},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, aliasRouteName, func(r *routev1.Route) {
+				r.Labels[resources.AliasLabelKey] = "true"
+				r.Annotations[resources.AliasHostsAnnotation] = "www.example.com"
+				r.Spec.Host = "www.example.com"
+			}),
+		},
+		WantEvents: []string{routeCreated(aliasRouteName, "www.example.com")},
+	}, {
+		Name:                    "prune route of removed alias host",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName),
+			route(ingressNamespace, aliasRouteName, func(r *routev1.Route) {
+				r.Labels[resources.AliasLabelKey] = "true"
+				r.Annotations[resources.AliasHostsAnnotation] = "www.example.com"
+				r.Spec.Host = "www.example.com"
+			}),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: aliasRouteName,
+		}},
+	}, {
+		Name:                    "alias host taken by another ingress",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.AliasHostsAnnotation] = "www.example.com"
+			}),
+			route(ingressNamespace, "other-route", func(r *routev1.Route) {
+				r.Labels[networking.IngressLabelKey] = "other"
+				r.Spec.Host = "www.example.com"
+			}),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "AliasHostConflict",
+				"Failed to generate routes: alias host www.example.com is served by route %s/other-route of another ingress", ingressNamespace),
+		},
+	}, {
+		Name:                    "keep certificate issued by cert-manager",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.CertManagerIssuerAnnotation] = "letsencrypt"
+			}),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.CertManagerIssuerAnnotation] = "letsencrypt"
+				r.Annotations["cert-manager.io/issuer-name"] = "letsencrypt"
+				r.Spec.TLS.Certificate = "cert"
+				r.Spec.TLS.Key = "key"
+			}),
+		},
+	}, {
+		Name:                    "recreate failed route",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withRejected(now.Add(-2*time.Minute))),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
+		}},
+		WantCreates: []runtime.Object{route(ingressNamespace, routeName)},
+		WantEvents:  []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "recreate route whose host was changed by hand",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Spec.Host = "manual.example.com"
+			}),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
+		}},
+		WantCreates: []runtime.Object{route(ingressNamespace, routeName)},
+		WantEvents: []string{
+			routeCreated(routeName, routeHost),
+			Eventf(corev1.EventTypeWarning, "HostMismatchCorrected",
+				`Recreated route %q: host "manual.example.com" did not match desired "test.testns.default.domainname"`, routeName),
+		},
+	}, {
+		Name:                    "update timeout in place",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.TimeoutAnnotation] = "1s"
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName),
+		}},
+	}, {
+		Name:                    "recreate route on change if requested",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.RecreateOnChangeAnnotation] = "true"
+			}),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.RecreateOnChangeAnnotation] = "true"
+				r.Spec.To.Name = "old-gateway"
+			}),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
+		}},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.RecreateOnChangeAnnotation] = "true"
+			}),
+		},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "keep route failed within the grace period",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withRejected(now.Add(-30*time.Second))),
+		},
+	}, {
+		Name:                    "create nothing",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.DisableRouteAnnotation] = "true"
+			}),
+		},
+	}, {
+		Name:                    "target the HTTP port of the gateway service",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			gatewayService(func(svc *corev1.Service) {
+				svc.Spec.Ports[0].Name = "http"
+			}),
+		},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, routeName, withPortSelection(resources.PortSelectedByProtocol), func(r *routev1.Route) {
+				r.Spec.Port.TargetPort = intstr.FromString("http")
+			}),
+		},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "gateway service without HTTP port",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			gatewayService(func(svc *corev1.Service) {
+				svc.Spec.Ports = []corev1.ServicePort{{Name: "https", Port: 443}}
+			}),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "NoGatewayPort",
+				"Failed to generate routes: gateway service %s/%s has no HTTP port Routes can target, its ports are [https/443]", ingressNamespace, svcName),
+		},
+	}, {
+		Name:                    "target the gateway service of the annotation",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.GatewayServiceAnnotation] = "kourier-experimental"
+			}),
+			gatewayService(func(svc *corev1.Service) {
+				svc.Name = "kourier-experimental"
+			}),
+		},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, routeName, withPortSelection(resources.PortSelectedByName), func(r *routev1.Route) {
+				r.Annotations[resources.GatewayServiceAnnotation] = "kourier-experimental"
+				r.Spec.To.Name = "kourier-experimental"
+			}),
+		},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "gateway service of the annotation doesn't exist",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.GatewayServiceAnnotation] = "kourier-experimental"
+			}),
+			route(ingressNamespace, routeName),
+		},
+		WantErr: true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "GatewayServiceNotFound",
+				"Failed to generate routes: gateway service %s/kourier-experimental requested by %s doesn't exist", ingressNamespace, resources.GatewayServiceAnnotation),
+		},
+	}, {
+		Name:                    "target the default gateway again once the annotation is removed",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.GatewayServiceAnnotation] = "kourier-experimental"
+				r.Spec.To.Name = "kourier-experimental"
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName),
+		}},
+	}, {
+		Name:                    "adopt route created before hosts were normalized",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, legacyRouteName, func(r *routev1.Route) {
+				r.Spec.Host = domainName
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, legacyRouteName),
+		}},
+	}, {
+		Name:                    "adopt route of a host with a trailing dot",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules[0].Hosts = []string{"Test.TestNs.Default.DomainName."}
+			}),
+			route(ingressNamespace, "legacy", func(r *routev1.Route) {
+				r.Spec.Host = "Test.TestNs.Default.DomainName."
+			}),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, "legacy"),
+		}},
+	}, {
+		// Restoring an Ingress from a backup recreates it with a new UID.
+		Name:                    "adopt route of a restored ingress",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, restoredRouteName),
+		},
+	}, {
+		Name:                    "adopt route with generated host of a restored ingress",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules[0].Hosts = nil
+			}),
+			route(ingressNamespace, ingName+"-123456", withGeneratedHost(ingName+"-123456-"+ingressNamespace+".apps.example.com")),
+		},
+	}, {
+		Name:                    "don't adopt route of a restored ingress targeting another gateway",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, restoredRouteName, func(r *routev1.Route) {
+				r.Spec.To.Name = "other-gateway"
+			}),
+		},
+		WantCreates: []runtime.Object{route(ingressNamespace, routeName)},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: restoredRouteName,
+		}},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "steady state with a lowercase host",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules[0].Hosts = []string{routeHost}
+			}),
+			route(ingressNamespace, routeName),
+		},
+	}, {
+		// The LoadBalancer status is owned by Kourier, which would overwrite the hostname.
+		Name:                    "router canonical hostname is written to a status annotation",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withAdmitted(canonicalHostname)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/annotations","value":{"serving.knative.openshift.io/routerHostnames":"test.testns.default.domainname=router-default.apps.example.com"}}]`),
+		},
+	}, {
+		Name:                    "router canonical hostname recorded already",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withRouterHostnames(routeHost+"="+canonicalHostname)),
+			route(ingressNamespace, routeName, withAdmitted(canonicalHostname)),
+		},
+	}, {
+		Name:                    "router canonical hostname no longer reported",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withRouterHostnames(routeHost+"="+canonicalHostname)),
+			route(ingressNamespace, routeName),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"remove","path":"/status/annotations/serving.knative.openshift.io~1routerHostnames"}]`),
+		},
+	}, {
+		Name:                    "route not admitted yet",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Status.Ingress = []routev1.RouteIngress{{RouterCanonicalHostname: canonicalHostname}}
+			}),
+		},
 	}, {
-		Name:    "steady state",
-		Key:     key,
-		Objects: []runtime.Object{ing(ingNamespace, ingName), route(ingressNamespace, routeName)},
+		Name:                    "create route if explicitly enabled",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.DisableRouteAnnotation] = "false"
+			}),
+		},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.DisableRouteAnnotation] = "false"
+			}),
+		},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
 	}, {
-		Name:                    "create route",
+		Name:                    "invalid disable annotation",
 		SkipNamespaceValidation: true,
 		Key:                     key,
-		Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
-		WantCreates:             []runtime.Object{route(ingressNamespace, routeName)},
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.DisableRouteAnnotation] = "nope"
+			}),
+			route(ingressNamespace, routeName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InvalidAnnotation",
+				`Failed to generate routes: invalid annotation serving.knative.openshift.io/disableRoute: value "nope" must be one of "true" or "false"`),
+		},
 	}, {
-		Name:                    "remove outdated routes",
+		Name:                    "SameSite=None cookies with plain HTTP allowed",
 		SkipNamespaceValidation: true,
 		Key:                     key,
 		Objects: []runtime.Object{
-			ing(ingNamespace, ingName),
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.CookieSameSiteAnnotation] = "None"
+			}),
+			route(ingressNamespace, routeName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InvalidAnnotation",
+				`Failed to generate routes: invalid annotation router.openshift.io/cookie-same-site: value "None" requires Secure cookies, `+
+					`but insecure policy "Allow" serves plain HTTP, set serving.knative.openshift.io/insecureEdgeTerminationPolicy to "Redirect"`),
+		},
+	}, {
+		Name:                    "delete route when the rule becomes cluster-local",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withClusterLocalVisibility),
 			route(ingressNamespace, routeName),
-			route(ingressNamespace, "foo"), // This gets deleted.
-			route(ingressNamespace, "foo2", func(r *routev1.Route) {
-				r.Labels[serving.RouteLabelKey] = "foo"
-			}), // This doesn't cause the label doesn't match.
 		},
 		WantDeletes: []clientgotesting.DeleteActionImpl{{
 			ActionImpl: clientgotesting.ActionImpl{
 				Namespace: ingressNamespace,
 				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
 			},
-			Name: "foo",
+			Name: routeName,
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Deleted", "Deleted route %q, host %q is only visible inside of the cluster", routeName, routeHost),
+		},
 	}, {
-		Name:                    "copy annotations and labels",
+		Name:                    "delete route when the rule becomes cluster-local with an invalid annotation",
 		SkipNamespaceValidation: true,
 		Key:                     key,
 		Objects: []runtime.Object{
-			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
-				i.Annotations["foo.bar/baz"] = "baz"
-				i.Labels["foo.bar/baz"] = "baz"
+			ing(ingNamespace, ingName, withClusterLocalVisibility, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.DisableRouteAnnotation] = "nope"
 			}),
+			route(ingressNamespace, routeName),
 		},
-		WantCreates: []runtime.Object{
-			route(ingressNamespace, routeName, func(r *routev1.Route) {
-				r.Annotations["foo.bar/baz"] = "baz"
-				r.Labels["foo.bar/baz"] = "baz"
-			}),
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Deleted", "Deleted route %q, host %q is only visible inside of the cluster", routeName, routeHost),
+			Eventf(corev1.EventTypeWarning, "InvalidAnnotation",
+				`Failed to generate routes: invalid annotation serving.knative.openshift.io/disableRoute: value "nope" must be one of "true" or "false"`),
 		},
 	}, {
-		Name:                    "copy annotations and labels on update too",
+		Name:                    "generate host if no rule has hosts",
 		SkipNamespaceValidation: true,
 		Key:                     key,
 		Objects: []runtime.Object{
 			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
-				i.Annotations["foo.bar/baz"] = "baz"
-				i.Labels["foo.bar/baz"] = "baz"
+				i.Spec.Rules[0].Hosts = nil
 			}),
 			route(ingressNamespace, routeName),
 		},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: route(ingressNamespace, routeName, func(r *routev1.Route) {
-				r.Annotations["foo.bar/baz"] = "baz"
-				r.Labels["foo.bar/baz"] = "baz"
-			}),
+		WantCreates: []runtime.Object{route(ingressNamespace, generatedRouteName, withGeneratedHost(""))},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
 		}},
+		WantEvents: []string{routeCreated(generatedRouteName, "")},
 	}, {
-		Name:                    "fix spec",
+		Name:                    "keep generated host",
 		SkipNamespaceValidation: true,
 		Key:                     key,
 		Objects: []runtime.Object{
-			ing(ingNamespace, ingName),
-			route(ingressNamespace, routeName, func(r *routev1.Route) {
-				r.Spec.To.Kind = "foo"
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Spec.Rules[0].Hosts = nil
 			}),
+			route(ingressNamespace, generatedRouteName, withGeneratedHost(generatedRouteName+"-"+ingressNamespace+".apps.example.com")),
 		},
-		WantUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: route(ingressNamespace, routeName),
-		}},
 	}, {
-		Name:                    "create nothing",
+		Name:                    "host label too long",
 		SkipNamespaceValidation: true,
 		Key:                     key,
 		Objects: []runtime.Object{
 			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
-				i.Annotations[resources.DisableRouteAnnotation] = "true"
+				i.Spec.Rules[0].Hosts = []string{strings.Repeat("a", 64) + ".testns.default.domainname"}
 			}),
 		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "HostLabelTooLong", "Failed to generate routes: host %q: label %q is 64 characters long, must be at most 63",
+				strings.Repeat("a", 64)+".testns.default.domainname", strings.Repeat("a", 64)),
+		},
+	}, {
+		Name:                    "warn about http2 without certificate",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+				i.Annotations[resources.HTTP2Annotation] = "true"
+			}),
+		},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.HTTP2Annotation] = "true"
+			}),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, resources.HTTP2UnavailableReason,
+				"host %s is served over HTTP/1.1 only, HTTP/2 requires a certificate of its own, see %s",
+				routeHost, resources.CertManagerIssuerAnnotation),
+			routeCreated(routeName, routeHost),
+		},
+	}, {
+		Name:                    "delete routes of skipped ingress",
+		SkipNamespaceValidation: true,
+		Key:                     "skipped/" + ingName,
+		Objects: []runtime.Object{
+			ing("skipped", ingName),
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Labels[serving.RouteNamespaceLabelKey] = "skipped"
+			}),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
+		}},
 	}, {
 		Name:                    "add finalizer",
 		SkipNamespaceValidation: true,
@@ -183,21 +870,97 @@ func TestReconcile(t *testing.T) {
 		},
 	}}
 
-	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+	table.Test(t, newFactory(nil))
+}
+
+func TestReconcileRouteGenerationErrors(t *testing.T) {
+	key := ingNamespace + "/" + ingName
+
+	// Without a fallback gateway, the reconciliation is retriggered once Kourier reports the gateway.
+	noLoadBalancer := func(i *v1alpha1.Ingress) {
+		i.Status.PublicLoadBalancer = nil
+	}
+	pending := TableTest{{
+		Name:                    "gateway not reported yet",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName, noLoadBalancer)},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/conditions","value":[{"type":"Gateway","status":"False","severity":"Info","lastTransitionTime":"2020-12-01T00:00:00Z","reason":"GatewayUnavailable","message":"The gateway serving the ingress is not reported in its LoadBalancer status"}]}]`),
+		},
+	}}
+	pending.Test(t, newFactory(func(r *Reconciler) {
+		r.fallbackGateway = nil
+	}))
+
+	// Retrying doesn't help until the user adds a certificate to the Ingress.
+	terminal := TableTest{{
+		Name:                    "no certificate with strict TLS",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "MissingCertificate",
+				"Failed to generate routes: strict TLS is enabled but no certificate covers host %q", routeHost),
+		},
+	}}
+	terminal.Test(t, newFactoryWithConfig(&config.Ingress{
+		AppsDomain:             "apps.example.com",
+		FailedRouteGracePeriod: time.Minute,
+	}, func(r *Reconciler) {
+		r.strictTLS = true
+	}))
+}
+
+// routeCreated returns the event of the creation of the given route.
+func routeCreated(name, host string) string {
+	return Eventf(corev1.EventTypeNormal, "Created", "Created route %q for host %q", name, host)
+}
+
+// newFactory returns a factory of Reconcilers as they're set up by the controller. The given
+// function can customize the Reconciler.
+func newFactory(customize func(*Reconciler)) Factory {
+	return newFactoryWithConfig(&config.Ingress{
+		SkipList:               []string{"skipped/*"},
+		FailedRouteGracePeriod: time.Minute,
+	}, customize)
+}
+
+func newFactoryWithConfig(cfg *config.Ingress, customize func(*Reconciler)) Factory {
+	return MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
 		r := &Reconciler{
-			routeClient: fakerouteclient.Get(ctx).RouteV1(),
-			routeLister: listers.GetRouteLister(),
+			routeClient:   fakerouteclient.Get(ctx).RouteV1(),
+			routeLister:   listers.GetRouteLister(),
+			ingressClient: networkingclient.Get(ctx).NetworkingV1alpha1(),
+			serviceLister: listers.GetServiceLister(),
+			fallbackGateway: &types.NamespacedName{
+				Namespace: ingressNamespace,
+				Name:      svcName,
+			},
+			clock: clock.NewFakeClock(now),
+		}
+		if customize != nil {
+			customize(r)
 		}
 
 		ingr := ingressreconciler.NewReconciler(ctx, logging.FromContext(ctx), networkingclient.Get(ctx),
-			listers.GetIngressLister(), controller.GetEventRecorder(ctx), r, kourierIngressClassName,
+			listers.GetIngressLister(), &UserAwareEventRecorder{EventRecorder: controller.GetEventRecorder(ctx)}, r, kourierIngressClassName,
 			controller.Options{
 				SkipStatusUpdates: true,
 				FinalizerName:     "ocp-ingress",
+				ConfigStore:       &testConfigStore{config: &config.Config{Ingress: cfg}},
 			})
 
 		return ingr
-	}))
+	})
+}
+
+type testConfigStore struct {
+	config *config.Config
+}
+
+func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, t.config)
 }
 
 type ingressOption func(*v1alpha1.Ingress)
@@ -254,7 +1017,7 @@ func route(ns, name string, opts ...routeOption) *routev1.Route {
 			},
 		},
 		Spec: routev1.RouteSpec{
-			Host: domainName,
+			Host: routeHost,
 			Port: &routev1.RoutePort{
 				TargetPort: intstr.FromString("http2"),
 			},
@@ -275,3 +1038,242 @@ func route(ns, name string, opts ...routeOption) *routev1.Route {
 	}
 	return r
 }
+
+// withPortSelection records how the port of the gateway Service was selected, which is only done
+// if the Service is known.
+func withPortSelection(selection string) routeOption {
+	return func(r *routev1.Route) {
+		r.Annotations[resources.GatewayPortSelectionAnnotation] = selection
+	}
+}
+
+func withClusterLocalVisibility(i *v1alpha1.Ingress) {
+	i.Spec.Rules[0].Visibility = v1alpha1.IngressVisibilityClusterLocal
+}
+
+func withGeneratedHost(host string) routeOption {
+	return func(r *routev1.Route) {
+		r.Annotations[resources.HostGeneratedAnnotation] = "true"
+		r.Spec.Host = host
+	}
+}
+
+func withAdmitted(canonicalHostname string) routeOption {
+	return func(r *routev1.Route) {
+		r.Status.Ingress = []routev1.RouteIngress{{
+			Host:                    r.Spec.Host,
+			RouterCanonicalHostname: canonicalHostname,
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:   routev1.RouteAdmitted,
+				Status: corev1.ConditionTrue,
+			}},
+		}}
+	}
+}
+
+func withRejected(since time.Time) routeOption {
+	return func(r *routev1.Route) {
+		r.Status.Ingress = []routev1.RouteIngress{{
+			Host: r.Spec.Host,
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:               routev1.RouteAdmitted,
+				Status:             corev1.ConditionFalse,
+				Reason:             "ServiceNotFound",
+				LastTransitionTime: &metav1.Time{Time: since},
+			}},
+		}}
+	}
+}
+
+func TestReconcileFailedRouteRecheck(t *testing.T) {
+	var enqueuedAfter time.Duration
+	table := TableTest{{
+		Name:                    "recheck route failed within the grace period",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withRejected(now.Add(-20*time.Second))),
+		},
+	}}
+	table.Test(t, newFactory(func(r *Reconciler) {
+		r.enqueueAfter = func(_ interface{}, after time.Duration) {
+			enqueuedAfter = after
+		}
+	}))
+
+	if want := 40 * time.Second; enqueuedAfter != want {
+		t.Errorf("Enqueued after %v, want: %v", enqueuedAfter, want)
+	}
+}
+
+// fakeSecretClient serves the secrets of the given names. Calls to methods it doesn't implement
+// panic on the embedded nil interface.
+type fakeSecretClient struct {
+	corev1client.SecretInterface
+
+	names sets.String
+	// err is returned for all secrets if set.
+	err error
+}
+
+func (f *fakeSecretClient) Secrets(string) corev1client.SecretInterface {
+	return f
+}
+
+func (f *fakeSecretClient) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.Secret, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if !f.names.Has(name) {
+		return nil, apierrs.NewNotFound(corev1.Resource("secrets"), name)
+	}
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+}
+
+func TestReconcilePendingCertificate(t *testing.T) {
+	withGatewayCertificate := func(secretName string) ingressOption {
+		return func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.TerminationAnnotation] = string(routev1.TLSTerminationPassthrough)
+			i.Spec.TLS = []v1alpha1.IngressTLS{{
+				Hosts:           []string{domainName},
+				SecretNamespace: ingNamespace,
+				SecretName:      secretName,
+			}}
+		}
+	}
+	passthrough := func(r *routev1.Route) {
+		r.Annotations[resources.TerminationAnnotation] = string(routev1.TLSTerminationPassthrough)
+		r.Spec.Port.TargetPort = intstr.FromString(resources.KourierHTTPSPort)
+		r.Spec.TLS = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationPassthrough,
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+	}
+
+	table := TableTest{{
+		Name:                    "secret present",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName, withGatewayCertificate("cert"))},
+		WantCreates:             []runtime.Object{route(ingressNamespace, routeName, passthrough)},
+		WantEvents:              []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "secret expected but missing",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName, withGatewayCertificate("issuing"))},
+		// The reconciliation fails to be retried with backoff until the secret exists.
+		WantErr: true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "CertificatePending",
+				"Waiting for certificate: secret %s/issuing of the certificate of host %q doesn't exist yet", ingNamespace, routeHost),
+		},
+	}, {
+		Name:                    "no certificate configured",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
+		WantCreates:             []runtime.Object{route(ingressNamespace, routeName)},
+		WantEvents:              []string{routeCreated(routeName, routeHost)},
+	}}
+	table.Test(t, newFactory(func(r *Reconciler) {
+		r.secretClient = &fakeSecretClient{names: sets.NewString("cert")}
+	}))
+
+	// Failing to look up the secret, for example for lack of permissions, doesn't count as the
+	// secret being present. The reconciliation fails to be retried with backoff.
+	forbidden := TableTest{{
+		Name:                    "secret lookup forbidden",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName, withGatewayCertificate("cert"))},
+		WantErr:                 true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError",
+				"failed to look up secret %s/cert of the certificate of host %q: failed to get secret %s/cert: "+
+					"secrets \"cert\" is forbidden: no permission", ingNamespace, routeHost, ingNamespace),
+		},
+	}}
+	forbidden.Test(t, newFactory(func(r *Reconciler) {
+		r.secretClient = &fakeSecretClient{
+			err: apierrs.NewForbidden(corev1.Resource("secrets"), "cert", errors.New("no permission")),
+		}
+	}))
+}
+
+// fakeConfigMapClient serves ConfigMaps of the given data by name. Calls to methods it doesn't
+// implement panic on the embedded nil interface.
+type fakeConfigMapClient struct {
+	corev1client.ConfigMapInterface
+
+	data map[string]map[string]string
+}
+
+func (f *fakeConfigMapClient) ConfigMaps(string) corev1client.ConfigMapInterface {
+	return f
+}
+
+func (f *fakeConfigMapClient) Get(_ context.Context, name string, _ metav1.GetOptions) (*corev1.ConfigMap, error) {
+	data, ok := f.data[name]
+	if !ok {
+		return nil, apierrs.NewNotFound(corev1.Resource("configmaps"), name)
+	}
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}, Data: data}, nil
+}
+
+func TestReconcileIPWhitelist(t *testing.T) {
+	withWhitelistRef := func(ref string) ingressOption {
+		return func(i *v1alpha1.Ingress) {
+			i.Annotations[resources.IPWhitelistConfigMapAnnotation] = ref
+		}
+	}
+
+	table := TableTest{{
+		Name:                    "configmap present",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName, withWhitelistRef("allowlists/office"))},
+		WantCreates: []runtime.Object{
+			route(ingressNamespace, routeName, func(r *routev1.Route) {
+				r.Annotations[resources.IPWhitelistConfigMapAnnotation] = "allowlists/office"
+				r.Annotations[resources.IPWhitelistRouteAnnotation] = "10.0.0.0/8 192.168.1.1"
+			}),
+		},
+		WantEvents: []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "configmap missing",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withWhitelistRef("unknown/office")),
+			route(ingressNamespace, routeName),
+		},
+		// The reconciliation fails to be retried with backoff until the ConfigMap exists.
+		WantErr: true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "IPWhitelistNotFound",
+				`Failed to generate routes: IP whitelist "office" of configmap %s/unknown doesn't exist`, ingNamespace),
+		},
+	}, {
+		Name:                    "malformed CIDR",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withWhitelistRef("allowlists/malformed")),
+			route(ingressNamespace, routeName),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InvalidIPWhitelist",
+				`Failed to generate routes: IP whitelist "malformed" of configmap %s/allowlists contains "10.0.0.0/33", which is neither an IP nor a CIDR`, ingNamespace),
+		},
+	}}
+	table.Test(t, newFactory(func(r *Reconciler) {
+		r.configMapClient = &fakeConfigMapClient{data: map[string]map[string]string{
+			"allowlists": {
+				"office":    "10.0.0.0/8\n192.168.1.1",
+				"malformed": "10.0.0.0/33",
+			},
+		}}
+	}))
+}
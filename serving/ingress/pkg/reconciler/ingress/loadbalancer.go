@@ -0,0 +1,220 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/reconciler"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// LoadBalancerAddressAnnotation is the status annotation of Ingresses exposed through a
+// LoadBalancer Service, holding the external IP and hostname assigned to the Service separated by
+// commas. It's removed when switching back to Routes.
+const LoadBalancerAddressAnnotation = "serving.knative.openshift.io/loadBalancerAddress"
+
+// reconcileLoadBalancer exposes the given Ingress through a LoadBalancer Service in front of its
+// Kourier gateway instead of Routes. Routes created before switching modes are deleted.
+func (r *Reconciler) reconcileLoadBalancer(ctx context.Context, ing *v1alpha1.Ingress, existing []*routev1.Route) error {
+	logger := logging.FromContext(ctx)
+	r.loadBalancerSweep.reset()
+
+	for _, route := range existing {
+		if err := r.deleteRoute(ctx, route); err != nil {
+			return err
+		}
+	}
+
+	gateway, err := resources.Gateway(ing, r.routeOptions(ctx)...)
+	if errors.Is(err, resources.ErrNoValidLoadbalancerDomain) {
+		logger.Warnf("Failed to determine the gateway of ingress %v", err)
+		// Returning nil aborts the reconciliation. It will be retriggered once the status of the ingress changes.
+		return nil
+	} else if errors.Is(err, resources.ErrInvalidAnnotation) {
+		return reconciler.NewEvent(corev1.EventTypeWarning, "InvalidAnnotation", "Failed to determine the gateway: %v", err)
+	} else if err != nil {
+		return err
+	}
+
+	gatewayService, err := r.serviceLister.Services(gateway.Namespace).Get(gateway.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get gateway service %s: %w", gateway, err)
+	}
+
+	svc, err := r.reconcileService(ctx, resources.MakeLoadBalancerService(gatewayService))
+	if err != nil {
+		return err
+	}
+
+	// The address is reported in a status annotation, as the LoadBalancer status of the Ingress
+	// is owned by Kourier.
+	ip, hostname := resources.LoadBalancerAddress(svc)
+	recordLoadBalancerAddress(ctx, svc, ip != "" || hostname != "")
+	before := ing.Status.DeepCopy()
+	if !markLoadBalancerAddress(ing, ip, hostname) {
+		return nil
+	}
+	return r.patchStatus(ctx, ing, before)
+}
+
+// markLoadBalancerAddress records the given external IP and hostname of the LoadBalancer Service
+// in the status of the Ingress. It returns true if the status changed.
+func markLoadBalancerAddress(ing *v1alpha1.Ingress, ip, hostname string) bool {
+	var addresses []string
+	for _, address := range []string{ip, hostname} {
+		if address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+
+	value := strings.Join(addresses, ",")
+	if value == ing.Status.Annotations[LoadBalancerAddressAnnotation] {
+		return false
+	}
+	if value == "" {
+		delete(ing.Status.Annotations, LoadBalancerAddressAnnotation)
+		return true
+	}
+	if ing.Status.Annotations == nil {
+		ing.Status.Annotations = make(map[string]string, 1)
+	}
+	ing.Status.Annotations[LoadBalancerAddressAnnotation] = value
+	return true
+}
+
+// reconcileService creates or updates the given Service and returns its current state.
+func (r *Reconciler) reconcileService(ctx context.Context, desired *corev1.Service) (*corev1.Service, error) {
+	logger := logging.FromContext(ctx)
+
+	svc, err := r.serviceLister.Services(desired.Namespace).Get(desired.Name)
+	if apierrs.IsNotFound(err) {
+		logger.Infof("Creating service %s/%s", desired.Namespace, desired.Name)
+		created, err := r.serviceClient.Services(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create service: %w", err)
+		}
+		return created, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	if svc.Spec.Type == desired.Spec.Type &&
+		svc.Spec.ExternalName == desired.Spec.ExternalName &&
+		equality.Semantic.DeepEqual(svc.Spec.Selector, desired.Spec.Selector) &&
+		equality.Semantic.DeepEqual(svc.Labels, desired.Labels) &&
+		portsEqual(svc.Spec.Ports, desired.Spec.Ports) {
+		return svc, nil
+	}
+
+	// Don't modify the informers copy. The cluster IP and node ports are allocated by the API
+	// server, so only the fields we own are overwritten.
+	existing := svc.DeepCopy()
+	existing.Labels = desired.Labels
+	existing.Spec.Type = desired.Spec.Type
+	existing.Spec.ExternalName = desired.Spec.ExternalName
+	existing.Spec.Selector = desired.Spec.Selector
+	existing.Spec.Ports = desired.Spec.Ports
+	updated, err := r.serviceClient.Services(existing.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+	return updated, nil
+}
+
+// loadBalancerSweep makes sure the LoadBalancer Services are deleted once after switching to
+// the Route exposure mode, rather than on every reconciliation of every Ingress.
+type loadBalancerSweep struct {
+	mu sync.Mutex
+	// done is true if no LoadBalancer Services were created since they were last deleted.
+	done bool
+}
+
+// reset marks LoadBalancer Services as to be deleted once the exposure mode changes.
+func (s *loadBalancerSweep) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = false
+}
+
+// sweepLoadBalancerServices deletes the LoadBalancer Services, unless that already happened since
+// the LoadBalancer exposure mode was last used. Every controller start sweeps once, as the mode
+// might have changed while the controller was down.
+func (r *Reconciler) sweepLoadBalancerServices(ctx context.Context) error {
+	r.loadBalancerSweep.mu.Lock()
+	defer r.loadBalancerSweep.mu.Unlock()
+	if r.loadBalancerSweep.done {
+		return nil
+	}
+	if err := r.deleteLoadBalancerServices(ctx); err != nil {
+		return err
+	}
+	r.loadBalancerSweep.done = true
+	return nil
+}
+
+// loadBalancerIngressFilter returns a filter passing the Ingresses exposed through the given
+// LoadBalancer Service. Outside of the LoadBalancer exposure mode, Ingresses don't depend on
+// LoadBalancer Services and none pass.
+func (r *Reconciler) loadBalancerIngressFilter(ctx context.Context, svc *corev1.Service) func(interface{}) bool {
+	if config.FromContext(ctx).Ingress.ExposureMode != config.ExposureLoadBalancer {
+		return func(interface{}) bool { return false }
+	}
+	gateway := resources.LoadBalancerGateway(svc)
+	opts := r.routeOptions(ctx)
+	return func(obj interface{}) bool {
+		ing, ok := obj.(*v1alpha1.Ingress)
+		if !ok {
+			return false
+		}
+		got, err := resources.Gateway(ing, opts...)
+		return err == nil && got == gateway
+	}
+}
+
+// deleteLoadBalancerServices deletes all LoadBalancer Services created in the LoadBalancer
+// exposure mode.
+func (r *Reconciler) deleteLoadBalancerServices(ctx context.Context) error {
+	svcs, err := r.serviceLister.List(labels.SelectorFromSet(map[string]string{
+		resources.ExposureLabelKey: resources.ExposureLoadBalancerValue,
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to list LoadBalancer services: %w", err)
+	}
+
+	for _, svc := range svcs {
+		logging.FromContext(ctx).Infof("Deleting LoadBalancer service %s/%s", svc.Namespace, svc.Name)
+		err := r.serviceClient.Services(svc.Namespace).Delete(ctx, svc.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			return fmt.Errorf("failed to delete LoadBalancer service: %w", err)
+		}
+	}
+	return nil
+}
+
+// portsEqual compares the given ports, ignoring the node ports allocated by the API server.
+func portsEqual(existing, desired []corev1.ServicePort) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for i := range existing {
+		port := existing[i]
+		port.NodePort = 0
+		if !equality.Semantic.DeepEqual(port, desired[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,297 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	clientgotesting "k8s.io/client-go/testing"
+
+	serviceinformer "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/injection/kube/informers/core/v1/service"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	. "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+// fakeServiceClient records the changes made to Services. Calls to methods it doesn't
+// implement panic on the embedded nil interface.
+type fakeServiceClient struct {
+	corev1client.ServiceInterface
+
+	created []string
+	updated []string
+	deleted []string
+}
+
+func (f *fakeServiceClient) Services(string) corev1client.ServiceInterface {
+	return f
+}
+
+func (f *fakeServiceClient) Create(_ context.Context, svc *corev1.Service, _ metav1.CreateOptions) (*corev1.Service, error) {
+	f.created = append(f.created, svc.Name)
+	return svc, nil
+}
+
+func (f *fakeServiceClient) Update(_ context.Context, svc *corev1.Service, _ metav1.UpdateOptions) (*corev1.Service, error) {
+	f.updated = append(f.updated, svc.Name)
+	return svc, nil
+}
+
+func (f *fakeServiceClient) Delete(_ context.Context, name string, _ metav1.DeleteOptions) error {
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func TestReconcileLoadBalancer(t *testing.T) {
+	const lbName = svcName + "-lb"
+
+	tests := []struct {
+		name        string
+		mode        config.ExposureMode
+		objects     []runtime.Object
+		wantDeletes []clientgotesting.DeleteActionImpl
+		wantPatches []clientgotesting.PatchActionImpl
+		wantCreated []string
+		wantUpdated []string
+		wantDeleted []string
+	}{{
+		name: "create load balancer and delete routes",
+		mode: config.ExposureLoadBalancer,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName),
+			gatewayService(),
+		},
+		wantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: ingressNamespace,
+				Verb:      "delete",
+				Resource:  routev1.SchemeGroupVersion.WithResource("routes"),
+			},
+			Name: routeName,
+		}},
+		wantCreated: []string{lbName},
+	}, {
+		name: "load balancer address is written to a status annotation",
+		mode: config.ExposureLoadBalancer,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			gatewayService(),
+			loadBalancerService(withLoadBalancerIngress(corev1.LoadBalancerIngress{IP: "192.0.2.1", Hostname: "lb.example.com"})),
+		},
+		wantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/annotations","value":{"serving.knative.openshift.io/loadBalancerAddress":"192.0.2.1,lb.example.com"}}]`),
+		},
+	}, {
+		name: "load balancer address recorded already",
+		mode: config.ExposureLoadBalancer,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName, withStatusAnnotation(LoadBalancerAddressAnnotation, "192.0.2.1")),
+			gatewayService(),
+			loadBalancerService(withLoadBalancerIngress(corev1.LoadBalancerIngress{IP: "192.0.2.1"})),
+		},
+	}, {
+		name: "load balancer address is removed in route mode",
+		mode: config.ExposureRoute,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName, withStatusAnnotation(LoadBalancerAddressAnnotation, "192.0.2.1")),
+			route(ingressNamespace, routeName, withPortSelection(resources.PortSelectedByName)),
+			gatewayService(),
+		},
+		wantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"remove","path":"/status/annotations/serving.knative.openshift.io~1loadBalancerAddress"}]`),
+		},
+	}, {
+		name: "update outdated load balancer",
+		mode: config.ExposureLoadBalancer,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			gatewayService(),
+			loadBalancerService(func(svc *corev1.Service) {
+				svc.Spec.Selector = map[string]string{"app": "outdated"}
+			}),
+		},
+		wantUpdated: []string{lbName},
+	}, {
+		name: "ignore node ports of load balancer",
+		mode: config.ExposureLoadBalancer,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			gatewayService(),
+			loadBalancerService(func(svc *corev1.Service) {
+				svc.Spec.Ports[0].NodePort = 31080
+			}),
+		},
+	}, {
+		name: "delete load balancer in route mode",
+		mode: config.ExposureRoute,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withPortSelection(resources.PortSelectedByName)),
+			gatewayService(),
+			loadBalancerService(),
+		},
+		wantDeleted: []string{lbName},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			services := &fakeServiceClient{}
+			table := TableTest{{
+				Name:                    test.name,
+				SkipNamespaceValidation: true,
+				Key:                     ingNamespace + "/" + ingName,
+				Objects:                 test.objects,
+				WantDeletes:             test.wantDeletes,
+				WantPatches:             test.wantPatches,
+			}}
+			table.Test(t, newFactoryWithConfig(&config.Ingress{ExposureMode: test.mode}, func(r *Reconciler) {
+				r.serviceClient = services
+			}))
+
+			if !cmp.Equal(services.created, test.wantCreated) {
+				t.Errorf("Created services (-want, +got) = %s", cmp.Diff(test.wantCreated, services.created))
+			}
+			if !cmp.Equal(services.updated, test.wantUpdated) {
+				t.Errorf("Updated services (-want, +got) = %s", cmp.Diff(test.wantUpdated, services.updated))
+			}
+			if !cmp.Equal(services.deleted, test.wantDeleted) {
+				t.Errorf("Deleted services (-want, +got) = %s", cmp.Diff(test.wantDeleted, services.deleted))
+			}
+		})
+	}
+}
+
+func TestSweepLoadBalancerServices(t *testing.T) {
+	const lbName = svcName + "-lb"
+	listers := NewListers([]runtime.Object{gatewayService(), loadBalancerService()})
+	services := &fakeServiceClient{}
+	r := &Reconciler{serviceLister: listers.GetServiceLister(), serviceClient: services}
+	ctx := context.Background()
+
+	// The fake client doesn't delete from the lister, so every sweep would find the Service.
+	for i := 0; i < 2; i++ {
+		if err := r.sweepLoadBalancerServices(ctx); err != nil {
+			t.Fatal("sweepLoadBalancerServices() =", err)
+		}
+	}
+	if want := []string{lbName}; !cmp.Equal(services.deleted, want) {
+		t.Errorf("Deleted services (-want, +got) = %s", cmp.Diff(want, services.deleted))
+	}
+
+	// Using the LoadBalancer exposure mode again requires another sweep.
+	r.loadBalancerSweep.reset()
+	if err := r.sweepLoadBalancerServices(ctx); err != nil {
+		t.Fatal("sweepLoadBalancerServices() =", err)
+	}
+	if want := []string{lbName, lbName}; !cmp.Equal(services.deleted, want) {
+		t.Errorf("Deleted services (-want, +got) = %s", cmp.Diff(want, services.deleted))
+	}
+}
+
+func TestLoadBalancerIngressFilter(t *testing.T) {
+	r := &Reconciler{}
+	lbMode := config.ToContext(context.Background(), &config.Config{Ingress: &config.Ingress{ExposureMode: config.ExposureLoadBalancer}})
+	routeMode := config.ToContext(context.Background(), &config.Config{Ingress: &config.Ingress{ExposureMode: config.ExposureRoute}})
+	otherGateway := gatewayService(func(svc *corev1.Service) {
+		svc.Name = "other"
+	})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		svc  *corev1.Service
+		obj  interface{}
+		want bool
+	}{{
+		name: "ingress of the exposed gateway",
+		ctx:  lbMode,
+		svc:  loadBalancerService(),
+		obj:  ing(ingNamespace, ingName),
+		want: true,
+	}, {
+		name: "ingress of another gateway",
+		ctx:  lbMode,
+		svc:  resources.MakeLoadBalancerService(otherGateway),
+		obj:  ing(ingNamespace, ingName),
+	}, {
+		name: "route exposure mode",
+		ctx:  routeMode,
+		svc:  loadBalancerService(),
+		obj:  ing(ingNamespace, ingName),
+	}, {
+		name: "not an ingress",
+		ctx:  lbMode,
+		svc:  loadBalancerService(),
+		obj:  gatewayService(),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := r.loadBalancerIngressFilter(test.ctx, test.svc)(test.obj); got != test.want {
+				t.Errorf("loadBalancerIngressFilter() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestServiceInformerSelector(t *testing.T) {
+	selector, err := labels.Parse(serviceinformer.LabelSelector)
+	if err != nil {
+		t.Fatal("Failed to parse selector:", err)
+	}
+	// The Services the controller creates must be visible to it.
+	for _, svc := range []*corev1.Service{
+		resources.MakeLoadBalancerService(gatewayService()),
+		resources.MakeExternalNameService(ingressNamespace, gatewayService()),
+	} {
+		if !selector.Matches(labels.Set(svc.Labels)) {
+			t.Errorf("Service %s is not selected by %q", svc.Name, serviceinformer.LabelSelector)
+		}
+	}
+}
+
+func gatewayService(opts ...func(*corev1.Service)) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svcName,
+			Namespace: ingressNamespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{"app": "3scale-kourier-gateway"},
+			Ports: []corev1.ServicePort{{
+				Name:       "http2",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+func loadBalancerService(opts ...func(*corev1.Service)) *corev1.Service {
+	svc := resources.MakeLoadBalancerService(gatewayService())
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+func withLoadBalancerIngress(ingress ...corev1.LoadBalancerIngress) func(*corev1.Service) {
+	return func(svc *corev1.Service) {
+		svc.Status.LoadBalancer.Ingress = ingress
+	}
+}
@@ -0,0 +1,30 @@
+package ingress
+
+import (
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+// InMaintenanceAnnotation is the status annotation of Ingresses whose Routes are out of rotation
+// because of the resources.MaintenanceAnnotation. It's removed once they're back.
+const InMaintenanceAnnotation = "serving.knative.openshift.io/inMaintenance"
+
+// markMaintenance records in the status of the given Ingress whether its Routes are in
+// maintenance. It returns true if the status changed.
+func markMaintenance(ing *v1alpha1.Ingress) bool {
+	_, marked := ing.Status.Annotations[InMaintenanceAnnotation]
+	inMaintenance := resources.InMaintenance(ing)
+	if inMaintenance == marked {
+		return false
+	}
+	if !inMaintenance {
+		delete(ing.Status.Annotations, InMaintenanceAnnotation)
+		return true
+	}
+	if ing.Status.Annotations == nil {
+		ing.Status.Annotations = make(map[string]string, 1)
+	}
+	ing.Status.Annotations[InMaintenanceAnnotation] = "true"
+	return true
+}
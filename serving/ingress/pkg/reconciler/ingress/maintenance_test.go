@@ -0,0 +1,70 @@
+package ingress
+
+import (
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/ptr"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func withMaintenance(i *v1alpha1.Ingress) {
+	i.Annotations[resources.MaintenanceAnnotation] = "true"
+}
+
+func withInMaintenance(i *v1alpha1.Ingress) {
+	i.Status.Annotations = map[string]string{InMaintenanceAnnotation: "true"}
+}
+
+func TestReconcileMaintenance(t *testing.T) {
+	key := ingNamespace + "/" + ingName
+	inMaintenance := func(r *routev1.Route) {
+		r.Annotations[resources.MaintenanceAnnotation] = "true"
+		r.Spec.To.Weight = ptr.Int32(0)
+	}
+
+	table := TableTest{{
+		Name:                    "take route out of rotation",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withMaintenance),
+			route(ingressNamespace, routeName),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName, inMaintenance),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/annotations","value":{"serving.knative.openshift.io/inMaintenance":"true"}}]`),
+		},
+	}, {
+		Name:                    "in maintenance",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withMaintenance, withInMaintenance),
+			route(ingressNamespace, routeName, inMaintenance),
+		},
+	}, {
+		Name:                    "restore route after maintenance",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withInMaintenance),
+			route(ingressNamespace, routeName, inMaintenance),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"remove","path":"/status/annotations/serving.knative.openshift.io~1inMaintenance"}]`),
+		},
+	}}
+
+	table.Test(t, newFactory(nil))
+}
@@ -0,0 +1,178 @@
+package ingress
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/metrics"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+var (
+	makeRoutesLatencyM = stats.Float64(
+		"make_routes_latencies",
+		"The time it takes to generate the Routes of an Ingress",
+		stats.UnitMilliseconds)
+
+	loadBalancerReadyM = stats.Int64(
+		"loadbalancer_ready",
+		"Whether the LoadBalancer Service of a gateway has been assigned an external address",
+		stats.UnitDimensionless)
+
+	routesM = stats.Int64(
+		"routes",
+		"The number of Routes generated for Ingresses, by whether routers admitted them",
+		stats.UnitDimensionless)
+
+	orphanRoutesM = stats.Int64(
+		"orphan_routes",
+		"The number of generated Routes whose Ingress doesn't exist anymore",
+		stats.UnitDimensionless)
+
+	namespaceRoutesM = stats.Int64(
+		"namespace_routes",
+		"The number of Routes generated for the Ingresses of a namespace, for the namespaces with the most Routes",
+		stats.UnitDimensionless)
+
+	skippedRoutesM = stats.Int64(
+		"skipped_routes",
+		"The number of hosts no Route was generated for, as they are not served outside of the cluster",
+		stats.UnitDimensionless)
+
+	createdRoutesM = stats.Int64(
+		"created_routes",
+		"The number of Routes created for Ingresses",
+		stats.UnitDimensionless)
+
+	routeAdmissionLatencyM = stats.Float64(
+		"route_admission_latencies",
+		"The time it takes routers to admit a Route after it has been created",
+		stats.UnitSeconds)
+
+	gatewayUnavailableIngressesM = stats.Int64(
+		"gateway_unavailable_ingresses",
+		"The number of Ingresses no Routes can be generated for, as their gateway is unknown",
+		stats.UnitDimensionless)
+
+	gatewayUnavailableAgeM = stats.Float64(
+		"gateway_unavailable_age",
+		"The time the Ingress whose gateway is unknown the longest has been failing for",
+		stats.UnitSeconds)
+
+	hostCountKey = tag.MustNewKey("host_count")
+	namespaceKey = tag.MustNewKey("namespace")
+	serviceKey   = tag.MustNewKey("service")
+	stateKey     = tag.MustNewKey("state")
+)
+
+func init() {
+	if err := view.Register(&view.View{
+		Description: makeRoutesLatencyM.Description(),
+		Measure:     makeRoutesLatencyM,
+		Aggregation: view.Distribution(metrics.Buckets125(0.1, 10000)...),
+		TagKeys:     []tag.Key{hostCountKey},
+	}, &view.View{
+		Description: loadBalancerReadyM.Description(),
+		Measure:     loadBalancerReadyM,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{serviceKey},
+	}, &view.View{
+		Description: routesM.Description(),
+		Measure:     routesM,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{stateKey},
+	}, &view.View{
+		Description: orphanRoutesM.Description(),
+		Measure:     orphanRoutesM,
+		Aggregation: view.LastValue(),
+	}, &view.View{
+		Description: namespaceRoutesM.Description(),
+		Measure:     namespaceRoutesM,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{namespaceKey},
+	}, &view.View{
+		Description: skippedRoutesM.Description(),
+		Measure:     skippedRoutesM,
+		Aggregation: view.Count(),
+	}, &view.View{
+		Description: createdRoutesM.Description(),
+		Measure:     createdRoutesM,
+		Aggregation: view.Count(),
+	}, &view.View{
+		Description: gatewayUnavailableIngressesM.Description(),
+		Measure:     gatewayUnavailableIngressesM,
+		Aggregation: view.LastValue(),
+	}, &view.View{
+		Description: gatewayUnavailableAgeM.Description(),
+		Measure:     gatewayUnavailableAgeM,
+		Aggregation: view.LastValue(),
+	}, &view.View{
+		Description: routeAdmissionLatencyM.Description(),
+		Measure:     routeAdmissionLatencyM,
+		Aggregation: view.Distribution(1, 2, 5, 10, 30, 60, 120, 300, 600),
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// makeRoutes generates the Routes of the given Ingress and records how long that took,
+// labeled by the number of hosts of the Ingress.
+func makeRoutes(ctx context.Context, ing *v1alpha1.Ingress, opts ...resources.Option) ([]*routev1.Route, error) {
+	start := time.Now()
+	routes, err := resources.MakeRoutes(ing, opts...)
+	elapsed := time.Since(start)
+
+	if ctx, tagErr := tag.New(ctx, tag.Insert(hostCountKey, hostCountBucket(ing))); tagErr == nil {
+		metrics.Record(ctx, makeRoutesLatencyM.M(float64(elapsed)/float64(time.Millisecond)))
+	}
+	return routes, err
+}
+
+// recordLoadBalancerAddress records whether the given LoadBalancer Service has been assigned
+// an external address.
+func recordLoadBalancerAddress(ctx context.Context, svc *corev1.Service, ready bool) {
+	value := int64(0)
+	if ready {
+		value = 1
+	}
+	if ctx, err := tag.New(ctx, tag.Insert(serviceKey, svc.Namespace+"/"+svc.Name)); err == nil {
+		metrics.Record(ctx, loadBalancerReadyM.M(value))
+	}
+}
+
+// recordSkippedRoute records that no Route was generated for a host of an Ingress.
+func recordSkippedRoute(ctx context.Context) {
+	metrics.Record(ctx, skippedRoutesM.M(1))
+}
+
+// recordCreatedRoute records that a Route was created for an Ingress.
+func recordCreatedRoute(ctx context.Context) {
+	metrics.Record(ctx, createdRoutesM.M(1))
+}
+
+// hostCountBucket returns the bucket of the number of hosts of the given Ingress. Buckets keep
+// the cardinality of the metric low.
+func hostCountBucket(ing *v1alpha1.Ingress) string {
+	count := 0
+	for _, rule := range ing.Spec.Rules {
+		count += len(rule.Hosts)
+	}
+
+	switch {
+	case count == 0:
+		return "0"
+	case count < 10:
+		return "1-9"
+	case count < 100:
+		return "10-99"
+	default:
+		return "100+"
+	}
+}
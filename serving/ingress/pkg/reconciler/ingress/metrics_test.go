@@ -0,0 +1,115 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/stats/view"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/metrics"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func TestMakeRoutesLatency(t *testing.T) {
+	metrics.InitForTesting()
+
+	if _, err := makeRoutes(context.Background(), ing(ingNamespace, ingName)); err != nil {
+		t.Fatalf("makeRoutes() = %v", err)
+	}
+
+	rows, err := view.RetrieveData(makeRoutesLatencyM.Name())
+	if err != nil {
+		t.Fatalf("RetrieveData() = %v", err)
+	}
+	var count int64
+	for _, row := range rows {
+		if got := row.Tags[0].Value; got != "1-9" {
+			t.Errorf("host_count = %q, want: %q", got, "1-9")
+		}
+		count += row.Data.(*view.DistributionData).Count
+	}
+	if count < 1 {
+		t.Errorf("got %d samples, want at least 1", count)
+	}
+}
+
+func TestRecordSkippedRoute(t *testing.T) {
+	metrics.InitForTesting()
+
+	ing := ing(ingNamespace, ingName, func(i *v1alpha1.Ingress) {
+		i.Spec.Rules[0].Hosts = append(i.Spec.Rules[0].Hosts, "test.testns.svc.cluster.local")
+	})
+	r := &Reconciler{}
+	if _, err := makeRoutes(context.Background(), ing, r.routeOptions(context.Background())...); err != nil {
+		t.Fatalf("makeRoutes() = %v", err)
+	}
+
+	rows, err := view.RetrieveData(skippedRoutesM.Name())
+	if err != nil {
+		t.Fatalf("RetrieveData() = %v", err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.CountData).Value != 1 {
+		t.Errorf("skipped_routes = %v, want: 1", rows)
+	}
+}
+
+func TestRecordCreatedRoute(t *testing.T) {
+	metrics.InitForTesting()
+
+	// Other tests create Routes as well, so only the Routes created here are counted.
+	createdRoutes := func() int64 {
+		rows, err := view.RetrieveData(createdRoutesM.Name())
+		if err != nil {
+			t.Fatalf("RetrieveData() = %v", err)
+		}
+		if len(rows) == 0 {
+			return 0
+		}
+		return rows[0].Data.(*view.CountData).Value
+	}
+	before := createdRoutes()
+
+	table := TableTest{{
+		Name:                    "create route",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
+		WantCreates:             []runtime.Object{route(ingressNamespace, routeName)},
+		WantEvents:              []string{routeCreated(routeName, routeHost)},
+	}}
+	table.Test(t, newFactory(nil))
+
+	if got := createdRoutes() - before; got != 1 {
+		t.Errorf("created_routes increased by %d, want: 1", got)
+	}
+}
+
+func TestHostCountBucket(t *testing.T) {
+	tests := []struct {
+		hosts int
+		want  string
+	}{{
+		hosts: 0,
+		want:  "0",
+	}, {
+		hosts: 9,
+		want:  "1-9",
+	}, {
+		hosts: 10,
+		want:  "10-99",
+	}, {
+		hosts: 100,
+		want:  "100+",
+	}}
+
+	for _, test := range tests {
+		ing := &v1alpha1.Ingress{Spec: v1alpha1.IngressSpec{
+			Rules: []v1alpha1.IngressRule{{Hosts: make([]string, test.hosts)}},
+		}}
+		if got := hostCountBucket(ing); got != test.want {
+			t.Errorf("hostCountBucket(%d hosts) = %q, want: %q", test.hosts, got, test.want)
+		}
+	}
+}
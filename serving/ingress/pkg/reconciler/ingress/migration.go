@@ -0,0 +1,26 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+)
+
+// checkGatewayMigration returns an error if a gateway of the configured migration doesn't
+// exist. Routes would send part of their traffic nowhere otherwise.
+func (r *Reconciler) checkGatewayMigration(ctx context.Context) error {
+	migration := config.FromContext(ctx).Ingress.GatewayMigration
+	if migration == nil {
+		return nil
+	}
+
+	for _, gateway := range []types.NamespacedName{migration.From, migration.To} {
+		if _, err := r.serviceLister.Services(gateway.Namespace).Get(gateway.Name); err != nil {
+			return fmt.Errorf("failed to get gateway %s of the migration: %w", gateway, err)
+		}
+	}
+	return nil
+}
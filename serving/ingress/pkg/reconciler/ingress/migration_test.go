@@ -0,0 +1,101 @@
+package ingress
+
+import (
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/ptr"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+const newGatewayName = "istio-ingressgateway"
+
+func newGatewayService() *corev1.Service {
+	svc := gatewayService()
+	svc.Name = newGatewayName
+	return svc
+}
+
+func withMigratedBackends(weight int32) routeOption {
+	return func(r *routev1.Route) {
+		r.Spec.To.Weight = ptr.Int32(100 - weight)
+		r.Spec.AlternateBackends = []routev1.RouteTargetReference{{
+			Kind:   "Service",
+			Name:   newGatewayName,
+			Weight: ptr.Int32(weight),
+		}}
+	}
+}
+
+func TestReconcileGatewayMigration(t *testing.T) {
+	migration := &resources.GatewayMigration{
+		From:   types.NamespacedName{Namespace: ingressNamespace, Name: svcName},
+		To:     types.NamespacedName{Namespace: ingressNamespace, Name: newGatewayName},
+		Weight: 20,
+	}
+
+	tests := []struct {
+		name        string
+		migration   *resources.GatewayMigration
+		objects     []runtime.Object
+		wantUpdates []clientgotesting.UpdateActionImpl
+		wantErr     bool
+		wantEvents  []string
+	}{{
+		name:      "shift traffic to the new gateway",
+		migration: migration,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withPortSelection(resources.PortSelectedByName)),
+			gatewayService(),
+			newGatewayService(),
+		},
+		wantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName, withPortSelection(resources.PortSelectedByName), withMigratedBackends(20)),
+		}},
+	}, {
+		name: "collapse backends after the migration",
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withMigratedBackends(20)),
+		},
+		wantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName),
+		}},
+	}, {
+		name:      "missing new gateway",
+		migration: migration,
+		objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName),
+			gatewayService(),
+		},
+		wantErr: true,
+		wantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError",
+				`failed to get gateway %s/%s of the migration: service %q not found`, ingressNamespace, newGatewayName, newGatewayName),
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table := TableTest{{
+				Name:                    test.name,
+				SkipNamespaceValidation: true,
+				Key:                     ingNamespace + "/" + ingName,
+				Objects:                 test.objects,
+				WantUpdates:             test.wantUpdates,
+				WantErr:                 test.wantErr,
+				WantEvents:              test.wantEvents,
+			}}
+			table.Test(t, newFactoryWithConfig(&config.Ingress{GatewayMigration: test.migration}, nil))
+		})
+	}
+}
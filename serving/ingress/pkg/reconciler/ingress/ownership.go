@@ -0,0 +1,73 @@
+package ingress
+
+import (
+	"context"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/controller"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+const (
+	// IngressConditionHostOwnership is false while hosts of the Ingress are held by other Routes.
+	// It's informational and doesn't affect the readiness of the Ingress, which is owned by Kourier.
+	IngressConditionHostOwnership apis.ConditionType = "HostOwnership"
+
+	hostOwnershipConflictReason = "HostOwnershipConflict"
+)
+
+// routeHostOwnershipConflicts returns the conflicts routers reported for the given Routes.
+func (r *Reconciler) routeHostOwnershipConflicts(routes []*routev1.Route) []*resources.HostOwnershipConflict {
+	var conflicts []*resources.HostOwnershipConflict
+	for _, desired := range routes {
+		route, err := r.routeLister.Routes(desired.Namespace).Get(desired.Name)
+		if err != nil {
+			continue
+		}
+		if conflict, ok := resources.RouteHostOwnershipConflict(route); ok {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// markHostOwnership records the given conflicts in the HostOwnership condition of the Ingress and
+// as events, if the condition changed. It returns true if the status changed.
+func markHostOwnership(ctx context.Context, ing *v1alpha1.Ingress, conflicts []*resources.HostOwnershipConflict) bool {
+	existing := ing.Status.GetCondition(IngressConditionHostOwnership)
+	cond := apis.Condition{
+		Type:     IngressConditionHostOwnership,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	}
+
+	if len(conflicts) == 0 {
+		// Only report ownership once it was in question.
+		if existing == nil || existing.IsTrue() {
+			return false
+		}
+	} else {
+		messages := make([]string, 0, len(conflicts))
+		for _, conflict := range conflicts {
+			messages = append(messages, conflict.Error())
+		}
+		cond.Status = corev1.ConditionFalse
+		cond.Reason = hostOwnershipConflictReason
+		cond.Message = strings.Join(messages, "; ")
+		// Conflicts persist until resolved by hand, so they're only reported once.
+		if existing != nil && existing.IsFalse() && existing.Message == cond.Message {
+			return false
+		}
+		for _, message := range messages {
+			controller.GetEventRecorder(ctx).Event(ing, corev1.EventTypeWarning, hostOwnershipConflictReason, message)
+		}
+	}
+
+	ing.GetConditionSet().Manage(&ing.Status).SetCondition(cond)
+	return true
+}
@@ -0,0 +1,129 @@
+package ingress
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+// Message of the OpenShift router enforcing namespace ownership of hosts.
+const otherNamespaceHoldsMessage = "a route in another namespace holds " + routeHost + " and is older than " + routeName
+
+func withHostOwnership(status corev1.ConditionStatus, message string) ingressOption {
+	return func(i *v1alpha1.Ingress) {
+		cond := apis.Condition{
+			Type:     IngressConditionHostOwnership,
+			Status:   status,
+			Severity: apis.ConditionSeverityInfo,
+		}
+		if status == corev1.ConditionFalse {
+			cond.Reason = hostOwnershipConflictReason
+			cond.Message = message
+		}
+		i.GetConditionSet().Manage(&i.Status).SetCondition(cond)
+	}
+}
+
+func withHostClaimed(message string) routeOption {
+	return func(r *routev1.Route) {
+		r.Status.Ingress = []routev1.RouteIngress{{
+			Host: r.Spec.Host,
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:    routev1.RouteAdmitted,
+				Status:  corev1.ConditionFalse,
+				Reason:  resources.HostAlreadyClaimedReason,
+				Message: message,
+				// Long past the grace period, but recreating the Route doesn't release the host.
+				LastTransitionTime: &metav1.Time{Time: now.Add(-time.Hour)},
+			}},
+		}}
+	}
+}
+
+func TestReconcileHostOwnership(t *testing.T) {
+	key := ingNamespace + "/" + ingName
+	conflictMessage := "host " + routeHost + " is held by a route in another namespace"
+
+	table := TableTest{{
+		Name:                    "route rejected by the router",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName),
+			route(ingressNamespace, routeName, withHostClaimed(otherNamespaceHoldsMessage)),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, hostOwnershipConflictReason, conflictMessage),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/conditions","value":[{"type":"HostOwnership","status":"False","severity":"Info","lastTransitionTime":"2020-12-01T00:00:00Z","reason":"HostOwnershipConflict","message":"host test.testns.default.domainname is held by a route in another namespace"}]}]`),
+		},
+	}, {
+		// No events are recorded on every reconciliation.
+		Name:                    "conflict already reported",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withHostOwnership(corev1.ConditionFalse, conflictMessage)),
+			route(ingressNamespace, routeName, withHostClaimed(otherNamespaceHoldsMessage)),
+		},
+	}, {
+		Name:                    "another conflict",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withHostOwnership(corev1.ConditionFalse, "host other.example.com is held by another route")),
+			route(ingressNamespace, routeName, withHostClaimed(otherNamespaceHoldsMessage)),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, hostOwnershipConflictReason, conflictMessage),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"test","path":"/status/conditions/0/type","value":"HostOwnership"},{"op":"replace","path":"/status/conditions/0","value":{"type":"HostOwnership","status":"False","severity":"Info","lastTransitionTime":"2020-12-01T00:00:00Z","reason":"HostOwnershipConflict","message":"host test.testns.default.domainname is held by a route in another namespace"}}]`),
+		},
+	}, {
+		Name:                    "conflict resolved",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withHostOwnership(corev1.ConditionFalse, conflictMessage)),
+			route(ingressNamespace, routeName),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"test","path":"/status/conditions/0/type","value":"HostOwnership"},{"op":"replace","path":"/status/conditions/0","value":{"type":"HostOwnership","status":"True","severity":"Info","lastTransitionTime":"2020-12-01T00:00:00Z"}}]`),
+		},
+	}, {
+		Name:                    "route refused by the API server",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
+		WithReactors: []clientgotesting.ReactionFunc{
+			func(action clientgotesting.Action) (bool, runtime.Object, error) {
+				if !action.Matches("create", "routes") {
+					return false, nil, nil
+				}
+				return true, nil, apierrs.NewForbidden(routev1.Resource("routes"), routeName, errors.New(otherNamespaceHoldsMessage))
+			},
+		},
+		WantCreates: []runtime.Object{route(ingressNamespace, routeName)},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, hostOwnershipConflictReason, conflictMessage),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			statusPatchAction(`[{"op":"add","path":"/status/conditions","value":[{"type":"HostOwnership","status":"False","severity":"Info","lastTransitionTime":"2020-12-01T00:00:00Z","reason":"HostOwnershipConflict","message":"host test.testns.default.domainname is held by a route in another namespace"}]}]`),
+		},
+	}}
+	table.Test(t, newFactory(nil))
+}
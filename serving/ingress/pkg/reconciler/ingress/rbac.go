@@ -0,0 +1,55 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// requiredRouteVerbs are the verbs the controller needs on Routes in all namespaces.
+var requiredRouteVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+
+// RBACValidator verifies that the service account of the controller is allowed to manage
+// Routes. Without these permissions, the controller appears to be running fine while Routes
+// silently stay out of date.
+type RBACValidator struct {
+	client authorizationv1client.SelfSubjectAccessReviewsGetter
+}
+
+// NewRBACValidator creates an RBACValidator reviewing permissions through the given client.
+func NewRBACValidator(client authorizationv1client.SelfSubjectAccessReviewsGetter) *RBACValidator {
+	return &RBACValidator{client: client}
+}
+
+// Validate returns an error listing all verbs on Routes the controller is not allowed to use.
+func (v *RBACValidator) Validate(ctx context.Context) error {
+	var missing []string
+	for _, verb := range requiredRouteVerbs {
+		review, err := v.client.SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:    routev1.GroupName,
+					Resource: "routes",
+					Verb:     verb,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to review permission to %s routes: %w", verb, err)
+		}
+		if !review.Status.Allowed {
+			missing = append(missing, verb)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("service account is not allowed to %s routes.%s in all namespaces, check the ClusterRole of the controller",
+			strings.Join(missing, ", "), routev1.GroupName)
+	}
+	return nil
+}
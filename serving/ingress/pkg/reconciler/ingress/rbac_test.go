@@ -0,0 +1,67 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// fakeAccessReviewClient allows all verbs but the denied ones. Calls to methods it doesn't
+// implement panic on the embedded nil interface.
+type fakeAccessReviewClient struct {
+	authorizationv1client.SelfSubjectAccessReviewInterface
+
+	denied map[string]bool
+	err    error
+}
+
+func (f *fakeAccessReviewClient) SelfSubjectAccessReviews() authorizationv1client.SelfSubjectAccessReviewInterface {
+	return f
+}
+
+func (f *fakeAccessReviewClient) Create(_ context.Context, review *authorizationv1.SelfSubjectAccessReview, _ metav1.CreateOptions) (*authorizationv1.SelfSubjectAccessReview, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	review = review.DeepCopy()
+	review.Status.Allowed = !f.denied[review.Spec.ResourceAttributes.Verb]
+	return review, nil
+}
+
+func TestRBACValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		client      *fakeAccessReviewClient
+		wantErr     bool
+		wantMissing string
+	}{{
+		name:   "all permissions",
+		client: &fakeAccessReviewClient{},
+	}, {
+		name:        "missing permissions",
+		client:      &fakeAccessReviewClient{denied: map[string]bool{"create": true, "delete": true}},
+		wantErr:     true,
+		wantMissing: "create, delete",
+	}, {
+		name:    "review fails",
+		client:  &fakeAccessReviewClient{err: errors.New("boom")},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewRBACValidator(test.client).Validate(context.Background())
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Validate() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if test.wantMissing != "" && !strings.Contains(err.Error(), test.wantMissing) {
+				t.Errorf("Validate() = %v, want missing verbs %q", err, test.wantMissing)
+			}
+		})
+	}
+}
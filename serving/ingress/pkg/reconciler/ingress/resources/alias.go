@@ -0,0 +1,125 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const (
+	// AliasHostsAnnotation serves the given comma-separated hosts like the primary host of an
+	// Ingress, which is the first host of its rules that isn't a traffic tag. Their Routes get
+	// the paths, timeouts and TLS settings of the Routes of the primary host.
+	AliasHostsAnnotation = "serving.knative.openshift.io/aliasHosts"
+
+	// AliasLabelKey labels the Routes of alias hosts.
+	AliasLabelKey = "serving.knative.openshift.io/alias"
+)
+
+// AliasHostConflictError indicates that an alias host is served by a Route of another Ingress.
+type AliasHostConflictError struct {
+	Host  string
+	Route types.NamespacedName
+}
+
+func (e *AliasHostConflictError) Error() string {
+	return fmt.Sprintf("alias host %s is served by route %s of another ingress", e.Host, e.Route)
+}
+
+// aliasHosts returns the normalized alias hosts the given annotations request.
+func aliasHosts(annotations map[string]string) ([]string, error) {
+	value, ok := annotations[AliasHostsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		host = NormalizeHost(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 || internalHost(host) {
+			return nil, fmt.Errorf("%w %s: value %q must be a comma-separated list of hosts served outside of the cluster", ErrInvalidAnnotation, AliasHostsAnnotation, value)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// primaryRoutes are the Routes of the primary host of an Ingress, along with the rule and paths
+// they were generated for.
+type primaryRoutes struct {
+	host   string
+	rule   networkingv1alpha1.IngressRule
+	paths  []routePath
+	routes []*routev1.Route
+}
+
+// add records the given Route generated for the path of the host of the rule, if the host is
+// the primary host.
+func (p *primaryRoutes) add(host string, rule networkingv1alpha1.IngressRule, path routePath, route *routev1.Route) {
+	if route.Labels[TagLabelKey] != "" || (p.host != "" && p.host != host) {
+		return
+	}
+	p.host, p.rule = host, rule
+	p.paths = append(p.paths, path)
+	p.routes = append(p.routes, route)
+}
+
+// makeAliasRoutes creates the Routes of the alias hosts of the Ingress. Hosts the Ingress serves
+// already are skipped.
+func makeAliasRoutes(ci *networkingv1alpha1.Ingress, primary *primaryRoutes, seen map[string]bool, o *options) ([]*routev1.Route, error) {
+	hosts, err := aliasHosts(ci.GetAnnotations())
+	if err != nil || len(hosts) == 0 {
+		return nil, err
+	}
+	if primary.host == "" {
+		o.skipped(fmt.Sprintf("alias hosts %v lack a primary host to serve them like", hosts))
+		return nil, nil
+	}
+	// Alias hosts are terminated like the primary host, even if the termination policy picks
+	// another termination for them.
+	aliasOptions := *o
+	termination := primary.routes[0].Spec.TLS.Termination
+	var routes []*routev1.Route
+	for _, host := range hosts {
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		if err := aliasHostConflict(ci, host, o); err != nil {
+			return nil, err
+		}
+		aliasOptions.terminationPolicy = TerminationPolicy{host: termination}
+		for _, path := range primary.paths {
+			route, err := makeRoute(ci, host, primary.rule, path, &aliasOptions)
+			if err != nil {
+				return nil, err
+			}
+			route.Labels[AliasLabelKey] = "true"
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+// aliasHostConflict returns an error if a Route of another Ingress serves the given alias host,
+// as returned by the hostRoutesFunc.
+func aliasHostConflict(ci *networkingv1alpha1.Ingress, host string, o *options) error {
+	if o.hostRoutesFunc == nil {
+		return nil
+	}
+	for _, route := range o.hostRoutesFunc(host) {
+		if !ownedBy(route, ci) {
+			return &AliasHostConflictError{
+				Host:  host,
+				Route: types.NamespacedName{Namespace: route.Namespace, Name: route.Name},
+			}
+		}
+	}
+	return nil
+}
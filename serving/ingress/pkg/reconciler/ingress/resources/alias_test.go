@@ -0,0 +1,152 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+func TestMakeRoutesAliasHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases string
+		hosts   []string
+		want    []string
+		wantErr bool
+	}{{
+		name:    "alias hosts",
+		aliases: "www.example.com, Shop.example.com.",
+		hosts:   []string{externalDomain},
+		want:    []string{externalHost, "www.example.com", "shop.example.com"},
+	}, {
+		name:    "alias of a host served already",
+		aliases: externalDomain2,
+		hosts:   []string{externalDomain, externalDomain2},
+		want:    []string{externalHost, externalHost2},
+	}, {
+		name:    "no primary host",
+		aliases: "www.example.com",
+		hosts:   []string{localDomain},
+	}, {
+		name:    "invalid host",
+		aliases: "www.example.com,shop_example.com",
+		hosts:   []string{externalDomain},
+		wantErr: true,
+	}, {
+		name:    "cluster-local host",
+		aliases: "other.default.svc.cluster.local",
+		hosts:   []string{externalDomain},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts(test.hosts))))
+			ing.Annotations = map[string]string{AliasHostsAnnotation: test.aliases}
+
+			routes, err := MakeRoutes(ing)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("MakeRoutes() = %v, want error: %v", err, test.wantErr)
+			}
+			var got []string
+			for _, route := range routes {
+				got = append(got, route.Spec.Host)
+				if alias := route.Labels[AliasLabelKey] == "true"; alias != (route.Spec.Host != routes[0].Spec.Host && len(test.hosts) == 1) {
+					t.Errorf("Route of host %s labeled as alias: %v", route.Spec.Host, alias)
+				}
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Hosts (-want, +got) = %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
+
+func TestMakeRoutesAliasHostsReusePrimarySettings(t *testing.T) {
+	timeout := 5 * time.Minute
+	ing := ingress(withRules(rule(withHosts([]string{externalDomain}), func(r *networkingv1alpha1.IngressRule) {
+		r.HTTP.Paths = []networkingv1alpha1.HTTPIngressPath{{Path: "/api", DeprecatedTimeout: &metav1.Duration{Duration: timeout}}, {Path: "/"}}
+	})))
+	ing.Annotations = map[string]string{AliasHostsAnnotation: "api.example.com"}
+
+	// The termination policy would pick passthrough for the alias host.
+	routes, err := MakeRoutes(ing, WithTerminationPolicy(TerminationPolicy{"example.com": routev1.TLSTerminationPassthrough}))
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	if len(routes) != 4 {
+		t.Fatalf("Got %d routes, want 4", len(routes))
+	}
+	for i, alias := range routes[2:] {
+		primary := routes[i]
+		if alias.Spec.Host != "api.example.com" || alias.Spec.Path != primary.Spec.Path {
+			t.Errorf("Alias route serves %s%s, want api.example.com%s", alias.Spec.Host, alias.Spec.Path, primary.Spec.Path)
+		}
+		if got, want := alias.Annotations[TimeoutAnnotation], primary.Annotations[TimeoutAnnotation]; got != want {
+			t.Errorf("Alias route timeout = %s, want %s", got, want)
+		}
+		if !cmp.Equal(alias.Spec.TLS, primary.Spec.TLS) {
+			t.Errorf("Alias route TLS (-want, +got) = %s", cmp.Diff(primary.Spec.TLS, alias.Spec.TLS))
+		}
+		if !cmp.Equal(alias.Spec.Port, primary.Spec.Port) {
+			t.Errorf("Alias route port (-want, +got) = %s", cmp.Diff(primary.Spec.Port, alias.Spec.Port))
+		}
+		if alias.Name == primary.Name {
+			t.Errorf("Alias route has the name %s of the primary route", alias.Name)
+		}
+	}
+}
+
+func TestMakeRoutesAliasHostConflict(t *testing.T) {
+	hostRoute := func(ingressName string) *routev1.Route {
+		return &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: lbNamespace,
+				Name:      ingressName + "-route",
+				Labels: map[string]string{
+					networking.IngressLabelKey:     ingressName,
+					serving.RouteNamespaceLabelKey: "default",
+				},
+			},
+			Spec: routev1.RouteSpec{Host: "www.example.com"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		existing *routev1.Route
+		wantErr  bool
+	}{{
+		name:     "host of another ingress",
+		existing: hostRoute("other"),
+		wantErr:  true,
+	}, {
+		name:     "own route",
+		existing: hostRoute("ingress"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = map[string]string{AliasHostsAnnotation: "www.example.com"}
+
+			_, err := MakeRoutes(ing, WithHostRoutesFunc(func(host string) []*routev1.Route {
+				if host == test.existing.Spec.Host {
+					return []*routev1.Route{test.existing}
+				}
+				return nil
+			}))
+			var conflict *AliasHostConflictError
+			if errors.As(err, &conflict) != test.wantErr {
+				t.Fatalf("MakeRoutes() = %v, want AliasHostConflictError: %v", err, test.wantErr)
+			}
+		})
+	}
+}
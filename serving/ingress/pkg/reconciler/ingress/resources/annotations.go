@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"sort"
+	"strings"
+)
+
+// knownAnnotations are the annotations of an Ingress that influence the generated Routes.
+var knownAnnotations = []string{
+	DisableRouteAnnotation,
+	WeightRoundingAnnotation,
+	KeepDrainedBackendsAnnotation,
+	OmitSingleBackendWeightAnnotation,
+	PrimaryWeightAnnotation,
+	AllowClusterLocalSplitsAnnotation,
+	HostSuffixAnnotation,
+	CertManagerIssuerAnnotation,
+	CertManagerIssuerKindAnnotation,
+	HTTP2Annotation,
+	ForwardedHeadersAnnotation,
+	TerminationAnnotation,
+	InsecurePolicyAnnotation,
+	GatewayServiceAnnotation,
+	IPWhitelistConfigMapAnnotation,
+	PathPrefixAnnotation,
+	RecreateOnChangeAnnotation,
+	HealthCheckIntervalAnnotation,
+	CookieSameSiteAnnotation,
+	MaintenanceAnnotation,
+	AliasHostsAnnotation,
+}
+
+// KnownAnnotations returns the sorted keys of all annotations of an Ingress that influence
+// the generated Routes. It allows validating annotations and warning about typos.
+func KnownAnnotations() []string {
+	known := append([]string(nil), knownAnnotations...)
+	sort.Strings(known)
+	return known
+}
+
+// routeAnnotationPrefixes are the prefixes of annotations of Routes that the router, external-dns
+// or cert-manager act on.
+var routeAnnotationPrefixes = []string{
+	"haproxy.router.openshift.io/",
+	"router.openshift.io/",
+	"network.openshift.io/",
+	"external-dns.alpha.kubernetes.io/",
+	"cert-manager.io/",
+}
+
+// ownRouteAnnotations are the annotations of Routes that are read back from existing Routes or
+// tell users how a Route was generated.
+var ownRouteAnnotations = []string{
+	HostGeneratedAnnotation,
+	GatewayPortSelectionAnnotation,
+	RecreateOnChangeAnnotation,
+}
+
+// minimalAnnotations removes all annotations from the given ones that neither have an effect on
+// the Route nor are needed by the controller, like the ones inherited from the Ingress.
+func minimalAnnotations(annotations map[string]string) {
+	for key := range annotations {
+		if !isRouteAnnotation(key) {
+			delete(annotations, key)
+		}
+	}
+}
+
+func isRouteAnnotation(key string) bool {
+	for _, own := range ownRouteAnnotations {
+		if key == own {
+			return true
+		}
+	}
+	for _, prefix := range routeAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
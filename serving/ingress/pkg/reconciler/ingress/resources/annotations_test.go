@@ -0,0 +1,95 @@
+package resources
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/networking/pkg/apis/networking"
+)
+
+func TestKnownAnnotations(t *testing.T) {
+	known := KnownAnnotations()
+	if !sort.StringsAreSorted(known) {
+		t.Errorf("KnownAnnotations() = %v, want sorted", known)
+	}
+
+	set := make(map[string]bool, len(known))
+	for _, annotation := range known {
+		set[annotation] = true
+	}
+	for _, want := range []string{DisableRouteAnnotation, WeightRoundingAnnotation, HostSuffixAnnotation} {
+		if !set[want] {
+			t.Errorf("KnownAnnotations() = %v, missing %q", known, want)
+		}
+	}
+
+	// Callers must not be able to modify the list.
+	known[0] = "foo"
+	if KnownAnnotations()[0] == "foo" {
+		t.Error("KnownAnnotations() returned shared state")
+	}
+}
+
+func TestMakeRoutesMinimalAnnotations(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+	ing.Annotations = map[string]string{
+		networking.IngressClassAnnotationKey:  "kourier.ingress.networking.knative.dev",
+		"serving.knative.dev/creator":         "admin",
+		HTTP2Annotation:                       "false",
+		CookieSameSiteAnnotation:              "Lax",
+		"haproxy.router.openshift.io/balance": "roundrobin",
+	}
+
+	full, err := MakeRoutes(ing)
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	minimal, err := MakeRoutes(ing, WithMinimalAnnotations())
+	if err != nil {
+		t.Fatal("MakeRoutes() with minimal annotations =", err)
+	}
+
+	wantFull := map[string]string{
+		networking.IngressClassAnnotationKey:  "kourier.ingress.networking.knative.dev",
+		"serving.knative.dev/creator":         "admin",
+		HTTP2Annotation:                       "false",
+		CookieSameSiteAnnotation:              "Lax",
+		"haproxy.router.openshift.io/balance": "roundrobin",
+		TimeoutAnnotation:                     defaultTimeout,
+	}
+	if got := full[0].Annotations; !cmp.Equal(got, wantFull) {
+		t.Errorf("Annotations (-want, +got) = %s", cmp.Diff(wantFull, got))
+	}
+	// Annotations of the router requested by the Ingress are kept, others only used to generate
+	// the Route are dropped.
+	wantMinimal := map[string]string{
+		CookieSameSiteAnnotation:              "Lax",
+		"haproxy.router.openshift.io/balance": "roundrobin",
+		TimeoutAnnotation:                     defaultTimeout,
+	}
+	if got := minimal[0].Annotations; !cmp.Equal(got, wantMinimal) {
+		t.Errorf("Minimal annotations (-want, +got) = %s", cmp.Diff(wantMinimal, got))
+	}
+	if !cmp.Equal(full[0].Spec, minimal[0].Spec) {
+		t.Errorf("Spec (-full, +minimal) = %s", cmp.Diff(full[0].Spec, minimal[0].Spec))
+	}
+}
+
+func TestMakeRoutesMinimalAnnotationsKeepsGenerated(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts(nil))))
+	ing.Annotations = map[string]string{RecreateOnChangeAnnotation: "true"}
+
+	routes, err := MakeRoutes(ing, WithMinimalAnnotations())
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("Got %d routes, want: 1", len(routes))
+	}
+	for _, key := range []string{HostGeneratedAnnotation, RecreateOnChangeAnnotation} {
+		if _, ok := routes[0].Annotations[key]; !ok {
+			t.Errorf("Annotations = %v, missing %q", routes[0].Annotations, key)
+		}
+	}
+}
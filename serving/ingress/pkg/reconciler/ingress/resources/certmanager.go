@@ -0,0 +1,51 @@
+package resources
+
+import (
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+const (
+	// CertManagerIssuerAnnotation names the cert-manager issuer that issues the certificates of
+	// the Routes for custom domains of an Ingress. CertManagerIssuerKindAnnotation optionally
+	// sets its kind, like "ClusterIssuer".
+	CertManagerIssuerAnnotation     = "serving.knative.openshift.io/certManagerIssuer"
+	CertManagerIssuerKindAnnotation = "serving.knative.openshift.io/certManagerIssuerKind"
+
+	// Annotations that make cert-manager's openshift-routes integration issue a certificate
+	// for a Route.
+	certManagerIssuerNameRouteAnnotation = "cert-manager.io/issuer-name"
+	certManagerIssuerKindRouteAnnotation = "cert-manager.io/issuer-kind"
+)
+
+// certManagerAnnotations returns the annotations requesting a certificate from cert-manager, as
+// configured by the given Ingress annotations.
+func certManagerAnnotations(annotations map[string]string) map[string]string {
+	issuer := annotations[CertManagerIssuerAnnotation]
+	if issuer == "" {
+		return nil
+	}
+
+	out := map[string]string{
+		certManagerIssuerNameRouteAnnotation: issuer,
+	}
+	if kind := annotations[CertManagerIssuerKindAnnotation]; kind != "" {
+		out[certManagerIssuerKindRouteAnnotation] = kind
+	}
+	return out
+}
+
+// IsCertManaged returns true if cert-manager issues the certificate of the given Route.
+func IsCertManaged(route *routev1.Route) bool {
+	return route.Annotations[certManagerIssuerNameRouteAnnotation] != ""
+}
+
+// PreserveCertificate copies the certificate cert-manager injected into the existing Route to
+// the desired one, so the certificate isn't removed again when updating the Route.
+func PreserveCertificate(desired, existing *routev1.Route) {
+	if !IsCertManaged(desired) || desired.Spec.TLS == nil || existing.Spec.TLS == nil {
+		return
+	}
+	desired.Spec.TLS.Certificate = existing.Spec.TLS.Certificate
+	desired.Spec.TLS.Key = existing.Spec.TLS.Key
+	desired.Spec.TLS.CACertificate = existing.Spec.TLS.CACertificate
+}
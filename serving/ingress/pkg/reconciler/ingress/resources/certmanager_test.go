@@ -0,0 +1,123 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+func TestMakeRoutesCertManager(t *testing.T) {
+	const appsDomain = "apps.example.com"
+
+	tests := []struct {
+		name        string
+		host        string
+		annotations map[string]string
+		want        map[string]string
+	}{{
+		name: "no issuer",
+		host: "foo.vanity.com",
+	}, {
+		name:        "custom domain",
+		host:        "foo.vanity.com",
+		annotations: map[string]string{CertManagerIssuerAnnotation: "letsencrypt"},
+		want: map[string]string{
+			certManagerIssuerNameRouteAnnotation: "letsencrypt",
+		},
+	}, {
+		name: "custom domain with issuer kind",
+		host: "foo.vanity.com",
+		annotations: map[string]string{
+			CertManagerIssuerAnnotation:     "letsencrypt",
+			CertManagerIssuerKindAnnotation: "ClusterIssuer",
+		},
+		want: map[string]string{
+			certManagerIssuerNameRouteAnnotation: "letsencrypt",
+			certManagerIssuerKindRouteAnnotation: "ClusterIssuer",
+		},
+	}, {
+		name:        "apps domain",
+		host:        "foo.default.apps.example.com",
+		annotations: map[string]string{CertManagerIssuerAnnotation: "letsencrypt"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{test.host}))))
+			ing.Annotations = test.annotations
+
+			routes, err := MakeRoutes(ing, WithAppsDomain(appsDomain))
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			got := map[string]string{}
+			for _, key := range []string{certManagerIssuerNameRouteAnnotation, certManagerIssuerKindRouteAnnotation} {
+				if value, ok := routes[0].Annotations[key]; ok {
+					got[key] = value
+				}
+			}
+			want := test.want
+			if want == nil {
+				want = map[string]string{}
+			}
+			if !cmp.Equal(got, want) {
+				t.Errorf("Annotations (-want, +got) = %s", cmp.Diff(want, got))
+			}
+			if got, want := IsCertManaged(routes[0]), test.want != nil; got != want {
+				t.Errorf("IsCertManaged() = %v, want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesStrictTLSCertManager(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+	ing.Annotations = map[string]string{CertManagerIssuerAnnotation: "letsencrypt"}
+
+	if _, err := MakeRoutes(ing, WithStrictTLS(true)); err != nil {
+		t.Errorf("MakeRoutes() = %v, want cert-manager to be a certificate source", err)
+	}
+}
+
+func TestPreserveCertificate(t *testing.T) {
+	existing := &routev1.Route{
+		Spec: routev1.RouteSpec{
+			TLS: &routev1.TLSConfig{
+				Termination:   routev1.TLSTerminationEdge,
+				Certificate:   "cert",
+				Key:           "key",
+				CACertificate: "ca",
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *routev1.TLSConfig
+	}{{
+		name: "not managed by cert-manager",
+		want: &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge},
+	}, {
+		name:        "managed by cert-manager",
+		annotations: map[string]string{certManagerIssuerNameRouteAnnotation: "letsencrypt"},
+		want:        existing.Spec.TLS,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desired := &routev1.Route{
+				Spec: routev1.RouteSpec{
+					TLS: &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge},
+				},
+			}
+			desired.Annotations = test.annotations
+
+			PreserveCertificate(desired, existing)
+			if !cmp.Equal(desired.Spec.TLS, test.want) {
+				t.Errorf("TLS (-want, +got) = %s", cmp.Diff(test.want, desired.Spec.TLS))
+			}
+		})
+	}
+}
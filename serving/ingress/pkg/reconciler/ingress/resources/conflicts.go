@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// hstsHeaderRouteAnnotation makes the router add a Strict-Transport-Security header to responses.
+const hstsHeaderRouteAnnotation = "haproxy.router.openshift.io/hsts_header"
+
+// terminationConflict is an annotation the router can't honor on Routes with the given
+// termination.
+type terminationConflict struct {
+	annotation  string
+	termination routev1.TLSTerminationType
+	reason      string
+}
+
+// terminationConflicts are the known annotations conflicting with a termination. The router
+// only forwards the TLS connections of passthrough Routes, so it can't act on their requests.
+var terminationConflicts = []terminationConflict{{
+	annotation:  PathPrefixAnnotation,
+	termination: routev1.TLSTerminationPassthrough,
+	reason:      "the router can't tell paths apart",
+}, {
+	annotation:  RewriteTargetAnnotation,
+	termination: routev1.TLSTerminationPassthrough,
+	reason:      "the router can't rewrite paths",
+}, {
+	annotation:  ForwardedHeadersAnnotation,
+	termination: routev1.TLSTerminationPassthrough,
+	reason:      "the router can't add headers",
+}, {
+	annotation:  hstsHeaderRouteAnnotation,
+	termination: routev1.TLSTerminationPassthrough,
+	reason:      "the router can't add headers",
+}, {
+	annotation:  CookieSameSiteAnnotation,
+	termination: routev1.TLSTerminationPassthrough,
+	reason:      "the router can't set cookies",
+}}
+
+// validateTermination returns an error listing all annotations of the given ones that conflict
+// with the given termination.
+func validateTermination(annotations map[string]string, termination routev1.TLSTerminationType) error {
+	var conflicts []string
+	for _, conflict := range terminationConflicts {
+		if _, ok := annotations[conflict.annotation]; ok && conflict.termination == termination {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", conflict.annotation, conflict.reason))
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: not supported with termination %q: %s", ErrInvalidAnnotation, termination, strings.Join(conflicts, ", "))
+}
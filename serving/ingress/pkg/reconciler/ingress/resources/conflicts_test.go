@@ -0,0 +1,76 @@
+package resources
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMakeRoutesTerminationConflicts(t *testing.T) {
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		wantConflicts []string
+	}{{
+		name: "path prefix with passthrough",
+		annotations: map[string]string{
+			TerminationAnnotation: "passthrough",
+			PathPrefixAnnotation:  "/payments",
+		},
+		wantConflicts: []string{PathPrefixAnnotation},
+	}, {
+		name: "rewrite target with passthrough",
+		annotations: map[string]string{
+			TerminationAnnotation:   "passthrough",
+			RewriteTargetAnnotation: "/",
+		},
+		wantConflicts: []string{RewriteTargetAnnotation},
+	}, {
+		name: "headers and cookies with passthrough",
+		annotations: map[string]string{
+			TerminationAnnotation:      "passthrough",
+			ForwardedHeadersAnnotation: "replace",
+			hstsHeaderRouteAnnotation:  "max-age=31536000",
+			CookieSameSiteAnnotation:   "Strict",
+		},
+		wantConflicts: []string{ForwardedHeadersAnnotation, hstsHeaderRouteAnnotation, CookieSameSiteAnnotation},
+	}, {
+		name: "rewrite target with edge",
+		annotations: map[string]string{
+			TerminationAnnotation:      "edge",
+			PathPrefixAnnotation:       "/payments",
+			ForwardedHeadersAnnotation: "replace",
+			CookieSameSiteAnnotation:   "Strict",
+		},
+	}, {
+		name: "passthrough with timeout",
+		annotations: map[string]string{
+			TerminationAnnotation:    "passthrough",
+			InsecurePolicyAnnotation: "Redirect",
+			TimeoutAnnotation:        "5s",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = test.annotations
+
+			_, err := MakeRoutes(ing)
+			if len(test.wantConflicts) == 0 {
+				if err != nil {
+					t.Fatalf("MakeRoutes() = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidAnnotation) {
+				t.Fatalf("MakeRoutes() = %v, want %v", err, ErrInvalidAnnotation)
+			}
+			for _, conflict := range test.wantConflicts {
+				if !strings.Contains(err.Error(), conflict) {
+					t.Errorf("MakeRoutes() = %v, want it to list %s", err, conflict)
+				}
+			}
+		})
+	}
+}
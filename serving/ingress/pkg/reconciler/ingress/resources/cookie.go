@@ -0,0 +1,37 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// CookieSameSiteAnnotation sets the SameSite attribute of the session affinity cookie of the
+// router. It's copied from the Ingress onto its Routes.
+const CookieSameSiteAnnotation = "router.openshift.io/cookie-same-site"
+
+// cookieSameSiteValues are the values of the CookieSameSiteAnnotation the router supports.
+var cookieSameSiteValues = []string{"None", "Lax", "Strict"}
+
+// validateCookieSameSite returns an error if the SameSite attribute the given annotations set
+// isn't supported, or is None on a Route with the given insecure policy. Browsers only accept
+// SameSite=None on Secure cookies, which the router can't use while it serves plain HTTP too.
+func validateCookieSameSite(annotations map[string]string, insecure routev1.InsecureEdgeTerminationPolicyType) error {
+	value, ok := annotations[CookieSameSiteAnnotation]
+	if !ok {
+		return nil
+	}
+	for _, sameSite := range cookieSameSiteValues {
+		if value != sameSite {
+			continue
+		}
+		if value == "None" && insecure == routev1.InsecureEdgeTerminationPolicyAllow {
+			return fmt.Errorf("%w %s: value %q requires Secure cookies, but insecure policy %q serves plain HTTP, set %s to %q",
+				ErrInvalidAnnotation, CookieSameSiteAnnotation, value, insecure, InsecurePolicyAnnotation, routev1.InsecureEdgeTerminationPolicyRedirect)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w %s: value %q must be one of %s", ErrInvalidAnnotation, CookieSameSiteAnnotation,
+		value, strings.Join(cookieSameSiteValues, ", "))
+}
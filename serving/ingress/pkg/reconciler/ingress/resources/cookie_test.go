@@ -0,0 +1,63 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMakeRoutesCookieSameSite(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     bool
+	}{{
+		name:        "lax",
+		annotations: map[string]string{CookieSameSiteAnnotation: "Lax"},
+	}, {
+		name:        "strict",
+		annotations: map[string]string{CookieSameSiteAnnotation: "Strict"},
+	}, {
+		name: "none with redirect",
+		annotations: map[string]string{
+			CookieSameSiteAnnotation: "None",
+			InsecurePolicyAnnotation: "Redirect",
+		},
+	}, {
+		name: "none without plain HTTP",
+		annotations: map[string]string{
+			CookieSameSiteAnnotation: "None",
+			InsecurePolicyAnnotation: "None",
+		},
+	}, {
+		name:        "none with plain HTTP allowed",
+		annotations: map[string]string{CookieSameSiteAnnotation: "None"},
+		wantErr:     true,
+	}, {
+		name:        "unsupported value",
+		annotations: map[string]string{CookieSameSiteAnnotation: "lax"},
+		wantErr:     true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = test.annotations
+
+			routes, err := MakeRoutes(ing)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidAnnotation) {
+					t.Fatalf("MakeRoutes() = %v, want %v", err, ErrInvalidAnnotation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			for _, route := range routes {
+				if got, want := route.Annotations[CookieSameSiteAnnotation], test.annotations[CookieSameSiteAnnotation]; got != want {
+					t.Errorf("%s = %q, want %q", CookieSameSiteAnnotation, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+// DirectServiceUnavailableReason is the reason of warnings about Routes that target the gateway
+// in direct service mode, because the Service of a split has no port they can target.
+const DirectServiceUnavailableReason = "DirectServiceUnavailable"
+
+// AllowClusterLocalSplitsAnnotation controls whether Routes targeting the Services of splits
+// directly keep the splits to cluster-local Services among their backends. The Services of
+// splits are cluster-local if a cluster-local rule of the Ingress targets them. If set to
+// "false", such splits are dropped and their weight is redistributed to the remaining backends.
+// Defaults to "true".
+const AllowClusterLocalSplitsAnnotation = "serving.knative.openshift.io/allowClusterLocalSplits"
+
+// ClusterLocalSplitsServedReason is the reason of warnings about Routes that target the gateway,
+// which serves the cluster-local splits of their path although they're to be excluded.
+const ClusterLocalSplitsServedReason = "ClusterLocalSplitsServed"
+
+// MissingBackendServiceError indicates that the Service of a split a Route targets directly in
+// direct service mode doesn't exist in the namespace of the Route. It may still be created, so
+// generating the Routes should be retried.
+type MissingBackendServiceError struct {
+	Service types.NamespacedName
+}
+
+func (e *MissingBackendServiceError) Error() string {
+	return fmt.Sprintf("backend service %s doesn't exist", e.Service)
+}
+
+// directTarget are the Services a Route targets directly, bypassing the gateway.
+type directTarget struct {
+	namespace string
+	port      string
+	backends  []backend
+}
+
+// directServiceTarget returns the Services the Route of the given path targets in direct
+// service mode. That's only possible if the Route serves a single path of the rule, as the gateway
+// is needed to match headers, if the path adds no headers to requests, which only the gateway
+// does, and if all splits of the path target the same port of Services in the same namespace,
+// which the Route is created in. Splits to the given excluded Services are dropped. Nil is
+// returned if the Route has to target the gateway.
+func directServiceTarget(host string, path routePath, excluded map[types.NamespacedName]bool, o *options) (*directTarget, error) {
+	if o.servicePortFunc == nil || len(path.splits) == 0 || path.appendsHeaders {
+		return nil, nil
+	}
+	namespace, port := path.splits[0].ServiceNamespace, path.splits[0].ServicePort
+	for _, split := range path.splits[1:] {
+		if split.ServiceNamespace != namespace || split.ServicePort != port {
+			return nil, nil
+		}
+	}
+
+	target := &directTarget{namespace: namespace}
+	for _, split := range path.splits {
+		service := types.NamespacedName{Namespace: split.ServiceNamespace, Name: split.ServiceName}
+		if excluded[service] {
+			continue
+		}
+		targetPort, exists, err := o.servicePortFunc(service.Namespace, service.Name, port)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, &MissingBackendServiceError{Service: service}
+		}
+		if targetPort == "" {
+			o.warn(DirectServiceUnavailableReason, fmt.Sprintf(
+				"The route of host %q targets the gateway, backend service %s has no port %s routes can target", host, service, port.String()))
+			return nil, nil
+		}
+		target.port = targetPort
+		target.backends = append(target.backends, backend{name: split.ServiceName, percent: split.Percent})
+	}
+	if len(target.backends) == 0 {
+		return nil, nil
+	}
+	return target, nil
+}
+
+// allowClusterLocalSplits returns false if the given annotations request excluding splits to
+// cluster-local Services from the backends of Routes.
+func allowClusterLocalSplits(annotations map[string]string) (bool, error) {
+	value, ok := annotations[AllowClusterLocalSplitsAnnotation]
+	if !ok {
+		return true, nil
+	}
+	allow, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w %s: value %q must be a boolean", ErrInvalidAnnotation, AllowClusterLocalSplitsAnnotation, value)
+	}
+	return allow, nil
+}
+
+// clusterLocalServices returns the Services the cluster-local rules of the given Ingress target.
+func clusterLocalServices(ci *networkingv1alpha1.Ingress) map[types.NamespacedName]bool {
+	services := make(map[types.NamespacedName]bool)
+	for _, rule := range ci.Spec.Rules {
+		if rule.Visibility != networkingv1alpha1.IngressVisibilityClusterLocal || rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			for _, split := range path.Splits {
+				services[types.NamespacedName{Namespace: split.ServiceNamespace, Name: split.ServiceName}] = true
+			}
+		}
+	}
+	return services
+}
+
+// targetsAny returns true if any of the given splits targets one of the given Services.
+func targetsAny(splits []networkingv1alpha1.IngressBackendSplit, services map[types.NamespacedName]bool) bool {
+	for _, split := range splits {
+		if services[types.NamespacedName{Namespace: split.ServiceNamespace, Name: split.ServiceName}] {
+			return true
+		}
+	}
+	return false
+}
+
+// ServicePortTarget returns the target port of a Route reaching the given port of the Service,
+// which is referred to by its name or number. It returns an empty string if the Service has no
+// such port or it can't be referred to.
+func ServicePortTarget(service *corev1.Service, port intstr.IntOrString) string {
+	for _, p := range service.Spec.Ports {
+		if (port.Type == intstr.String && p.Name == port.StrVal) || (port.Type == intstr.Int && p.Port == port.IntVal) {
+			return portTarget(p)
+		}
+	}
+	return ""
+}
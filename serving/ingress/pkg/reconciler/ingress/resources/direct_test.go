@@ -0,0 +1,346 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/ptr"
+)
+
+func TestMakeRoutesDirectServiceMode(t *testing.T) {
+	split := func(namespace, name string, port intstr.IntOrString, percent int) networkingv1alpha1.IngressBackendSplit {
+		return networkingv1alpha1.IngressBackendSplit{
+			IngressBackend: networkingv1alpha1.IngressBackend{ServiceNamespace: namespace, ServiceName: name, ServicePort: port},
+			Percent:        percent,
+		}
+	}
+	withAppendHeaders := func(split networkingv1alpha1.IngressBackendSplit, headers map[string]string) networkingv1alpha1.IngressBackendSplit {
+		split.AppendHeaders = headers
+		return split
+	}
+	http := intstr.FromInt(80)
+	// Services are known by name, test-00003 has no port routes can target.
+	ports := map[string]string{"test-00001": "http", "test-00002": "http", "test-00003": ""}
+
+	tests := []struct {
+		name        string
+		paths       []networkingv1alpha1.HTTPIngressPath
+		annotations map[string]string
+		wantNS      string
+		wantTo      string
+		wantAlt     []routev1.RouteTargetReference
+		wantPort    string
+		wantWarning bool
+		wantErr     bool
+	}{{
+		name:     "single split",
+		paths:    []networkingv1alpha1.HTTPIngressPath{{Splits: []networkingv1alpha1.IngressBackendSplit{split("default", "test-00001", http, 100)}}},
+		wantNS:   "default",
+		wantTo:   "test-00001",
+		wantPort: "http",
+	}, {
+		name: "traffic split",
+		paths: []networkingv1alpha1.HTTPIngressPath{{Splits: []networkingv1alpha1.IngressBackendSplit{
+			split("default", "test-00001", http, 80),
+			split("default", "test-00002", http, 20),
+		}}},
+		wantNS:   "default",
+		wantTo:   "test-00001",
+		wantAlt:  []routev1.RouteTargetReference{{Kind: "Service", Name: "test-00002", Weight: ptr.Int32(20)}},
+		wantPort: "http",
+	}, {
+		name: "splits in several namespaces",
+		paths: []networkingv1alpha1.HTTPIngressPath{{Splits: []networkingv1alpha1.IngressBackendSplit{
+			split("default", "test-00001", http, 80),
+			split("other", "test-00002", http, 20),
+		}}},
+		wantNS:   lbNamespace,
+		wantTo:   lbService,
+		wantPort: KourierHTTPPort,
+	}, {
+		name: "paths matching headers",
+		paths: []networkingv1alpha1.HTTPIngressPath{{
+			Headers: map[string]networkingv1alpha1.HeaderMatch{"Knative-Serving-Tag": {Exact: "latest"}},
+			Splits:  []networkingv1alpha1.IngressBackendSplit{split("default", "test-00002", http, 100)},
+		}, {
+			Splits: []networkingv1alpha1.IngressBackendSplit{split("default", "test-00001", http, 100)},
+		}},
+		wantNS:   lbNamespace,
+		wantTo:   lbService,
+		wantPort: KourierHTTPPort,
+	}, {
+		// Kourier adds the headers the activator needs to find the revision while scaled to zero.
+		name: "splits appending activator headers",
+		paths: []networkingv1alpha1.HTTPIngressPath{{Splits: []networkingv1alpha1.IngressBackendSplit{
+			withAppendHeaders(split("default", "test-00001", http, 100), map[string]string{
+				"Knative-Serving-Revision":  "test-00001",
+				"Knative-Serving-Namespace": "default",
+			}),
+		}}},
+		wantNS:   lbNamespace,
+		wantTo:   lbService,
+		wantPort: KourierHTTPPort,
+	}, {
+		name: "path appending the tag header",
+		paths: []networkingv1alpha1.HTTPIngressPath{{
+			AppendHeaders: map[string]string{"Knative-Serving-Tag": "latest"},
+			Splits:        []networkingv1alpha1.IngressBackendSplit{split("default", "test-00001", http, 100)},
+		}},
+		wantNS:   lbNamespace,
+		wantTo:   lbService,
+		wantPort: KourierHTTPPort,
+	}, {
+		name:        "passthrough",
+		paths:       []networkingv1alpha1.HTTPIngressPath{{Splits: []networkingv1alpha1.IngressBackendSplit{split("default", "test-00001", http, 100)}}},
+		annotations: map[string]string{TerminationAnnotation: "passthrough"},
+		wantNS:      lbNamespace,
+		wantTo:      lbService,
+		wantPort:    KourierHTTPSPort,
+	}, {
+		name:        "no port to target",
+		paths:       []networkingv1alpha1.HTTPIngressPath{{Splits: []networkingv1alpha1.IngressBackendSplit{split("default", "test-00003", http, 100)}}},
+		wantNS:      lbNamespace,
+		wantTo:      lbService,
+		wantPort:    KourierHTTPPort,
+		wantWarning: true,
+	}, {
+		name:    "missing service",
+		paths:   []networkingv1alpha1.HTTPIngressPath{{Splits: []networkingv1alpha1.IngressBackendSplit{split("default", "test-00004", http, 100)}}},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}), func(r *networkingv1alpha1.IngressRule) {
+				r.HTTP.Paths = test.paths
+			})))
+			ing.Annotations = test.annotations
+
+			warned := false
+			routes, err := MakeRoutes(ing,
+				WithDirectServiceMode(func(namespace, name string, port intstr.IntOrString) (string, bool, error) {
+					target, ok := ports[name]
+					return target, ok, nil
+				}),
+				WithWarningFunc(func(reason, _ string) {
+					warned = warned || reason == DirectServiceUnavailableReason
+				}))
+			var missing *MissingBackendServiceError
+			if errors.As(err, &missing) != test.wantErr {
+				t.Fatalf("MakeRoutes() = %v, want MissingBackendServiceError: %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if len(routes) != 1 {
+				t.Fatalf("Got %d routes, want 1", len(routes))
+			}
+			route := routes[0]
+			if route.Namespace != test.wantNS || route.Spec.To.Name != test.wantTo {
+				t.Errorf("Route in namespace %s targets %s, want namespace %s and %s", route.Namespace, route.Spec.To.Name, test.wantNS, test.wantTo)
+			}
+			if !cmp.Equal(route.Spec.AlternateBackends, test.wantAlt) {
+				t.Errorf("AlternateBackends (-want, +got) = %s", cmp.Diff(test.wantAlt, route.Spec.AlternateBackends))
+			}
+			if got := route.Spec.Port.TargetPort.String(); got != test.wantPort {
+				t.Errorf("TargetPort = %s, want %s", got, test.wantPort)
+			}
+			if warned != test.wantWarning {
+				t.Errorf("Warned about %s: %v, want %v", DirectServiceUnavailableReason, warned, test.wantWarning)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesClusterLocalSplits(t *testing.T) {
+	split := func(name string, percent int) networkingv1alpha1.IngressBackendSplit {
+		return networkingv1alpha1.IngressBackendSplit{
+			IngressBackend: networkingv1alpha1.IngressBackend{ServiceNamespace: "default", ServiceName: name, ServicePort: intstr.FromInt(80)},
+			Percent:        percent,
+		}
+	}
+	// test-00002 is also served by a cluster-local tag.
+	external := rule(withHosts([]string{externalDomain}), func(r *networkingv1alpha1.IngressRule) {
+		r.HTTP.Paths[0].Splits = []networkingv1alpha1.IngressBackendSplit{
+			split("test-00001", 40), split("test-00002", 30), split("test-00003", 30),
+		}
+	})
+	local := rule(withLocalVisibilityRule, withHosts([]string{localDomain}), func(r *networkingv1alpha1.IngressRule) {
+		r.HTTP.Paths[0].Splits = []networkingv1alpha1.IngressBackendSplit{split("test-00002", 100)}
+	})
+	allAlternates := []routev1.RouteTargetReference{
+		{Kind: "Service", Name: "test-00002", Weight: ptr.Int32(30)},
+		{Kind: "Service", Name: "test-00003", Weight: ptr.Int32(30)},
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		direct      bool
+		wantTo      routev1.RouteTargetReference
+		wantAlt     []routev1.RouteTargetReference
+		wantWarning bool
+		wantErr     error
+	}{{
+		name:    "allowed by default",
+		direct:  true,
+		wantTo:  routev1.RouteTargetReference{Kind: "Service", Name: "test-00001", Weight: ptr.Int32(40)},
+		wantAlt: allAlternates,
+	}, {
+		name:        "allowed",
+		annotations: map[string]string{AllowClusterLocalSplitsAnnotation: "true"},
+		direct:      true,
+		wantTo:      routev1.RouteTargetReference{Kind: "Service", Name: "test-00001", Weight: ptr.Int32(40)},
+		wantAlt:     allAlternates,
+	}, {
+		// The 30 percent of the cluster-local split are redistributed proportionally.
+		name:        "excluded with redistribution",
+		annotations: map[string]string{AllowClusterLocalSplitsAnnotation: "false"},
+		direct:      true,
+		wantTo:      routev1.RouteTargetReference{Kind: "Service", Name: "test-00001", Weight: ptr.Int32(57)},
+		wantAlt:     []routev1.RouteTargetReference{{Kind: "Service", Name: "test-00003", Weight: ptr.Int32(43)}},
+	}, {
+		name:        "excluded through the gateway",
+		annotations: map[string]string{AllowClusterLocalSplitsAnnotation: "false"},
+		wantTo:      routev1.RouteTargetReference{Kind: "Service", Name: lbService, Weight: ptr.Int32(100)},
+		wantWarning: true,
+	}, {
+		name:        "invalid",
+		annotations: map[string]string{AllowClusterLocalSplitsAnnotation: "never"},
+		direct:      true,
+		wantErr:     ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(external, local))
+			ing.Annotations = test.annotations
+
+			warned := false
+			opts := []Option{WithWarningFunc(func(reason, _ string) {
+				warned = warned || reason == ClusterLocalSplitsServedReason
+			})}
+			if test.direct {
+				opts = append(opts, WithDirectServiceMode(func(string, string, intstr.IntOrString) (string, bool, error) {
+					return "http", true, nil
+				}))
+			}
+			routes, err := MakeRoutes(ing, opts...)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			if len(routes) != 1 {
+				t.Fatalf("Got %d routes, want 1", len(routes))
+			}
+			if !cmp.Equal(routes[0].Spec.To, test.wantTo) {
+				t.Errorf("To (-want, +got) = %s", cmp.Diff(test.wantTo, routes[0].Spec.To))
+			}
+			if !cmp.Equal(routes[0].Spec.AlternateBackends, test.wantAlt) {
+				t.Errorf("AlternateBackends (-want, +got) = %s", cmp.Diff(test.wantAlt, routes[0].Spec.AlternateBackends))
+			}
+			if warned != test.wantWarning {
+				t.Errorf("Warned about %s: %v, want %v", ClusterLocalSplitsServedReason, warned, test.wantWarning)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesRoundingPolicy(t *testing.T) {
+	split := func(name string, percent int) networkingv1alpha1.IngressBackendSplit {
+		return networkingv1alpha1.IngressBackendSplit{
+			IngressBackend: networkingv1alpha1.IngressBackend{ServiceNamespace: "default", ServiceName: name, ServicePort: intstr.FromInt(80)},
+			Percent:        percent,
+		}
+	}
+	// Dropping the cluster-local split leaves 10 and 45 percent, which are scaled to 18.18 and
+	// 81.82 percent.
+	external := rule(withHosts([]string{externalDomain}), func(r *networkingv1alpha1.IngressRule) {
+		r.HTTP.Paths[0].Splits = []networkingv1alpha1.IngressBackendSplit{
+			split("test-00001", 10), split("test-00002", 45), split("test-00003", 45),
+		}
+	})
+	local := rule(withLocalVisibilityRule, withHosts([]string{localDomain}), func(r *networkingv1alpha1.IngressRule) {
+		r.HTTP.Paths[0].Splits = []networkingv1alpha1.IngressBackendSplit{split("test-00002", 100)}
+	})
+
+	tests := []struct {
+		name       string
+		policy     *string
+		wantWeight int32
+	}{{
+		name:       "largest remainder by default",
+		wantWeight: 18,
+	}, {
+		name:       "largest remainder",
+		policy:     ptr.String(string(RoundingLargestRemainder)),
+		wantWeight: 18,
+	}, {
+		name:       "truncate",
+		policy:     ptr.String(string(RoundingTruncate)),
+		wantWeight: 19,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(external, local))
+			ing.Annotations = map[string]string{AllowClusterLocalSplitsAnnotation: "false"}
+			if test.policy != nil {
+				ing.Annotations[WeightRoundingAnnotation] = *test.policy
+			}
+
+			routes, err := MakeRoutes(ing, WithDirectServiceMode(func(string, string, intstr.IntOrString) (string, bool, error) {
+				return "http", true, nil
+			}))
+			if err != nil {
+				t.Fatal("MakeRoutes() =", err)
+			}
+			if len(routes) != 1 {
+				t.Fatalf("Got %d routes, want 1", len(routes))
+			}
+			wantTo := routev1.RouteTargetReference{Kind: "Service", Name: "test-00001", Weight: ptr.Int32(test.wantWeight)}
+			wantAlt := []routev1.RouteTargetReference{{Kind: "Service", Name: "test-00003", Weight: ptr.Int32(100 - test.wantWeight)}}
+			if !cmp.Equal(routes[0].Spec.To, wantTo) {
+				t.Errorf("To (-want, +got) = %s", cmp.Diff(wantTo, routes[0].Spec.To))
+			}
+			if !cmp.Equal(routes[0].Spec.AlternateBackends, wantAlt) {
+				t.Errorf("AlternateBackends (-want, +got) = %s", cmp.Diff(wantAlt, routes[0].Spec.AlternateBackends))
+			}
+		})
+	}
+}
+
+func TestServicePortTarget(t *testing.T) {
+	service := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(8012)},
+				{Port: 9090, TargetPort: intstr.FromInt(9091)},
+			},
+		},
+	}
+
+	tests := []struct {
+		port intstr.IntOrString
+		want string
+	}{
+		{intstr.FromInt(80), "http"},
+		{intstr.FromString("http"), "http"},
+		{intstr.FromInt(9090), "9091"},
+		{intstr.FromInt(8080), ""},
+		{intstr.FromString("http2"), ""},
+	}
+
+	for _, test := range tests {
+		if got := ServicePortTarget(service, test.port); got != test.want {
+			t.Errorf("ServicePortTarget(%s) = %q, want %q", test.port.String(), got, test.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package resources
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// DefaultDomainTemplate is the default domain-template of Knative Serving's config-network.
+const DefaultDomainTemplate = "{{.Name}}.{{.Namespace}}.{{.Domain}}"
+
+// DomainTemplateMismatchReason is the reason of warnings about Ingresses whose external hosts
+// don't include the host the domain template of Knative Serving generates for their Route.
+const DomainTemplateMismatchReason = "DomainTemplateMismatch"
+
+// DomainTemplate generates the external hosts of Knative Routes like Knative Serving does, from
+// the domain-template of its config-network and the domain of its config-domain.
+type DomainTemplate struct {
+	// Template is the domain-template, DefaultDomainTemplate if empty.
+	Template string
+
+	// Domain is the domain Knative Serving serves the Routes on.
+	Domain string
+}
+
+// domainTemplateValues are the values the domain-template of Knative Serving is executed with.
+type domainTemplateValues struct {
+	Name        string
+	Namespace   string
+	Domain      string
+	Annotations map[string]string
+	Labels      map[string]string
+}
+
+// Validate returns an error if the template can't be parsed or doesn't generate a host served
+// outside of the cluster.
+func (d *DomainTemplate) Validate() error {
+	host, err := d.host("name", "namespace", nil, nil)
+	if err != nil {
+		return err
+	}
+	if internalHost(host) {
+		return fmt.Errorf("domain template generates host %q that is not served outside of the cluster", host)
+	}
+	return nil
+}
+
+// host returns the host the template generates for the given Knative Route.
+func (d *DomainTemplate) host(name, namespace string, annotations, labels map[string]string) (string, error) {
+	text := d.Template
+	if text == "" {
+		text = DefaultDomainTemplate
+	}
+	tmpl, err := template.New("domain-template").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse domain template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, domainTemplateValues{
+		Name:        name,
+		Namespace:   namespace,
+		Domain:      d.Domain,
+		Annotations: annotations,
+		Labels:      labels,
+	}); err != nil {
+		return "", fmt.Errorf("failed to execute domain template: %w", err)
+	}
+	return NormalizeHost(buf.String()), nil
+}
+
+// externalHost returns the host the template generates for the Knative Route of the given
+// Ingress, if the given rule serves it. That's the case if one of the hosts of the rule is the
+// cluster-local host of the Route, which keeps rules of tags and domain mappings out. It returns
+// an empty host otherwise.
+func (d *DomainTemplate) externalHost(ci *networkingv1alpha1.Ingress, rule networkingv1alpha1.IngressRule) (string, error) {
+	name, namespace := ci.Labels[serving.RouteLabelKey], ci.Labels[serving.RouteNamespaceLabelKey]
+	if name == "" || namespace == "" {
+		return "", nil
+	}
+	local := name + "." + namespace + "."
+	for _, host := range rule.Hosts {
+		host = NormalizeHost(host)
+		if strings.HasPrefix(host+".", local) && internalHost(host) {
+			host, err := d.host(name, namespace, ci.Annotations, ci.Labels)
+			if err != nil {
+				return "", err
+			}
+			if internalHost(host) {
+				return "", nil
+			}
+			return host, nil
+		}
+	}
+	return "", nil
+}
+
+// internalHost returns true if the given host is only served inside of the cluster, like
+// myksvc.myproject.svc.cluster.local.
+// TODO: This also matches any top-level vanity domains like foo.com the user may have set. But,
+// it tackles the autogenerated name case which is the biggest pain point.
+func internalHost(host string) bool {
+	parts := strings.Split(host, ".")
+	return len(parts) <= 2 || parts[2] == "svc"
+}
+
+// domainTemplateHosts returns the hosts Routes of the given rule are generated for. If a domain
+// template is set and the rule only has cluster-local hosts, the external host of the Knative
+// Route is reconstructed from it. Otherwise, it's used to cross-check that the rule serves the
+// host Knative generates for the Route, which is warned about if it doesn't.
+func domainTemplateHosts(ci *networkingv1alpha1.Ingress, rule networkingv1alpha1.IngressRule, o *options) ([]string, error) {
+	if o.domainTemplate == nil {
+		return rule.Hosts, nil
+	}
+	expected, err := o.domainTemplate.externalHost(ci, rule)
+	if err != nil || expected == "" {
+		return rule.Hosts, err
+	}
+	external := false
+	for _, host := range rule.Hosts {
+		host = NormalizeHost(host)
+		if host == expected {
+			return rule.Hosts, nil
+		}
+		if !internalHost(host) {
+			external = true
+		}
+	}
+	if external {
+		o.warn(DomainTemplateMismatchReason, fmt.Sprintf(
+			"None of the hosts %v is host %q the domain template generates for the route", rule.Hosts, expected))
+		return rule.Hosts, nil
+	}
+	return append(append([]string(nil), rule.Hosts...), expected), nil
+}
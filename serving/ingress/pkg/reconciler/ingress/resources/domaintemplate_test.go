@@ -0,0 +1,119 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestMakeRoutesDomainTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		hosts     []string
+		template  string
+		want      []string
+		wantWarns int
+	}{{
+		name:  "reconstruct external host",
+		hosts: []string{"route1.default", "route1.default.svc", localDomain},
+		want:  []string{"route1.default.apps.example.com"},
+	}, {
+		name:     "reconstruct external host with custom template",
+		hosts:    []string{"route1.default.svc.cluster.local"},
+		template: "{{.Name}}-{{.Namespace}}.{{.Domain}}",
+		want:     []string{"route1-default.apps.example.com"},
+	}, {
+		name:  "external host matches template",
+		hosts: []string{"route1.default.svc.cluster.local", "Route1.default.apps.example.com."},
+		want:  []string{"route1.default.apps.example.com"},
+	}, {
+		name:      "external host doesn't match template",
+		hosts:     []string{"route1.default.svc.cluster.local", externalDomain},
+		want:      []string{externalHost},
+		wantWarns: 1,
+	}, {
+		name:  "external host of tag or domain mapping",
+		hosts: []string{externalDomain},
+		want:  []string{externalHost},
+	}, {
+		name:  "cluster-local host of another route",
+		hosts: []string{"tag-route1.default.svc.cluster.local"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts(test.hosts))))
+
+			warns := 0
+			routes, err := MakeRoutes(ing,
+				WithDomainTemplate(DomainTemplate{Template: test.template, Domain: "apps.example.com"}),
+				WithWarningFunc(func(reason, _ string) {
+					if reason == DomainTemplateMismatchReason {
+						warns++
+					}
+				}))
+			if err != nil {
+				t.Fatal("MakeRoutes() =", err)
+			}
+			var got []string
+			for _, route := range routes {
+				got = append(got, route.Spec.Host)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Hosts (-want, +got) = %s", cmp.Diff(test.want, got))
+			}
+			if warns != test.wantWarns {
+				t.Errorf("Got %d %s warnings, want %d", warns, DomainTemplateMismatchReason, test.wantWarns)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesWithoutDomainTemplate(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{localDomain}))), func(i *networkingv1alpha1.Ingress) {
+		i.Labels = map[string]string{}
+	})
+
+	routes, err := MakeRoutes(ing, WithDomainTemplate(DomainTemplate{Domain: "apps.example.com"}))
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	if len(routes) != 0 {
+		t.Errorf("Got %d routes for an Ingress without route labels, want none", len(routes))
+	}
+}
+
+func TestDomainTemplateValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template DomainTemplate
+		wantErr  bool
+	}{{
+		name:     "default template",
+		template: DomainTemplate{Domain: "apps.example.com"},
+	}, {
+		name:     "template using annotations",
+		template: DomainTemplate{Template: `{{.Name}}.{{index .Annotations "sub"}}.{{.Domain}}`, Domain: "apps.example.com"},
+	}, {
+		name:     "malformed template",
+		template: DomainTemplate{Template: "{{.Name", Domain: "apps.example.com"},
+		wantErr:  true,
+	}, {
+		name:     "unknown field",
+		template: DomainTemplate{Template: "{{.Tag}}.{{.Domain}}", Domain: "apps.example.com"},
+		wantErr:  true,
+	}, {
+		name:     "cluster-local domain",
+		template: DomainTemplate{Domain: "svc.cluster.local"},
+		wantErr:  true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.template.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, want error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package resources
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// MarshalRoutes serializes the given Routes into a multi-document YAML stream, suitable to be
+// committed to Git. The output is stable for the same input: Routes are sorted by namespace
+// and name, keys are sorted and all server-populated fields, like the status, are dropped.
+func MarshalRoutes(routes []*routev1.Route) ([]byte, error) {
+	sorted := make([]*routev1.Route, 0, len(routes))
+	for _, route := range routes {
+		sorted = append(sorted, exportable(route))
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Namespace != sorted[j].Namespace {
+			return sorted[i].Namespace < sorted[j].Namespace
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var buf bytes.Buffer
+	for _, route := range sorted {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(route)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert route %s/%s: %w", route.Namespace, route.Name, err)
+		}
+		// Zero values of structs are still serialized, so they have to be removed explicitly.
+		delete(obj, "status")
+		unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+
+		// Marshalling a map sorts all keys alphabetically.
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal route %s/%s: %w", route.Namespace, route.Name, err)
+		}
+		buf.WriteString("---\n")
+		buf.Write(out)
+	}
+	return buf.Bytes(), nil
+}
+
+// exportable returns a copy of the given Route, stripped of everything but the desired state.
+func exportable(route *routev1.Route) *routev1.Route {
+	return &routev1.Route{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: routev1.SchemeGroupVersion.String(),
+			Kind:       "Route",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        route.Name,
+			Namespace:   route.Namespace,
+			Labels:      route.Labels,
+			Annotations: route.Annotations,
+		},
+		Spec: *route.Spec.DeepCopy(),
+	}
+}
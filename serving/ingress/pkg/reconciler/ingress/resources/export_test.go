@@ -0,0 +1,60 @@
+package resources
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMarshalRoutes(t *testing.T) {
+	ing := ingress(withRules(
+		rule(withHosts([]string{externalDomain})),
+		rule(withHosts([]string{externalDomain2})),
+	))
+	ing.Annotations = map[string]string{"b": "2", "a": "1", "c": "3"}
+
+	routes, err := MakeRoutes(ing)
+	if err != nil {
+		t.Fatalf("MakeRoutes() = %v", err)
+	}
+	// Populate server-side fields, which must not be exported.
+	for _, route := range routes {
+		route.CreationTimestamp = metav1.NewTime(time.Now())
+		route.ResourceVersion = "42"
+		route.Status.Ingress = []routev1.RouteIngress{{Host: route.Spec.Host}}
+	}
+
+	first, err := MarshalRoutes(routes)
+	if err != nil {
+		t.Fatalf("MarshalRoutes() = %v", err)
+	}
+	// Reversing the input must not change the output.
+	reversed := []*routev1.Route{routes[1], routes[0]}
+	second, err := MarshalRoutes(reversed)
+	if err != nil {
+		t.Fatalf("MarshalRoutes() = %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("MarshalRoutes() not stable, got:\n%s\nand:\n%s", first, second)
+	}
+
+	out := string(first)
+	if got := strings.Count(out, "---\n"); got != 2 {
+		t.Errorf("got %d documents, want: 2", got)
+	}
+	for _, unwanted := range []string{"status", "creationTimestamp", "resourceVersion"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output contains %q:\n%s", unwanted, out)
+		}
+	}
+	if !strings.Contains(out, "    a: \"1\"\n    b: \"2\"\n    c: \"3\"\n") {
+		t.Errorf("annotations not sorted:\n%s", out)
+	}
+	if !strings.HasPrefix(out, "---\napiVersion: route.openshift.io/v1\nkind: Route\n") {
+		t.Errorf("output does not start with the type:\n%s", out)
+	}
+}
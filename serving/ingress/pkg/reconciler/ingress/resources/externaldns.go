@@ -0,0 +1,41 @@
+package resources
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	ExternalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+	ExternalDNSTTLAnnotation      = "external-dns.alpha.kubernetes.io/ttl"
+	ExternalDNSTargetAnnotation   = "external-dns.alpha.kubernetes.io/target"
+)
+
+// ExternalDNS configures the annotations external-dns uses to publish DNS records for Routes
+// of hosts outside of the cluster's apps domain.
+type ExternalDNS struct {
+	// TTL is the TTL of the published records. The external-dns default is used if zero.
+	TTL time.Duration
+
+	// Target overrides the target of the published records, which defaults to the router.
+	Target string
+}
+
+// annotations returns the external-dns annotations for a Route with the given host.
+func (e *ExternalDNS) annotations(host string) map[string]string {
+	if e == nil {
+		return nil
+	}
+
+	annotations := map[string]string{
+		ExternalDNSHostnameAnnotation: host,
+	}
+	if e.TTL > 0 {
+		// external-dns only accepts whole seconds.
+		annotations[ExternalDNSTTLAnnotation] = strconv.FormatInt(int64(e.TTL/time.Second), 10)
+	}
+	if e.Target != "" {
+		annotations[ExternalDNSTargetAnnotation] = e.Target
+	}
+	return annotations
+}
@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMakeRoutesExternalDNS(t *testing.T) {
+	const appsDomain = "apps.example.com"
+
+	tests := []struct {
+		name        string
+		host        string
+		externalDNS *ExternalDNS
+		want        map[string]string
+	}{{
+		name: "disabled",
+		host: "foo.vanity.com",
+	}, {
+		name:        "vanity domain",
+		host:        "foo.vanity.com",
+		externalDNS: &ExternalDNS{},
+		want: map[string]string{
+			ExternalDNSHostnameAnnotation: "foo.vanity.com",
+		},
+	}, {
+		name:        "vanity domain with ttl and target",
+		host:        "foo.vanity.com",
+		externalDNS: &ExternalDNS{TTL: 5 * time.Minute, Target: "lb.example.com"},
+		want: map[string]string{
+			ExternalDNSHostnameAnnotation: "foo.vanity.com",
+			ExternalDNSTTLAnnotation:      "300",
+			ExternalDNSTargetAnnotation:   "lb.example.com",
+		},
+	}, {
+		name:        "apps domain",
+		host:        "foo.default.apps.example.com",
+		externalDNS: &ExternalDNS{TTL: 5 * time.Minute},
+	}, {
+		name:        "domain only sharing a suffix with the apps domain",
+		host:        "foo.myapps.example.com",
+		externalDNS: &ExternalDNS{},
+		want: map[string]string{
+			ExternalDNSHostnameAnnotation: "foo.myapps.example.com",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{test.host}))))
+			opts := []Option{WithAppsDomain(appsDomain)}
+			if test.externalDNS != nil {
+				opts = append(opts, WithExternalDNS(*test.externalDNS))
+			}
+
+			routes, err := MakeRoutes(ing, opts...)
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			got := map[string]string{}
+			for _, key := range []string{ExternalDNSHostnameAnnotation, ExternalDNSTTLAnnotation, ExternalDNSTargetAnnotation} {
+				if value, ok := routes[0].Annotations[key]; ok {
+					got[key] = value
+				}
+			}
+			want := test.want
+			if want == nil {
+				want = map[string]string{}
+			}
+			if !cmp.Equal(got, want) {
+				t.Errorf("Annotations (-want, +got) = %s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package resources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/network"
+)
+
+// GatewayNamespaceMismatchError indicates that the gateway of an Ingress lives in a different
+// namespace than Routes have to be created in. Routes can only target Services of their own
+// namespace.
+type GatewayNamespaceMismatchError struct {
+	Gateway        types.NamespacedName
+	RouteNamespace string
+}
+
+func (e *GatewayNamespaceMismatchError) Error() string {
+	return fmt.Sprintf("gateway %s is not in namespace %q routes are created in", e.Gateway, e.RouteNamespace)
+}
+
+// ExternalNameServiceName returns the name of the ExternalName Service that points to the given
+// gateway from the namespace of the Routes.
+func ExternalNameServiceName(gateway types.NamespacedName) string {
+	return kmeta.ChildName(gateway.Namespace+"-"+gateway.Name, "-gateway")
+}
+
+// MakeExternalNameService creates an ExternalName Service in the given namespace, which points
+// to the given gateway Service. It serves the same ports, so Routes can target it by port name.
+func MakeExternalNameService(namespace string, gateway *corev1.Service) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ExternalNameServiceName(types.NamespacedName{Namespace: gateway.Namespace, Name: gateway.Name}),
+			Namespace: namespace,
+			Labels:    map[string]string{IngressProviderLabelKey: KourierIngressProvider},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: network.GetServiceHostname(gateway.Name, gateway.Namespace),
+			Ports:        gatewayPorts(gateway),
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestMakeRoutesRouteNamespace(t *testing.T) {
+	gateway := types.NamespacedName{Namespace: lbNamespace, Name: lbService}
+
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantService   string
+		wantNamespace string
+		wantErr       error
+	}{{
+		name:          "matching namespace",
+		opts:          []Option{WithRouteNamespace(lbNamespace)},
+		wantService:   lbService,
+		wantNamespace: lbNamespace,
+	}, {
+		name:    "mismatching namespace",
+		opts:    []Option{WithRouteNamespace("knative-serving-ingress")},
+		wantErr: &GatewayNamespaceMismatchError{Gateway: gateway, RouteNamespace: "knative-serving-ingress"},
+	}, {
+		name:          "mismatching namespace with indirection",
+		opts:          []Option{WithRouteNamespace("knative-serving-ingress"), WithExternalNameIndirection()},
+		wantService:   ExternalNameServiceName(gateway),
+		wantNamespace: "knative-serving-ingress",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routes, err := MakeRoutes(ingress(withRules(rule(withHosts([]string{externalDomain})))), test.opts...)
+			if test.wantErr != nil {
+				var mismatchErr *GatewayNamespaceMismatchError
+				if !errors.As(err, &mismatchErr) || !cmp.Equal(mismatchErr, test.wantErr) {
+					t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("MakeRoutes() =", err)
+			}
+			if got := routes[0].Spec.To.Name; got != test.wantService {
+				t.Errorf("To.Name = %q, want: %q", got, test.wantService)
+			}
+			if got := routes[0].Namespace; got != test.wantNamespace {
+				t.Errorf("Namespace = %q, want: %q", got, test.wantNamespace)
+			}
+			if got := routes[0].Spec.Port.TargetPort.StrVal; got != KourierHTTPPort {
+				t.Errorf("TargetPort = %q, want: %q", got, KourierHTTPPort)
+			}
+		})
+	}
+}
+
+func TestMakeExternalNameService(t *testing.T) {
+	gateway := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kourier",
+			Namespace: "kourier-system",
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "3scale-kourier-gateway"},
+			Ports: []corev1.ServicePort{{
+				Name:       "http2",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+
+	want := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kourier-system-kourier-gateway",
+			Namespace: "knative-serving-ingress",
+			Labels:    map[string]string{IngressProviderLabelKey: KourierIngressProvider},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: "kourier.kourier-system.svc.cluster.local",
+			Ports: []corev1.ServicePort{{
+				Name:       "http2",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+
+	got := MakeExternalNameService("knative-serving-ingress", gateway)
+	if !cmp.Equal(got, want) {
+		t.Errorf("MakeExternalNameService() (-want, +got) = %s", cmp.Diff(want, got))
+	}
+}
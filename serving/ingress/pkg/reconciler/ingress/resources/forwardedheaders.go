@@ -0,0 +1,34 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ForwardedHeadersAnnotation configures how the router handles the X-Forwarded-* and Forwarded
+// headers of requests to the Routes of an Ingress. See the values of forwardedHeadersPolicies.
+const ForwardedHeadersAnnotation = "serving.knative.openshift.io/forwardedHeaders"
+
+// setForwardedHeadersRouteAnnotation is the Route annotation of the router setting the policy.
+const setForwardedHeadersRouteAnnotation = "haproxy.router.openshift.io/set-forwarded-headers"
+
+// forwardedHeadersPolicies are the policies the router supports. "append" is the router's
+// default, "replace" drops headers set by the client, "never" leaves them untouched and
+// "if-none" only sets them if the client didn't.
+var forwardedHeadersPolicies = []string{"append", "replace", "never", "if-none"}
+
+// forwardedHeadersPolicy returns the policy requested by the given annotations, or an empty
+// string if none is.
+func forwardedHeadersPolicy(annotations map[string]string) (string, error) {
+	value, ok := annotations[ForwardedHeadersAnnotation]
+	if !ok {
+		return "", nil
+	}
+	for _, policy := range forwardedHeadersPolicies {
+		if value == policy {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("%w %s: value %q must be one of %s", ErrInvalidAnnotation, ForwardedHeadersAnnotation,
+		value, strings.Join(forwardedHeadersPolicies, ", "))
+}
@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMakeRoutesForwardedHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{{
+		name: "not set",
+	}, {
+		name:  "append",
+		value: "append",
+		want:  "append",
+	}, {
+		name:  "replace",
+		value: "replace",
+		want:  "replace",
+	}, {
+		name:  "never",
+		value: "never",
+		want:  "never",
+	}, {
+		name:  "if-none",
+		value: "if-none",
+		want:  "if-none",
+	}, {
+		name:    "invalid",
+		value:   "sometimes",
+		wantErr: ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{"foo.default.apps.example.com"}))))
+			if test.value != "" {
+				ing.Annotations = map[string]string{ForwardedHeadersAnnotation: test.value}
+			}
+
+			routes, err := MakeRoutes(ing)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			got, ok := routes[0].Annotations[setForwardedHeadersRouteAnnotation]
+			if ok != (test.want != "") || got != test.want {
+				t.Errorf("%s = %q, want: %q", setForwardedHeadersRouteAnnotation, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// GatewayPortSelectionAnnotation records on a Route how the port of the gateway Service it
+	// targets was selected, one of the PortSelectedBy values. It's only set if the gateway Service
+	// was looked up.
+	GatewayPortSelectionAnnotation = "serving.knative.openshift.io/gatewayPortSelection"
+
+	// PortSelectedByName means the gateway Service has a port of the configured name.
+	PortSelectedByName = "name"
+	// PortSelectedByProtocol means the name or appProtocol of the port indicates HTTP.
+	PortSelectedByProtocol = "protocol"
+	// PortSelectedByNumber means the port is the lowest numbered port not serving TLS.
+	PortSelectedByNumber = "number"
+)
+
+// httpProtocols are the names and appProtocols of ports indicating plain HTTP, including the
+// prefixes of port names Istio derives protocols from.
+var httpProtocols = []string{"http2", "h2c", "http", "kubernetes.io/h2c"}
+
+// NoGatewayPortError indicates that the gateway Service has no port Routes terminating TLS at the
+// router can target.
+type NoGatewayPortError struct {
+	Service string
+	Ports   []string
+}
+
+func (e *NoGatewayPortError) Error() string {
+	return fmt.Sprintf("gateway service %s has no HTTP port Routes can target, its ports are [%s]",
+		e.Service, strings.Join(e.Ports, ", "))
+}
+
+// GatewayHTTPPort returns the plain HTTP port of the given gateway Service that Routes terminating
+// TLS at the router target, and how it was selected. That's the port of the preferred name, or
+// else the first port whose name or appProtocol indicates HTTP, or else the lowest numbered port
+// not serving TLS. Unnamed ports are returned as the number of the port they target. It returns a
+// NoGatewayPortError if the Service has no such port.
+func GatewayHTTPPort(gateway *corev1.Service, preferred string) (string, string, error) {
+	for _, port := range gateway.Spec.Ports {
+		if port.Name == preferred {
+			return port.Name, PortSelectedByName, nil
+		}
+	}
+	for _, port := range gateway.Spec.Ports {
+		if indicatesHTTP(port) {
+			if target := portTarget(port); target != "" {
+				return target, PortSelectedByProtocol, nil
+			}
+		}
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(gateway.Spec.Ports))
+	for _, port := range gateway.Spec.Ports {
+		if !servesTLS(port) && (port.Protocol == "" || port.Protocol == corev1.ProtocolTCP) {
+			ports = append(ports, port)
+		}
+	}
+	sort.SliceStable(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+	for _, port := range ports {
+		if target := portTarget(port); target != "" {
+			return target, PortSelectedByNumber, nil
+		}
+	}
+
+	names := make([]string, 0, len(gateway.Spec.Ports))
+	for _, port := range gateway.Spec.Ports {
+		names = append(names, fmt.Sprintf("%s/%d", port.Name, port.Port))
+	}
+	return "", "", &NoGatewayPortError{Service: gateway.Namespace + "/" + gateway.Name, Ports: names}
+}
+
+// indicatesHTTP returns true if the name or appProtocol of the given port indicates plain HTTP.
+func indicatesHTTP(port corev1.ServicePort) bool {
+	for _, protocol := range httpProtocols {
+		if port.Name == protocol || strings.HasPrefix(port.Name, protocol+"-") {
+			return true
+		}
+		if port.AppProtocol != nil && *port.AppProtocol == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// servesTLS returns true if the name or appProtocol of the given port indicates TLS.
+func servesTLS(port corev1.ServicePort) bool {
+	for _, protocol := range []string{KourierHTTPSPort, "tls"} {
+		if port.Name == protocol || strings.HasPrefix(port.Name, protocol+"-") {
+			return true
+		}
+		if port.AppProtocol != nil && *port.AppProtocol == protocol {
+			return true
+		}
+	}
+	return port.Port == 443
+}
+
+// portTarget returns the target port of a Route reaching the given port. Routes refer to named
+// ports by name, and to unnamed ports by the number of the port of the pods. It returns an empty
+// string if the port can't be referred to.
+func portTarget(port corev1.ServicePort) string {
+	if port.Name != "" {
+		return port.Name
+	}
+	switch {
+	case port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal != 0:
+		return strconv.Itoa(int(port.TargetPort.IntVal))
+	case port.TargetPort.Type == intstr.String && port.TargetPort.StrVal != "":
+		// Unnamed ports targeting named ports of the pods can't be referred to.
+		return ""
+	default:
+		// The target port defaults to the port itself.
+		return strconv.Itoa(int(port.Port))
+	}
+}
@@ -0,0 +1,106 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/pkg/ptr"
+)
+
+func TestGatewayHTTPPort(t *testing.T) {
+	tests := []struct {
+		name          string
+		preferred     string
+		ports         []corev1.ServicePort
+		want          string
+		wantSelection string
+		wantPorts     []string
+	}{{
+		name:          "kourier gateway",
+		ports:         []corev1.ServicePort{{Name: KourierHTTPPort, Port: 80}, {Name: KourierHTTPSPort, Port: 443}},
+		want:          KourierHTTPPort,
+		wantSelection: PortSelectedByName,
+	}, {
+		name:          "configured name",
+		preferred:     "web",
+		ports:         []corev1.ServicePort{{Name: KourierHTTPPort, Port: 80}, {Name: "web", Port: 8080}},
+		want:          "web",
+		wantSelection: PortSelectedByName,
+	}, {
+		name:          "HTTPS port first",
+		ports:         []corev1.ServicePort{{Name: KourierHTTPSPort, Port: 443}, {Name: "http", Port: 80}},
+		want:          "http",
+		wantSelection: PortSelectedByProtocol,
+	}, {
+		name:          "istio port name",
+		ports:         []corev1.ServicePort{{Name: "status-port", Port: 15021}, {Name: "http2-gateway", Port: 80}},
+		want:          "http2-gateway",
+		wantSelection: PortSelectedByProtocol,
+	}, {
+		name: "app protocol",
+		ports: []corev1.ServicePort{
+			{Name: "metrics", Port: 9090},
+			{Name: "web", Port: 8080, AppProtocol: ptr.String("kubernetes.io/h2c")},
+		},
+		want:          "web",
+		wantSelection: PortSelectedByProtocol,
+	}, {
+		name:          "lowest port by number",
+		ports:         []corev1.ServicePort{{Name: "b", Port: 9090}, {Name: "tls", Port: 8443}, {Name: "a", Port: 8080}},
+		want:          "a",
+		wantSelection: PortSelectedByNumber,
+	}, {
+		name:          "unnamed port",
+		ports:         []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+		want:          "8080",
+		wantSelection: PortSelectedByNumber,
+	}, {
+		name:          "unnamed port without target port",
+		ports:         []corev1.ServicePort{{Port: 80}},
+		want:          "80",
+		wantSelection: PortSelectedByNumber,
+	}, {
+		name:      "only HTTPS",
+		ports:     []corev1.ServicePort{{Name: KourierHTTPSPort, Port: 443}},
+		wantPorts: []string{"https/443"},
+	}, {
+		name:      "only UDP",
+		ports:     []corev1.ServicePort{{Name: "dns", Port: 53, Protocol: corev1.ProtocolUDP}},
+		wantPorts: []string{"dns/53"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "gateway"},
+				Spec:       corev1.ServiceSpec{Ports: test.ports},
+			}
+			preferred := test.preferred
+			if preferred == "" {
+				preferred = KourierHTTPPort
+			}
+
+			got, selection, err := GatewayHTTPPort(svc, preferred)
+			if test.wantPorts != nil {
+				var portErr *NoGatewayPortError
+				if !errors.As(err, &portErr) {
+					t.Fatalf("GatewayHTTPPort() = %v, want a NoGatewayPortError", err)
+				}
+				if portErr.Service != "ns/gateway" || !cmp.Equal(portErr.Ports, test.wantPorts) {
+					t.Errorf("Error = %v, want service ns/gateway with ports %v", portErr, test.wantPorts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GatewayHTTPPort() = %v", err)
+			}
+			if got != test.want || selection != test.wantSelection {
+				t.Errorf("GatewayHTTPPort() = %q, %q, want: %q, %q", got, selection, test.want, test.wantSelection)
+			}
+		})
+	}
+}
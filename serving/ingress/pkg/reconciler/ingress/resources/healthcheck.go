@@ -0,0 +1,23 @@
+package resources
+
+import "fmt"
+
+// HealthCheckIntervalAnnotation sets how often the router probes the backends of a Route. It's
+// copied from the Ingress onto its Routes. Longer intervals keep probes from waking up the
+// activator of services scaled to zero.
+const HealthCheckIntervalAnnotation = "router.openshift.io/haproxy.health.check.interval"
+
+// applyHealthCheckInterval validates the health check interval of the given annotations, if
+// set, and renders it in a unit HAProxy supports.
+func applyHealthCheckInterval(annotations map[string]string) error {
+	value, ok := annotations[HealthCheckIntervalAnnotation]
+	if !ok {
+		return nil
+	}
+	interval, err := ParseTimeout(value)
+	if err != nil || interval == 0 {
+		return fmt.Errorf("%w %s: value %q must be a positive duration", ErrInvalidAnnotation, HealthCheckIntervalAnnotation, value)
+	}
+	annotations[HealthCheckIntervalAnnotation] = FormatTimeout(interval)
+	return nil
+}
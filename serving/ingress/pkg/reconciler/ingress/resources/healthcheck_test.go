@@ -0,0 +1,64 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMakeRoutesHealthCheckInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{{
+		name: "unset",
+	}, {
+		name:  "haproxy unit",
+		value: "5m",
+		want:  "5m",
+	}, {
+		name:  "go duration",
+		value: "1m30s",
+		want:  "90s",
+	}, {
+		name:  "seconds",
+		value: "30",
+		want:  "30s",
+	}, {
+		name:    "zero",
+		value:   "0s",
+		wantErr: true,
+	}, {
+		name:    "no duration",
+		value:   "often",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = map[string]string{}
+			if test.value != "" {
+				ing.Annotations[HealthCheckIntervalAnnotation] = test.value
+			}
+
+			routes, err := MakeRoutes(ing)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidAnnotation) {
+					t.Fatalf("MakeRoutes() = %v, want %v", err, ErrInvalidAnnotation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			for _, route := range routes {
+				got, ok := route.Annotations[HealthCheckIntervalAnnotation]
+				if ok != (test.value != "") || got != test.want {
+					t.Errorf("%s = %q (set: %v), want %q", HealthCheckIntervalAnnotation, got, ok, test.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+// LongLabelPolicy defines how hosts with labels longer than the router accepts are handled.
+type LongLabelPolicy string
+
+const (
+	// LongLabelFail fails generating Routes for hosts with too long labels.
+	LongLabelFail LongLabelPolicy = "fail"
+
+	// LongLabelHash replaces a too long first label with a truncated label suffixed with a
+	// hash of the original one. The Route is then reachable under that host instead.
+	LongLabelHash LongLabelPolicy = "hash"
+)
+
+// HostGeneratedAnnotation marks Routes whose host OpenShift generates as
+// <name>-<namespace>.<router domain>. The API server sets it as well when generating the host.
+const HostGeneratedAnnotation = "openshift.io/host.generated"
+
+// wildcardRouteLabel is the first label of the host of Routes serving a wildcard host like
+// "*.example.com". Hosts of Routes can't contain wildcards, Routes with the Subdomain wildcard
+// policy serve all hosts of the domain of their host instead.
+const wildcardRouteLabel = "wildcard"
+
+// hashedLabelSuffixLength is the number of characters of the hash appended to truncated labels.
+const hashedLabelSuffixLength = 8
+
+// HostLabelTooLongError indicates that a label of a host exceeds the maximum length of a DNS
+// label, which makes the router reject the Route.
+type HostLabelTooLongError struct {
+	Host  string
+	Label string
+}
+
+func (e *HostLabelTooLongError) Error() string {
+	return fmt.Sprintf("host %q: label %q is %d characters long, must be at most %d",
+		e.Host, e.Label, len(e.Label), validation.DNS1123LabelMaxLength)
+}
+
+// routeHost returns the host the Route for the given host should use, according to the given
+// policy for too long labels.
+func routeHost(host string, policy LongLabelPolicy) (string, error) {
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if len(label) <= validation.DNS1123LabelMaxLength {
+			continue
+		}
+		// Only the first label is derived from the Knative Service, all others are part of
+		// the domain and can't be changed.
+		if i != 0 || policy != LongLabelHash {
+			return "", &HostLabelTooLongError{Host: host, Label: label}
+		}
+		labels[0] = hashLabel(label)
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// hashLabel truncates the given label to the maximum length of a DNS label, keeping it unique
+// by suffixing it with a hash of the full label.
+func hashLabel(label string) string {
+	prefix := label[:validation.DNS1123LabelMaxLength-hashedLabelSuffixLength-1]
+	// A label must not end with a dash.
+	prefix = strings.TrimRight(prefix, "-")
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(label)))[:hashedLabelSuffixLength]
+	return prefix + "-" + hash
+}
+
+// replaceHostSuffix replaces the suffix of the given host as requested by the
+// HostSuffixAnnotation, which has the form "<from>=<to>", like "apps.internal=example.com".
+// Hosts not ending in <from> are returned unchanged.
+func replaceHostSuffix(host string, annotations map[string]string) (string, error) {
+	value, ok := annotations[HostSuffixAnnotation]
+	if !ok {
+		return host, nil
+	}
+
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("%w %s: value %q must be of the form <from>=<to>",
+			ErrInvalidAnnotation, HostSuffixAnnotation, value)
+	}
+	from, to := NormalizeHost(strings.TrimPrefix(parts[0], ".")), NormalizeHost(strings.TrimPrefix(parts[1], "."))
+	for _, suffix := range []string{from, to} {
+		if errs := validation.IsDNS1123Subdomain(suffix); len(errs) > 0 {
+			return "", fmt.Errorf("%w %s: suffix %q is not a valid domain: %s",
+				ErrInvalidAnnotation, HostSuffixAnnotation, suffix, strings.Join(errs, ", "))
+		}
+	}
+
+	// Only replace whole labels, "foo.bar.com" doesn't end in "ar.com".
+	if prefix := strings.TrimSuffix(host, "."+from); prefix != host {
+		return prefix + "." + to, nil
+	}
+	return host, nil
+}
+
+// InDomain returns true if the given host is below the given domain.
+func InDomain(host, domain string) bool {
+	domain = NormalizeHost(strings.TrimPrefix(domain, "."))
+	return domain != "" && strings.HasSuffix(host, "."+domain)
+}
+
+// generatedHostRouteName returns the name of the Route of the given Ingress whose host OpenShift
+// generates. The name is derived from the name of the Ingress, suffixed with a hash of its UID to
+// be unique across namespaces. It's truncated so that the generated first label of the host,
+// <name>-<namespace>, fits into a DNS label.
+func generatedHostRouteName(ci *networkingv1alpha1.Ingress, namespace string) string {
+	suffix := "-" + hashHost(string(ci.GetUID()))
+	max := validation.DNS1123LabelMaxLength - len(namespace) - len("-") - len(suffix)
+	name := ci.Name
+	if len(name) > max {
+		if max < 1 {
+			// The namespace is too long for any generated host. The router reports that.
+			max = 1
+		}
+		name = strings.TrimRight(name[:max], "-")
+	}
+	return name + suffix
+}
+
+// PreserveGeneratedHost copies the host OpenShift generated for the existing Route to the desired
+// one, so that updating the Route doesn't remove it again.
+func PreserveGeneratedHost(desired, existing *routev1.Route) {
+	if desired.Spec.Host == "" && desired.Annotations[HostGeneratedAnnotation] == "true" {
+		desired.Spec.Host = existing.Spec.Host
+	}
+}
+
+// wildcardRouteHost returns the host of the Route serving the given host and its wildcard
+// policy. Only wildcard hosts need the Subdomain policy. The router prefers Routes of specific
+// hosts over wildcard Routes, so both can be served by the same Ingress.
+func wildcardRouteHost(host string) (string, routev1.WildcardPolicyType) {
+	if !strings.HasPrefix(host, "*.") {
+		return host, routev1.WildcardPolicyNone
+	}
+	return wildcardRouteLabel + host[1:], routev1.WildcardPolicySubdomain
+}
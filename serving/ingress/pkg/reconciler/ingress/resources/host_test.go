@@ -0,0 +1,187 @@
+package resources
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestMakeRoutesLongLabel(t *testing.T) {
+	longLabel := strings.Repeat("a", 40) + "-" + strings.Repeat("b", 30)
+	longHost := longLabel + ".default.domainname"
+
+	tests := []struct {
+		name     string
+		host     string
+		policy   LongLabelPolicy
+		wantHost string
+		wantErr  *HostLabelTooLongError
+	}{{
+		name:     "short label",
+		host:     externalDomain,
+		wantHost: externalHost,
+	}, {
+		name:    "long first label fails by default",
+		host:    longHost,
+		wantErr: &HostLabelTooLongError{Host: longHost, Label: longLabel},
+	}, {
+		name:     "long first label is hashed",
+		host:     longHost,
+		policy:   LongLabelHash,
+		wantHost: strings.Repeat("a", 40) + "-" + strings.Repeat("b", 13) + "-0e567bf1.default.domainname",
+	}, {
+		name:    "long domain label can't be hashed",
+		host:    "foo." + longLabel + ".domainname",
+		policy:  LongLabelHash,
+		wantErr: &HostLabelTooLongError{Host: "foo." + longLabel + ".domainname", Label: longLabel},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{test.host}))))
+			routes, err := MakeRoutes(ing, WithLongLabelPolicy(test.policy))
+
+			var labelErr *HostLabelTooLongError
+			if test.wantErr != nil {
+				if !errors.As(err, &labelErr) || *labelErr != *test.wantErr {
+					t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			if got := routes[0].Spec.Host; got != test.wantHost {
+				t.Errorf("Host = %q, want: %q", got, test.wantHost)
+			}
+			if got := routes[0].Name; got != routeName(uid, NormalizeHost(test.host)) {
+				t.Errorf("Name = %q, want: %q", got, routeName(uid, NormalizeHost(test.host)))
+			}
+		})
+	}
+}
+
+func TestMakeRoutesWildcardAndSpecificHost(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{"*.example.com", "api.example.com"}))))
+
+	routes, err := MakeRoutes(ing, WithExternalDNS(ExternalDNS{}))
+	if err != nil {
+		t.Fatalf("MakeRoutes() = %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("len(routes) = %d, want 2", len(routes))
+	}
+
+	want := []struct {
+		host    string
+		policy  routev1.WildcardPolicyType
+		dnsName string
+	}{
+		{host: "wildcard.example.com", policy: routev1.WildcardPolicySubdomain, dnsName: "*.example.com"},
+		{host: "api.example.com", policy: routev1.WildcardPolicyNone, dnsName: "api.example.com"},
+	}
+	for i, route := range routes {
+		if route.Spec.Host != want[i].host {
+			t.Errorf("Host = %q, want %q", route.Spec.Host, want[i].host)
+		}
+		if errs := validation.IsDNS1123Subdomain(route.Spec.Host); len(errs) > 0 {
+			t.Errorf("Host %q is invalid: %v", route.Spec.Host, errs)
+		}
+		if route.Spec.WildcardPolicy != want[i].policy {
+			t.Errorf("WildcardPolicy = %q, want %q", route.Spec.WildcardPolicy, want[i].policy)
+		}
+		if got := route.Annotations[ExternalDNSHostnameAnnotation]; got != want[i].dnsName {
+			t.Errorf("%s = %q, want %q", ExternalDNSHostnameAnnotation, got, want[i].dnsName)
+		}
+	}
+	if routes[0].Name == routes[1].Name {
+		t.Errorf("Both routes are named %q", routes[0].Name)
+	}
+}
+
+func TestHashLabel(t *testing.T) {
+	// Truncating right after a dash must not produce a label ending in a dash.
+	label := strings.Repeat("a", 53) + "-" + strings.Repeat("b", 20)
+	got := hashLabel(label)
+	if len(got) > validation.DNS1123LabelMaxLength {
+		t.Errorf("len(%q) = %d, want at most %d", got, len(got), validation.DNS1123LabelMaxLength)
+	}
+	if errs := validation.IsDNS1123Label(got); len(errs) > 0 {
+		t.Errorf("hashLabel() = %q is not a valid label: %v", got, errs)
+	}
+}
+
+func TestHostLabelTooLongError(t *testing.T) {
+	err := &HostLabelTooLongError{Host: "aaa.example.com", Label: "aaa"}
+	want := `host "aaa.example.com": label "aaa" is 3 characters long, must be at most 63`
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want: %q", got, want)
+	}
+}
+
+func TestMakeRoutesHostSuffix(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		host       string
+		wantHost   string
+		wantErr    bool
+	}{{
+		name:       "matching suffix",
+		annotation: "apps.internal=example.com",
+		host:       "foo.bar.apps.internal",
+		wantHost:   "foo.bar.example.com",
+	}, {
+		name:       "matching suffix with leading dots",
+		annotation: ".apps.internal=.example.com",
+		host:       "foo.bar.apps.internal",
+		wantHost:   "foo.bar.example.com",
+	}, {
+		name:       "non-matching suffix",
+		annotation: "apps.internal=example.com",
+		host:       "foo.bar.apps.external",
+		wantHost:   "foo.bar.apps.external",
+	}, {
+		name:       "partial label",
+		annotation: "ps.internal=example.com",
+		host:       "foo.bar.apps.internal",
+		wantHost:   "foo.bar.apps.internal",
+	}, {
+		name:       "invalid suffix",
+		annotation: "apps.internal=example_com",
+		host:       "foo.bar.apps.internal",
+		wantErr:    true,
+	}, {
+		name:       "missing separator",
+		annotation: "apps.internal",
+		host:       "foo.bar.apps.internal",
+		wantErr:    true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{test.host}))))
+			ing.Annotations = map[string]string{HostSuffixAnnotation: test.annotation}
+
+			routes, err := MakeRoutes(ing)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidAnnotation) {
+					t.Fatalf("MakeRoutes() = %v, want: %v", err, ErrInvalidAnnotation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			if got := routes[0].Spec.Host; got != test.wantHost {
+				t.Errorf("Host = %q, want: %q", got, test.wantHost)
+			}
+			if got := routes[0].Name; got != routeName(uid, test.wantHost) {
+				t.Errorf("Name = %q, want: %q", got, routeName(uid, test.wantHost))
+			}
+		})
+	}
+}
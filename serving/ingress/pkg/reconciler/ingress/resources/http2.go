@@ -0,0 +1,32 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// HTTP2Annotation requests the Routes of an Ingress to be served over HTTP/2. It overrides the
+// cluster default set by WithHTTP2.
+//
+// HTTP/2 is enabled on the IngressController and the router only negotiates it for Routes with
+// a certificate of their own, as connections to the default certificate could be coalesced
+// across Routes. Routes that request HTTP/2 therefore get a certificate from cert-manager even
+// for hosts of the apps domain, which requires CertManagerIssuerAnnotation to be set.
+const HTTP2Annotation = "serving.knative.openshift.io/http2"
+
+// HTTP2UnavailableReason is the reason of warnings about Routes that request HTTP/2 but will
+// be served over HTTP/1.1 only.
+const HTTP2UnavailableReason = "HTTP2Unavailable"
+
+// http2Requested returns true if the given annotations or the cluster default request HTTP/2.
+func http2Requested(annotations map[string]string, clusterDefault bool) (bool, error) {
+	value, ok := annotations[HTTP2Annotation]
+	if !ok {
+		return clusterDefault, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w %s: value %q must be a boolean", ErrInvalidAnnotation, HTTP2Annotation, value)
+	}
+	return enabled, nil
+}
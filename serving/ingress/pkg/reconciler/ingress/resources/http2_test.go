@@ -0,0 +1,85 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMakeRoutesHTTP2(t *testing.T) {
+	const appsDomain = "apps.example.com"
+
+	tests := []struct {
+		name           string
+		host           string
+		annotations    map[string]string
+		clusterDefault bool
+		wantIssuer     string
+		wantWarning    bool
+		wantErr        error
+	}{{
+		name: "disabled",
+		host: "foo.default.apps.example.com",
+	}, {
+		name:        "requested by annotation",
+		host:        "foo.default.apps.example.com",
+		annotations: map[string]string{HTTP2Annotation: "true", CertManagerIssuerAnnotation: "letsencrypt"},
+		wantIssuer:  "letsencrypt",
+	}, {
+		name:           "cluster default",
+		host:           "foo.default.apps.example.com",
+		annotations:    map[string]string{CertManagerIssuerAnnotation: "letsencrypt"},
+		clusterDefault: true,
+		wantIssuer:     "letsencrypt",
+	}, {
+		name:           "cluster default disabled by annotation",
+		host:           "foo.default.apps.example.com",
+		annotations:    map[string]string{HTTP2Annotation: "false", CertManagerIssuerAnnotation: "letsencrypt"},
+		clusterDefault: true,
+	}, {
+		name:        "default certificate",
+		host:        "foo.default.apps.example.com",
+		annotations: map[string]string{HTTP2Annotation: "true"},
+		wantWarning: true,
+	}, {
+		name:        "custom domain without certificate",
+		host:        "foo.vanity.com",
+		annotations: map[string]string{HTTP2Annotation: "true"},
+		wantWarning: true,
+	}, {
+		name:        "invalid annotation",
+		host:        "foo.default.apps.example.com",
+		annotations: map[string]string{HTTP2Annotation: "sure"},
+		wantErr:     ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{test.host}))))
+			ing.Annotations = test.annotations
+
+			var warnings []string
+			routes, err := MakeRoutes(ing,
+				WithAppsDomain(appsDomain),
+				WithHTTP2(test.clusterDefault),
+				WithWarningFunc(func(reason, _ string) { warnings = append(warnings, reason) }))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+
+			if got := routes[0].Annotations[certManagerIssuerNameRouteAnnotation]; got != test.wantIssuer {
+				t.Errorf("Issuer = %q, want: %q", got, test.wantIssuer)
+			}
+			if got := len(warnings) > 0; got != test.wantWarning {
+				t.Errorf("Warnings = %v, want warning: %v", warnings, test.wantWarning)
+			}
+			for _, reason := range warnings {
+				if reason != HTTP2UnavailableReason {
+					t.Errorf("Warning reason = %q, want: %q", reason, HTTP2UnavailableReason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package resources
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const (
+	// IPWhitelistConfigMapAnnotation references the key of a ConfigMap holding the source IPs
+	// and CIDRs allowed to reach the Routes of an Ingress, as "<name>/<key>". The ConfigMap must
+	// be in the namespace of the Ingress. Entries are separated by whitespace or commas, and lines
+	// starting with "#" are ignored.
+	IPWhitelistConfigMapAnnotation = "serving.knative.openshift.io/ipWhitelistConfigMap"
+
+	// IPWhitelistRouteAnnotation restricts the source addresses the router accepts for a Route.
+	IPWhitelistRouteAnnotation = "haproxy.router.openshift.io/ip_whitelist"
+)
+
+// MissingIPWhitelistError indicates that the ConfigMap or key referenced by the
+// IPWhitelistConfigMapAnnotation doesn't exist. It may be created later.
+type MissingIPWhitelistError struct {
+	ConfigMap string
+	Key       string
+}
+
+func (e *MissingIPWhitelistError) Error() string {
+	return fmt.Sprintf("IP whitelist %q of configmap %s doesn't exist", e.Key, e.ConfigMap)
+}
+
+// InvalidIPWhitelistError indicates that an entry of the referenced IP whitelist is neither an IP
+// nor a CIDR.
+type InvalidIPWhitelistError struct {
+	ConfigMap string
+	Key       string
+	Entry     string
+}
+
+func (e *InvalidIPWhitelistError) Error() string {
+	return fmt.Sprintf("IP whitelist %q of configmap %s contains %q, which is neither an IP nor a CIDR", e.Key, e.ConfigMap, e.Entry)
+}
+
+// ipWhitelist returns the IP whitelist the given Ingress references, formatted as the value of the
+// IPWhitelistRouteAnnotation. It returns an empty string if the Ingress references none, or if
+// ConfigMaps aren't looked up.
+func ipWhitelist(ci *networkingv1alpha1.Ingress, o *options) (string, error) {
+	ref, ok := ci.GetAnnotations()[IPWhitelistConfigMapAnnotation]
+	if !ok || o.configMapFunc == nil {
+		return "", nil
+	}
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("%w %s: value %q must be of the form <name>/<key>", ErrInvalidAnnotation, IPWhitelistConfigMapAnnotation, ref)
+	}
+	name, key := parts[0], parts[1]
+	configMap := ci.Namespace + "/" + name
+
+	data, found := o.configMapFunc(ci.Namespace, name)
+	value, ok := data[key]
+	if !found || !ok {
+		return "", &MissingIPWhitelistError{ConfigMap: configMap, Key: key}
+	}
+
+	var entries []string
+	for _, line := range strings.Split(value, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, entry := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		}) {
+			if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+				return "", &InvalidIPWhitelistError{ConfigMap: configMap, Key: key, Entry: entry}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return strings.Join(entries, " "), nil
+}
+
+// applyIPWhitelist restricts the source addresses of the given Routes to the given whitelist. The
+// whitelist referenced by a ConfigMap takes precedence over one set on the Ingress directly.
+func applyIPWhitelist(routes []*routev1.Route, whitelist string) {
+	if whitelist == "" {
+		return
+	}
+	for _, route := range routes {
+		route.Annotations[IPWhitelistRouteAnnotation] = whitelist
+	}
+}
@@ -0,0 +1,114 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMakeRoutesIPWhitelist(t *testing.T) {
+	configMaps := map[string]map[string]string{
+		"allowlists": {
+			"office":    "# Office networks\n10.0.0.0/8, 192.168.1.1\n  # VPN\n172.16.0.0/12",
+			"malformed": "10.0.0.0/8 not-an-ip",
+		},
+	}
+	configMapFunc := WithConfigMapFunc(func(namespace, name string) (map[string]string, bool) {
+		if namespace != "default" {
+			t.Errorf("Looked up configmap in namespace %q, want the namespace of the ingress", namespace)
+		}
+		data, ok := configMaps[name]
+		return data, ok
+	})
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		opts        []Option
+		want        string
+		wantMissing bool
+		wantInvalid string
+		wantErr     error
+	}{{
+		name:        "configmap present",
+		annotations: map[string]string{IPWhitelistConfigMapAnnotation: "allowlists/office"},
+		opts:        []Option{configMapFunc},
+		want:        "10.0.0.0/8 192.168.1.1 172.16.0.0/12",
+	}, {
+		name: "configmap takes precedence",
+		annotations: map[string]string{
+			IPWhitelistConfigMapAnnotation: "allowlists/office",
+			IPWhitelistRouteAnnotation:     "0.0.0.0/0",
+		},
+		opts: []Option{configMapFunc},
+		want: "10.0.0.0/8 192.168.1.1 172.16.0.0/12",
+	}, {
+		name:        "configmap missing",
+		annotations: map[string]string{IPWhitelistConfigMapAnnotation: "unknown/office"},
+		opts:        []Option{configMapFunc},
+		wantMissing: true,
+	}, {
+		name:        "key missing",
+		annotations: map[string]string{IPWhitelistConfigMapAnnotation: "allowlists/unknown"},
+		opts:        []Option{configMapFunc},
+		wantMissing: true,
+	}, {
+		name:        "malformed CIDR",
+		annotations: map[string]string{IPWhitelistConfigMapAnnotation: "allowlists/malformed"},
+		opts:        []Option{configMapFunc},
+		wantInvalid: "not-an-ip",
+	}, {
+		name:        "malformed reference",
+		annotations: map[string]string{IPWhitelistConfigMapAnnotation: "allowlists"},
+		opts:        []Option{configMapFunc},
+		wantErr:     ErrInvalidAnnotation,
+	}, {
+		name:        "configmaps not looked up",
+		annotations: map[string]string{IPWhitelistConfigMapAnnotation: "allowlists/office"},
+	}, {
+		name: "no reference",
+		opts: []Option{configMapFunc},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = test.annotations
+
+			routes, err := MakeRoutes(ing, test.opts...)
+			var missingErr *MissingIPWhitelistError
+			var invalidErr *InvalidIPWhitelistError
+			switch {
+			case test.wantMissing:
+				if !errors.As(err, &missingErr) {
+					t.Fatalf("MakeRoutes() = %v, want a MissingIPWhitelistError", err)
+				}
+				return
+			case test.wantInvalid != "":
+				if !errors.As(err, &invalidErr) {
+					t.Fatalf("MakeRoutes() = %v, want an InvalidIPWhitelistError", err)
+				}
+				if invalidErr.Entry != test.wantInvalid {
+					t.Errorf("Entry = %q, want: %q", invalidErr.Entry, test.wantInvalid)
+				}
+				return
+			case test.wantErr != nil:
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+				}
+				return
+			case err != nil:
+				t.Fatal("MakeRoutes() =", err)
+			}
+
+			got := routes[0].Annotations[IPWhitelistRouteAnnotation]
+			if test.want == "" {
+				test.want = test.annotations[IPWhitelistRouteAnnotation]
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Whitelist = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
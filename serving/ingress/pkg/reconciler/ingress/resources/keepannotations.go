@@ -0,0 +1,35 @@
+package resources
+
+import (
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// DefaultKeepAnnotationPrefixes are the prefixes of the annotations OpenShift itself sets on
+// Routes, which are kept by default.
+var DefaultKeepAnnotationPrefixes = []string{"openshift.io/"}
+
+// PreserveAnnotations copies the annotations of the existing Route matching one of the given
+// prefixes to the desired one, so that updating the Route neither overwrites nor removes them.
+// Others than the controller, like the router or admins, own these annotations.
+func PreserveAnnotations(desired, existing *routev1.Route, prefixes []string) {
+	for key, value := range existing.Annotations {
+		if !hasAnyPrefix(key, prefixes) {
+			continue
+		}
+		if desired.Annotations == nil {
+			desired.Annotations = make(map[string]string, len(existing.Annotations))
+		}
+		desired.Annotations[key] = value
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
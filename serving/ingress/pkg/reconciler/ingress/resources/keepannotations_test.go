@@ -0,0 +1,61 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreserveAnnotations(t *testing.T) {
+	annotated := func(annotations map[string]string) *routev1.Route {
+		return &routev1.Route{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name     string
+		prefixes []string
+		existing map[string]string
+		desired  map[string]string
+		want     map[string]string
+	}{{
+		name:     "kept annotations survive",
+		prefixes: DefaultKeepAnnotationPrefixes,
+		existing: map[string]string{"openshift.io/admin-note": "keep", TimeoutAnnotation: "5s"},
+		desired:  map[string]string{TimeoutAnnotation: "10s"},
+		want:     map[string]string{"openshift.io/admin-note": "keep", TimeoutAnnotation: "10s"},
+	}, {
+		name:     "kept annotations aren't overwritten",
+		prefixes: []string{"example.com/"},
+		existing: map[string]string{"example.com/owner": "admin"},
+		desired:  map[string]string{"example.com/owner": "generated"},
+		want:     map[string]string{"example.com/owner": "admin"},
+	}, {
+		name:     "operator annotations are updated and removed",
+		prefixes: DefaultKeepAnnotationPrefixes,
+		existing: map[string]string{TimeoutAnnotation: "5s", HTTP2Annotation: "true"},
+		desired:  map[string]string{TimeoutAnnotation: "10s"},
+		want:     map[string]string{TimeoutAnnotation: "10s"},
+	}, {
+		name:     "router annotations don't match the default prefix",
+		prefixes: DefaultKeepAnnotationPrefixes,
+		existing: map[string]string{TimeoutAnnotation: "5s"},
+		desired:  map[string]string{},
+		want:     map[string]string{},
+	}, {
+		name:     "no prefixes",
+		existing: map[string]string{"openshift.io/admin-note": "keep"},
+		want:     nil,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desired := annotated(test.desired)
+			PreserveAnnotations(desired, annotated(test.existing), test.prefixes)
+			if !cmp.Equal(desired.Annotations, test.want) {
+				t.Errorf("Annotations (-want, +got) = %s", cmp.Diff(test.want, desired.Annotations))
+			}
+		})
+	}
+}
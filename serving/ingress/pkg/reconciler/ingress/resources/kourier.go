@@ -0,0 +1,51 @@
+package resources
+
+import "strings"
+
+const (
+	// IngressProviderLabelKey is the label Kourier puts on its gateway Services. The Services
+	// created for the gateways carry it as well, so only Services with it have to be watched.
+	IngressProviderLabelKey = "networking.knative.dev/ingress-provider"
+
+	// KourierIngressProvider is the value of IngressProviderLabelKey on the Services of Kourier.
+	KourierIngressProvider = "kourier"
+)
+
+// DefaultKourierSelector matches the gateways of the internal Kourier instance deployed by
+// Knative Serving.
+var DefaultKourierSelector = KourierSelector{InternalServicePrefix: "kourier-internal"}
+
+// DefaultLocalGatewayNames are the names of the cluster-local gateway Services of net-istio.
+// They're listed next to the public gateway in the LoadBalancer status of some Ingresses, but
+// refuse external hosts.
+var DefaultLocalGatewayNames = []string{"knative-local-gateway", "cluster-local-gateway"}
+
+// KourierSelector tells the gateways of internal and public Kourier instances apart, based
+// on the names of their Services.
+type KourierSelector struct {
+	// InternalServicePrefix is the name prefix of the Services of internal Kourier instances.
+	// If empty, only the local gateways are considered internal.
+	InternalServicePrefix string
+
+	// LocalGatewayNames are the names of the Services of other cluster-local gateways. Nil if
+	// unset, which uses DefaultLocalGatewayNames.
+	LocalGatewayNames []string
+}
+
+// IsInternal returns true if the gateway Service with the given name belongs to an internal
+// Kourier instance or is a local gateway.
+func (s KourierSelector) IsInternal(serviceName string) bool {
+	if s.InternalServicePrefix != "" && strings.HasPrefix(serviceName, s.InternalServicePrefix) {
+		return true
+	}
+	names := s.LocalGatewayNames
+	if names == nil {
+		names = DefaultLocalGatewayNames
+	}
+	for _, name := range names {
+		if serviceName == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"testing"
+
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestMakeRoutesKourierSelector(t *testing.T) {
+	const (
+		public   = "kourier.knative-serving-ingress.svc.cluster.local"
+		internal = "kourier-internal.knative-serving-ingress.svc.cluster.local"
+		istio    = "istio-ingressgateway.istio-system.svc.cluster.local"
+		local    = "knative-local-gateway.istio-system.svc.cluster.local"
+	)
+
+	tests := []struct {
+		name     string
+		domains  []string
+		selector *KourierSelector
+		want     string
+	}{{
+		name:    "public only",
+		domains: []string{public},
+		want:    "kourier",
+	}, {
+		name:    "internal only",
+		domains: []string{internal},
+		want:    "kourier-internal",
+	}, {
+		name:    "public first",
+		domains: []string{public, internal},
+		want:    "kourier",
+	}, {
+		name:    "internal first",
+		domains: []string{internal, public},
+		want:    "kourier",
+	}, {
+		name:     "custom selector",
+		domains:  []string{"private.knative-serving-ingress.svc.cluster.local", public},
+		selector: &KourierSelector{InternalServicePrefix: "kourier"},
+		want:     "private",
+	}, {
+		name:     "no internal gateways",
+		domains:  []string{public, internal},
+		selector: &KourierSelector{},
+		want:     "kourier-internal",
+	}, {
+		name:    "istio public first",
+		domains: []string{istio, local},
+		want:    "istio-ingressgateway",
+	}, {
+		name:    "istio local first",
+		domains: []string{local, istio},
+		want:    "istio-ingressgateway",
+	}, {
+		name:    "local only",
+		domains: []string{local},
+		want:    "knative-local-gateway",
+	}, {
+		name:     "custom local gateways",
+		domains:  []string{"private.knative-serving-ingress.svc.cluster.local", local},
+		selector: &KourierSelector{LocalGatewayNames: []string{"private"}},
+		want:     "knative-local-gateway",
+	}, {
+		name:     "no local gateways",
+		domains:  []string{public, local},
+		selector: &KourierSelector{LocalGatewayNames: []string{}},
+		want:     "knative-local-gateway",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Status.PublicLoadBalancer.Ingress = nil
+			for _, domain := range test.domains {
+				ing.Status.PublicLoadBalancer.Ingress = append(ing.Status.PublicLoadBalancer.Ingress,
+					networkingv1alpha1.LoadBalancerIngressStatus{DomainInternal: domain})
+			}
+
+			opts := []Option{}
+			if test.selector != nil {
+				opts = append(opts, WithKourierSelector(*test.selector))
+			}
+			routes, err := MakeRoutes(ing, opts...)
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			if got := routes[0].Spec.To.Name; got != test.want {
+				t.Errorf("To.Name = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package resources
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+const (
+	// ExposureLabelKey marks the LoadBalancer Services created to expose Kourier gateways.
+	ExposureLabelKey = "serving.knative.openshift.io/exposure"
+
+	// ExposureLoadBalancerValue is the value of ExposureLabelKey on LoadBalancer Services.
+	ExposureLoadBalancerValue = "loadbalancer"
+
+	loadBalancerSuffix = "-lb"
+)
+
+// Gateway returns the Kourier gateway Service the given Ingress is served by.
+func Gateway(ci *networkingv1alpha1.Ingress, opts ...Option) (types.NamespacedName, error) {
+	name, namespace, err := gatewayService(ci, newOptions(opts))
+	if err != nil {
+		return types.NamespacedName{}, err
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+// MakeLoadBalancerService creates a LoadBalancer Service exposing the given Kourier gateway
+// Service outside of the cluster. It selects the same pods and serves the same ports.
+func MakeLoadBalancerService(gateway *corev1.Service) *corev1.Service {
+	selector := make(map[string]string, len(gateway.Spec.Selector))
+	for k, v := range gateway.Spec.Selector {
+		selector[k] = v
+	}
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      gateway.Name + loadBalancerSuffix,
+			Namespace: gateway.Namespace,
+			Labels: map[string]string{
+				IngressProviderLabelKey: KourierIngressProvider,
+				ExposureLabelKey:        ExposureLoadBalancerValue,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: selector,
+			Ports:    gatewayPorts(gateway),
+		},
+	}
+}
+
+// LoadBalancerGateway returns the gateway Service the given LoadBalancer Service exposes.
+func LoadBalancerGateway(svc *corev1.Service) types.NamespacedName {
+	return types.NamespacedName{Namespace: svc.Namespace, Name: strings.TrimSuffix(svc.Name, loadBalancerSuffix)}
+}
+
+// LoadBalancerAddress returns the external IP and hostname assigned to the given LoadBalancer
+// Service. Both are empty until the cloud provider provisioned the load balancer.
+func LoadBalancerAddress(svc *corev1.Service) (ip, hostname string) {
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ip == "" {
+			ip = ingress.IP
+		}
+		if hostname == "" {
+			hostname = ingress.Hostname
+		}
+	}
+	return ip, hostname
+}
+
+// gatewayPorts returns the ports of the given gateway Service, without the node ports allocated
+// to it.
+func gatewayPorts(gateway *corev1.Service) []corev1.ServicePort {
+	ports := make([]corev1.ServicePort, 0, len(gateway.Spec.Ports))
+	for _, port := range gateway.Spec.Ports {
+		ports = append(ports, corev1.ServicePort{
+			Name:       port.Name,
+			Protocol:   port.Protocol,
+			Port:       port.Port,
+			TargetPort: port.TargetPort,
+		})
+	}
+	return ports
+}
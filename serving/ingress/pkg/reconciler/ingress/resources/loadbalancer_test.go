@@ -0,0 +1,109 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGateway(t *testing.T) {
+	got, err := Gateway(ingress(withRules(rule(withHosts([]string{externalDomain})))))
+	if err != nil {
+		t.Fatal("Gateway() =", err)
+	}
+	if want := (types.NamespacedName{Namespace: lbNamespace, Name: lbService}); got != want {
+		t.Errorf("Gateway() = %v, want: %v", got, want)
+	}
+
+	if _, err := Gateway(ingress(withoutLBStatus)); err != ErrNoValidLoadbalancerDomain {
+		t.Errorf("Gateway() = %v, want: %v", err, ErrNoValidLoadbalancerDomain)
+	}
+}
+
+func TestMakeLoadBalancerService(t *testing.T) {
+	gateway := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kourier",
+			Namespace: "knative-serving-ingress",
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeNodePort,
+			Selector: map[string]string{"app": "3scale-kourier-gateway"},
+			Ports: []corev1.ServicePort{{
+				Name:       "http2",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+				NodePort:   31080,
+			}},
+		},
+	}
+
+	want := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kourier-lb",
+			Namespace: "knative-serving-ingress",
+			Labels: map[string]string{
+				IngressProviderLabelKey: KourierIngressProvider,
+				ExposureLabelKey:        ExposureLoadBalancerValue,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: map[string]string{"app": "3scale-kourier-gateway"},
+			Ports: []corev1.ServicePort{{
+				Name:       "http2",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       80,
+				TargetPort: intstr.FromInt(8080),
+			}},
+		},
+	}
+
+	got := MakeLoadBalancerService(gateway)
+	if !cmp.Equal(got, want) {
+		t.Errorf("MakeLoadBalancerService() (-want, +got) = %s", cmp.Diff(want, got))
+	}
+	if gw := LoadBalancerGateway(got); gw != (types.NamespacedName{Namespace: "knative-serving-ingress", Name: "kourier"}) {
+		t.Errorf("LoadBalancerGateway() = %v, want the exposed gateway", gw)
+	}
+}
+
+func TestLoadBalancerAddress(t *testing.T) {
+	tests := []struct {
+		name         string
+		ingress      []corev1.LoadBalancerIngress
+		wantIP       string
+		wantHostname string
+	}{{
+		name: "pending",
+	}, {
+		name:    "ip",
+		ingress: []corev1.LoadBalancerIngress{{IP: "192.0.2.1"}},
+		wantIP:  "192.0.2.1",
+	}, {
+		name:         "hostname",
+		ingress:      []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+		wantHostname: "lb.example.com",
+	}, {
+		name:         "both",
+		ingress:      []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}, {IP: "192.0.2.1"}, {IP: "192.0.2.2"}},
+		wantIP:       "192.0.2.1",
+		wantHostname: "lb.example.com",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &corev1.Service{}
+			svc.Status.LoadBalancer.Ingress = test.ingress
+			ip, hostname := LoadBalancerAddress(svc)
+			if ip != test.wantIP || hostname != test.wantHostname {
+				t.Errorf("LoadBalancerAddress() = %q, %q, want: %q, %q", ip, hostname, test.wantIP, test.wantHostname)
+			}
+		})
+	}
+}
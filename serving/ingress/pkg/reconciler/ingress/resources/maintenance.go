@@ -0,0 +1,57 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	routev1 "github.com/openshift/api/route/v1"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/ptr"
+)
+
+// MaintenanceAnnotation takes the Routes of an Ingress out of rotation without deleting them, so
+// their hosts keep their DNS records. Requests are answered by the maintenance Service if one
+// is configured, see WithMaintenanceService, or else with 503 by the router.
+const MaintenanceAnnotation = "serving.knative.openshift.io/maintenance"
+
+// maintenance returns whether the given annotations request maintenance.
+func maintenance(annotations map[string]string) (bool, error) {
+	value, ok := annotations[MaintenanceAnnotation]
+	if !ok {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w %s: value %q must be a boolean", ErrInvalidAnnotation, MaintenanceAnnotation, value)
+	}
+	return enabled, nil
+}
+
+// InMaintenance returns whether the Routes of the given Ingress are out of rotation. Invalid
+// values of the MaintenanceAnnotation fail generating the Routes, so they're not in maintenance.
+func InMaintenance(ci *networkingv1alpha1.Ingress) bool {
+	enabled, _ := maintenance(ci.GetAnnotations())
+	return enabled
+}
+
+// applyMaintenance takes the given Route out of rotation. Routes terminating TLS at the router
+// target the given maintenance Service, which serves plain HTTP. Other Routes, or all if no
+// maintenance Service is given, keep their backends at a weight of zero, which the router
+// answers with 503.
+func applyMaintenance(route *routev1.Route, service string) {
+	if service != "" && route.Spec.TLS != nil && route.Spec.TLS.Termination == routev1.TLSTerminationEdge {
+		route.Spec.To = routev1.RouteTargetReference{
+			Kind:   "Service",
+			Name:   service,
+			Weight: ptr.Int32(100),
+		}
+		route.Spec.AlternateBackends = nil
+		// The router serves any port of the maintenance Service.
+		route.Spec.Port = nil
+		return
+	}
+	route.Spec.To.Weight = ptr.Int32(0)
+	for i := range route.Spec.AlternateBackends {
+		route.Spec.AlternateBackends[i].Weight = ptr.Int32(0)
+	}
+}
@@ -0,0 +1,90 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestMakeRoutesMaintenance(t *testing.T) {
+	migration := WithGatewayMigration(GatewayMigration{
+		From:   types.NamespacedName{Namespace: lbNamespace, Name: lbService},
+		To:     types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"},
+		Weight: 25,
+	})
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		opts        []Option
+		wantTarget  string
+		wantWeights []int32
+		wantErr     error
+	}{{
+		name:        "maintenance service",
+		annotations: map[string]string{MaintenanceAnnotation: "true"},
+		opts:        []Option{WithMaintenanceService("maintenance"), migration},
+		wantTarget:  "maintenance",
+		wantWeights: []int32{100},
+	}, {
+		name:        "no maintenance service",
+		annotations: map[string]string{MaintenanceAnnotation: "true"},
+		opts:        []Option{migration},
+		wantTarget:  lbService,
+		wantWeights: []int32{0, 0},
+	}, {
+		// The maintenance Service serves plain HTTP only.
+		name: "passthrough",
+		annotations: map[string]string{
+			MaintenanceAnnotation:    "true",
+			TerminationAnnotation:    "passthrough",
+			InsecurePolicyAnnotation: "Redirect",
+		},
+		opts:        []Option{WithMaintenanceService("maintenance")},
+		wantTarget:  lbService,
+		wantWeights: []int32{0},
+	}, {
+		name:        "maintenance over",
+		annotations: map[string]string{MaintenanceAnnotation: "false"},
+		opts:        []Option{WithMaintenanceService("maintenance"), migration},
+		wantTarget:  lbService,
+		wantWeights: []int32{75, 25},
+	}, {
+		name:        "invalid",
+		annotations: map[string]string{MaintenanceAnnotation: "soon"},
+		wantErr:     ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = test.annotations
+
+			routes, err := MakeRoutes(ing, test.opts...)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			route := routes[0]
+			if route.Spec.To.Name != test.wantTarget {
+				t.Errorf("To = %q, want: %q", route.Spec.To.Name, test.wantTarget)
+			}
+			backends := append([]routev1.RouteTargetReference{route.Spec.To}, route.Spec.AlternateBackends...)
+			if len(backends) != len(test.wantWeights) {
+				t.Fatalf("Got %d backends, want: %d", len(backends), len(test.wantWeights))
+			}
+			for i, backend := range backends {
+				if *backend.Weight != test.wantWeights[i] {
+					t.Errorf("Weight of %s = %d, want: %d", backend.Name, *backend.Weight, test.wantWeights[i])
+				}
+			}
+			if got := route.Spec.Port == nil; got != (test.wantTarget == "maintenance") {
+				t.Errorf("Port = %v, want it unset only for the maintenance service", route.Spec.Port)
+			}
+		})
+	}
+}
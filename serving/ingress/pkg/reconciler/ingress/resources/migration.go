@@ -0,0 +1,48 @@
+package resources
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// GatewayMigration shifts traffic of Routes from one gateway Service to another gradually, for
+// example when migrating from Kourier to another ingress implementation. Routes targeting From
+// send Weight percent of their traffic to To instead.
+//
+// Routes can only target Services of their own namespace, so both gateways have to live in the
+// same namespace. Both also have to serve the port Routes target.
+type GatewayMigration struct {
+	From   types.NamespacedName
+	To     types.NamespacedName
+	Weight int
+}
+
+// Validate returns an error if the migration can't be applied to Routes.
+func (m *GatewayMigration) Validate() error {
+	if m.From.Name == "" || m.To.Name == "" {
+		return fmt.Errorf("both gateways of the migration must be set, got %q and %q", m.From, m.To)
+	}
+	if m.From.Namespace != m.To.Namespace {
+		return fmt.Errorf("gateways %s and %s must be in the same namespace", m.From, m.To)
+	}
+	if m.From == m.To {
+		return fmt.Errorf("gateways of the migration must differ, got %s twice", m.From)
+	}
+	if m.Weight < 0 || m.Weight > 100 {
+		return fmt.Errorf("weight must be between 0 and 100, got %d", m.Weight)
+	}
+	return nil
+}
+
+// backends returns the backends of a Route to the given gateway Service. Gateways other than
+// the one being migrated from keep receiving all traffic.
+func (m *GatewayMigration) backends(namespace, name string) []backend {
+	if m == nil || m.From != (types.NamespacedName{Namespace: namespace, Name: name}) {
+		return []backend{{name: name, percent: 100}}
+	}
+	return []backend{
+		{name: m.From.Name, percent: 100 - m.Weight},
+		{name: m.To.Name, percent: m.Weight},
+	}
+}
@@ -0,0 +1,127 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/ptr"
+)
+
+func TestGatewayMigrationValidate(t *testing.T) {
+	from := types.NamespacedName{Namespace: lbNamespace, Name: lbService}
+	to := types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"}
+
+	tests := []struct {
+		name      string
+		migration GatewayMigration
+		wantErr   bool
+	}{{
+		name:      "valid",
+		migration: GatewayMigration{From: from, To: to, Weight: 10},
+	}, {
+		name:      "missing gateway",
+		migration: GatewayMigration{From: from, Weight: 10},
+		wantErr:   true,
+	}, {
+		name:      "different namespaces",
+		migration: GatewayMigration{From: from, To: types.NamespacedName{Namespace: "istio-system", Name: to.Name}},
+		wantErr:   true,
+	}, {
+		name:      "same gateway",
+		migration: GatewayMigration{From: from, To: from},
+		wantErr:   true,
+	}, {
+		name:      "weight too high",
+		migration: GatewayMigration{From: from, To: to, Weight: 101},
+		wantErr:   true,
+	}, {
+		name:      "negative weight",
+		migration: GatewayMigration{From: from, To: to, Weight: -1},
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.migration.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, wantErr: %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesGatewayMigration(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		annotations   map[string]string
+		wantTo        routev1.RouteTargetReference
+		wantAlternate []routev1.RouteTargetReference
+	}{{
+		name:   "no migration",
+		wantTo: routev1.RouteTargetReference{Kind: "Service", Name: lbService, Weight: ptr.Int32(100)},
+	}, {
+		name: "migrating",
+		opts: []Option{WithGatewayMigration(GatewayMigration{
+			From:   types.NamespacedName{Namespace: lbNamespace, Name: lbService},
+			To:     types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"},
+			Weight: 30,
+		})},
+		wantTo: routev1.RouteTargetReference{Kind: "Service", Name: lbService, Weight: ptr.Int32(70)},
+		wantAlternate: []routev1.RouteTargetReference{
+			{Kind: "Service", Name: "istio-ingressgateway", Weight: ptr.Int32(30)},
+		},
+	}, {
+		name: "migrating another gateway",
+		opts: []Option{WithGatewayMigration(GatewayMigration{
+			From:   types.NamespacedName{Namespace: lbNamespace, Name: "kourier-internal"},
+			To:     types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"},
+			Weight: 30,
+		})},
+		wantTo: routev1.RouteTargetReference{Kind: "Service", Name: lbService, Weight: ptr.Int32(100)},
+	}, {
+		name: "migration not started",
+		opts: []Option{WithGatewayMigration(GatewayMigration{
+			From: types.NamespacedName{Namespace: lbNamespace, Name: lbService},
+			To:   types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"},
+		})},
+		wantTo: routev1.RouteTargetReference{Kind: "Service", Name: lbService, Weight: ptr.Int32(100)},
+	}, {
+		name: "migration not started, drained backends kept",
+		opts: []Option{WithGatewayMigration(GatewayMigration{
+			From: types.NamespacedName{Namespace: lbNamespace, Name: lbService},
+			To:   types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"},
+		})},
+		annotations: map[string]string{KeepDrainedBackendsAnnotation: "true"},
+		wantTo:      routev1.RouteTargetReference{Kind: "Service", Name: lbService, Weight: ptr.Int32(100)},
+		wantAlternate: []routev1.RouteTargetReference{
+			{Kind: "Service", Name: "istio-ingressgateway", Weight: ptr.Int32(0)},
+		},
+	}, {
+		name: "migration completed",
+		opts: []Option{WithGatewayMigration(GatewayMigration{
+			From:   types.NamespacedName{Namespace: lbNamespace, Name: lbService},
+			To:     types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"},
+			Weight: 100,
+		})},
+		wantTo: routev1.RouteTargetReference{Kind: "Service", Name: "istio-ingressgateway", Weight: ptr.Int32(100)},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = test.annotations
+			routes, err := MakeRoutes(ing, test.opts...)
+			if err != nil {
+				t.Fatal("MakeRoutes() =", err)
+			}
+			if !cmp.Equal(routes[0].Spec.To, test.wantTo) {
+				t.Errorf("To (-want, +got) = %s", cmp.Diff(test.wantTo, routes[0].Spec.To))
+			}
+			if !cmp.Equal(routes[0].Spec.AlternateBackends, test.wantAlternate) {
+				t.Errorf("AlternateBackends (-want, +got) = %s", cmp.Diff(test.wantAlternate, routes[0].Spec.AlternateBackends))
+			}
+		})
+	}
+}
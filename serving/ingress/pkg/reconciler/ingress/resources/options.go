@@ -0,0 +1,334 @@
+package resources
+
+import (
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Option customizes the Routes generated by MakeRoutes.
+type Option func(*options)
+
+type options struct {
+	targetPortFunc    func(namespace, name string) (string, string, error)
+	secretExistsFunc  func(namespace, name string) (bool, error)
+	serviceExistsFunc func(namespace, name string) bool
+	configMapFunc     func(namespace, name string) (map[string]string, bool)
+	hostRoutesFunc    func(host string) []*routev1.Route
+	fallbackGateway   *types.NamespacedName
+	strictTLS         bool
+	skipFunc          func(reason string)
+	longLabelPolicy   LongLabelPolicy
+	kourierSelector   KourierSelector
+	appsDomain        string
+	externalDNS       *ExternalDNS
+	flowCollection    bool
+	serviceLabels     map[string]string
+	tenantLabel       *TenantLabel
+
+	routeNamespace          string
+	externalNameIndirection bool
+
+	gatewayMigration *GatewayMigration
+
+	http2    bool
+	warnFunc func(reason, message string)
+
+	terminationPolicy TerminationPolicy
+	tlsDefaults       TLSDefaults
+
+	defaultTimeout time.Duration
+
+	minimalAnnotations bool
+	maintenanceService string
+
+	domainTemplate *DomainTemplate
+
+	servicePortFunc func(namespace, name string, port intstr.IntOrString) (string, bool, error)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		kourierSelector: DefaultKourierSelector,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTargetPortFunc sets a function returning the port of the given gateway Service that Routes
+// should target, and how it was selected. If it returns an empty port, KourierHTTPPort is used.
+func WithTargetPortFunc(f func(namespace, name string) (port, selection string, err error)) Option {
+	return func(o *options) {
+		o.targetPortFunc = f
+	}
+}
+
+// WithSecretExistsFunc sets a function returning whether the given secret exists. Generating
+// Routes for hosts whose certificate of the Ingress lacks its secret then fails with a
+// PendingCertificateError. Errors of the function are returned by MakeRoutes. Without it, all
+// secrets are assumed to exist.
+func WithSecretExistsFunc(f func(namespace, name string) (bool, error)) Option {
+	return func(o *options) {
+		o.secretExistsFunc = f
+	}
+}
+
+// WithServiceExistsFunc sets a function returning whether the given Service exists. Generating
+// Routes for Ingresses overriding their gateway with the GatewayServiceAnnotation then fails
+// with a MissingGatewayServiceError if it doesn't. Without it, all Services are assumed to exist.
+func WithServiceExistsFunc(f func(namespace, name string) bool) Option {
+	return func(o *options) {
+		o.serviceExistsFunc = f
+	}
+}
+
+// WithConfigMapFunc sets a function returning the data of the given ConfigMap, and whether it
+// exists. Without it, ConfigMaps referenced by Ingresses are ignored.
+func WithConfigMapFunc(f func(namespace, name string) (map[string]string, bool)) Option {
+	return func(o *options) {
+		o.configMapFunc = f
+	}
+}
+
+// WithHostRoutesFunc sets a function returning the existing Routes of the given host. Generating
+// Routes with a path prefix then fails with a PathPrefixConflictError if their path overlaps
+// with the path of a Route of another Ingress. Without it, paths aren't checked.
+func WithHostRoutesFunc(f func(host string) []*routev1.Route) Option {
+	return func(o *options) {
+		o.hostRoutesFunc = f
+	}
+}
+
+// WithFallbackGateway sets the gateway Service Routes target while the Ingress' LoadBalancer
+// status is not populated yet. This allows creating Routes before the Ingress becomes ready.
+func WithFallbackGateway(namespace, name string) Option {
+	return func(o *options) {
+		o.fallbackGateway = &types.NamespacedName{Namespace: namespace, Name: name}
+	}
+}
+
+// WithStrictTLS makes MakeRoutes fail with a MissingCertificateError for hosts outside of the apps
+// domain whose Route doesn't get a certificate of its own, instead of silently serving the
+// router's default certificate for them.
+func WithStrictTLS(strict bool) Option {
+	return func(o *options) {
+		o.strictTLS = strict
+	}
+}
+
+// WithLongLabelPolicy sets how hosts with labels longer than 63 characters are handled. By
+// default, generating Routes fails with a HostLabelTooLongError for them.
+func WithLongLabelPolicy(policy LongLabelPolicy) Option {
+	return func(o *options) {
+		o.longLabelPolicy = policy
+	}
+}
+
+// WithKourierSelector sets how the gateways of internal and public Kourier instances are told
+// apart. DefaultKourierSelector is used by default.
+func WithKourierSelector(selector KourierSelector) Option {
+	return func(o *options) {
+		o.kourierSelector = selector
+	}
+}
+
+// WithAppsDomain sets the apps domain of the cluster. Hosts outside of it are custom domains.
+// All hosts are considered custom domains if the apps domain is not set.
+func WithAppsDomain(domain string) Option {
+	return func(o *options) {
+		o.appsDomain = domain
+	}
+}
+
+// WithExternalDNS makes Routes for hosts outside of the cluster's apps domain carry the
+// annotations external-dns needs to publish DNS records for them.
+func WithExternalDNS(cfg ExternalDNS) Option {
+	return func(o *options) {
+		o.externalDNS = &cfg
+	}
+}
+
+// WithFlowCollection marks Routes for the Network Observability Operator to collect their flows.
+func WithFlowCollection() Option {
+	return func(o *options) {
+		o.flowCollection = true
+	}
+}
+
+// WithMinimalAnnotations makes Routes only carry the annotations that have an effect on them,
+// like the timeout and the annotations of the router the Ingress sets, rather than all
+// annotations of their Ingress. It keeps Routes small on clusters with many of them.
+func WithMinimalAnnotations() Option {
+	return func(o *options) {
+		o.minimalAnnotations = true
+	}
+}
+
+// WithMaintenanceService sets the name of the Service answering the requests of Routes in
+// maintenance, see MaintenanceAnnotation. It has to exist in the namespace of the Routes and
+// serve plain HTTP. Without it, the router answers these requests with 503.
+func WithMaintenanceService(name string) Option {
+	return func(o *options) {
+		o.maintenanceService = name
+	}
+}
+
+// WithDomainTemplate sets the domain template of Knative Serving. Rules of Ingresses that only
+// have cluster-local hosts then get a Route for the external host the template generates for
+// their Knative Route. Rules with external hosts are warned about if they lack it.
+func WithDomainTemplate(template DomainTemplate) Option {
+	return func(o *options) {
+		o.domainTemplate = &template
+	}
+}
+
+// WithDirectServiceMode makes Routes terminating TLS at the router target the Services of the
+// splits of their path directly, rather than the gateway. The given function returns the target
+// port of a Route reaching the given port of the given Service, see ServicePortTarget, and
+// whether the Service exists. Generating Routes fails with a MissingBackendServiceError if it
+// doesn't. Routes of paths that need the gateway to match or append headers, like the revision
+// headers the activator relies on, or whose splits target Services in several namespaces or
+// several ports, keep targeting the gateway.
+func WithDirectServiceMode(f func(namespace, name string, port intstr.IntOrString) (target string, exists bool, err error)) Option {
+	return func(o *options) {
+		o.servicePortFunc = f
+	}
+}
+
+// WithServiceLabels sets labels of the Knative Service of the Ingress to copy onto its Routes.
+// They don't override the labels of the Ingress.
+func WithServiceLabels(labels map[string]string) Option {
+	return func(o *options) {
+		o.serviceLabels = labels
+	}
+}
+
+// WithTenantLabel labels Routes with the tenant of the namespace of their Ingress. The label
+// overrides labels of the Ingress with the same key.
+func WithTenantLabel(label TenantLabel) Option {
+	return func(o *options) {
+		o.tenantLabel = &label
+	}
+}
+
+// WithRouteNamespace sets the namespace Routes have to be created in. Routes can only target
+// Services of their own namespace, so generating Routes for gateways in other namespaces fails
+// with a GatewayNamespaceMismatchError, unless WithExternalNameIndirection is used. By default,
+// Routes are created in the namespace of the gateway.
+func WithRouteNamespace(namespace string) Option {
+	return func(o *options) {
+		o.routeNamespace = namespace
+	}
+}
+
+// WithExternalNameIndirection makes Routes for gateways outside of the Route namespace target
+// an ExternalName Service pointing to the gateway instead, see MakeExternalNameService. The
+// caller is responsible for creating that Service.
+//
+// This keeps misconfigured gateways working at a cost: the router has to be allowed to use
+// ExternalName Services, it resolves them through DNS and balances over the gateway's
+// ClusterIP rather than its endpoints, adding a hop through kube-proxy. Errors in the
+// indirection only surface at request time, so fixing the gateway's namespace is preferable.
+func WithExternalNameIndirection() Option {
+	return func(o *options) {
+		o.externalNameIndirection = true
+	}
+}
+
+// WithGatewayMigration makes Routes to the gateway being migrated from split their traffic
+// between both gateways of the given migration.
+func WithGatewayMigration(migration GatewayMigration) Option {
+	return func(o *options) {
+		o.gatewayMigration = &migration
+	}
+}
+
+// WithHTTP2 sets whether Routes are served over HTTP/2 by default. Ingresses can override it
+// with HTTP2Annotation.
+func WithHTTP2(enabled bool) Option {
+	return func(o *options) {
+		o.http2 = enabled
+	}
+}
+
+// WithTerminationPolicy sets the TLS termination of Routes by the suffix of their host. The
+// TerminationAnnotation of an Ingress takes precedence.
+func WithTerminationPolicy(policy TerminationPolicy) Option {
+	return func(o *options) {
+		o.terminationPolicy = policy
+	}
+}
+
+// WithTLSDefaults sets the TLS settings of Routes that neither their Ingress nor the
+// TerminationPolicy set. The defaults are expected to be valid, see TLSDefaults.Validate.
+func WithTLSDefaults(defaults TLSDefaults) Option {
+	return func(o *options) {
+		o.tlsDefaults = defaults
+	}
+}
+
+// WithDefaultTimeout sets the timeout of Routes whose Ingress doesn't set one for any path. Zero
+// keeps the default, which is the maximum revision timeout of Knative Serving.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.defaultTimeout = timeout
+	}
+}
+
+// WithWarningFunc sets a function that is called whenever MakeRoutes generates a Route that
+// doesn't behave as the Ingress requested, but still serves it.
+func WithWarningFunc(f func(reason, message string)) Option {
+	return func(o *options) {
+		o.warnFunc = f
+	}
+}
+
+// WithSkipFunc sets a function that is called with the reason whenever MakeRoutes skips
+// generating Routes for a part of the Ingress.
+func WithSkipFunc(f func(reason string)) Option {
+	return func(o *options) {
+		o.skipFunc = f
+	}
+}
+
+// routeTimeout returns the timeout of Routes whose Ingress doesn't set one.
+func (o *options) routeTimeout() string {
+	if o.defaultTimeout > 0 {
+		return FormatTimeout(o.defaultTimeout)
+	}
+	return defaultTimeout
+}
+
+// targetPort returns the port Routes to the given gateway Service should target, and how it was
+// selected. The selection is empty if the port of the Service wasn't looked up.
+func (o *options) targetPort(namespace, name string) (string, string, error) {
+	if o.targetPortFunc != nil {
+		if port, selection, err := o.targetPortFunc(namespace, name); err != nil || port != "" {
+			return port, selection, err
+		}
+	}
+	return KourierHTTPPort, "", nil
+}
+
+// skipped records that a part of the Ingress was skipped for the given reason.
+func (o *options) skipped(reason string) {
+	if o.skipFunc != nil {
+		o.skipFunc(reason)
+	}
+}
+
+// warn records a warning with the given reason and message.
+func (o *options) warn(reason, message string) {
+	if o.warnFunc != nil {
+		o.warnFunc(reason, message)
+	}
+}
+
+// customDomain returns true if the given host is outside of the cluster's apps domain.
+func (o *options) customDomain(host string) bool {
+	return !InDomain(host, o.appsDomain)
+}
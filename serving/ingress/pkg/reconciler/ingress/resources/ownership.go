@@ -0,0 +1,94 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// HostAlreadyClaimedReason is the reason routers reject Routes with, if their host is held by an
+// older Route. With the namespace ownership of the IngressController set to Strict, that's the
+// case for hosts of Routes in other namespaces.
+const HostAlreadyClaimedReason = "HostAlreadyClaimed"
+
+var (
+	// otherNamespaceHoldsHost matches the message of routers enforcing namespace ownership.
+	otherNamespaceHoldsHost = regexp.MustCompile(`a route in another namespace holds (\S+) and is older than (\S+)`)
+	// routeHoldsHost matches the message of routers rejecting a Route with a host of an older one.
+	routeHoldsHost = regexp.MustCompile(`route (\S+) already exposes (\S+) and is older`)
+)
+
+// HostOwnershipConflict describes a Route that can't claim its host, because an older Route
+// holds it already.
+type HostOwnershipConflict struct {
+	Host string
+
+	// Holder is the name of the Route holding the host. Empty if unknown.
+	Holder string
+
+	// OtherNamespace is true if the Route holding the host is known to be in another namespace.
+	// Routers don't report which one.
+	OtherNamespace bool
+}
+
+func (c *HostOwnershipConflict) Error() string {
+	switch {
+	case c.OtherNamespace:
+		return fmt.Sprintf("host %s is held by a route in another namespace", c.Host)
+	case c.Holder != "":
+		return fmt.Sprintf("host %s is held by route %s", c.Host, c.Holder)
+	default:
+		return fmt.Sprintf("host %s is held by another route", c.Host)
+	}
+}
+
+// ParseHostOwnershipConflict parses a message of the OpenShift router or API server about a
+// Route whose host is held by another Route. False is returned for all other messages.
+func ParseHostOwnershipConflict(message string) (*HostOwnershipConflict, bool) {
+	if m := otherNamespaceHoldsHost.FindStringSubmatch(message); m != nil {
+		return &HostOwnershipConflict{Host: m[1], OtherNamespace: true}, true
+	}
+	if m := routeHoldsHost.FindStringSubmatch(message); m != nil {
+		return &HostOwnershipConflict{Host: m[2], Holder: m[1]}, true
+	}
+	return nil, false
+}
+
+// RouteHostOwnershipConflict returns the conflict if a router rejected the given Route, because
+// another Route holds its host.
+func RouteHostOwnershipConflict(route *routev1.Route) (*HostOwnershipConflict, bool) {
+	if IsRouteAdmitted(route) {
+		return nil, false
+	}
+	for _, ingress := range route.Status.Ingress {
+		for _, cond := range ingress.Conditions {
+			if cond.Type != routev1.RouteAdmitted || cond.Status != corev1.ConditionFalse ||
+				cond.Reason != HostAlreadyClaimedReason {
+				continue
+			}
+			if conflict, ok := ParseHostOwnershipConflict(cond.Message); ok {
+				return conflict, true
+			}
+			return &HostOwnershipConflict{Host: route.Spec.Host}, true
+		}
+	}
+	return nil, false
+}
+
+// APIHostOwnershipConflict returns the conflict if the API server refused to create or update a
+// Route, because another Route holds its host.
+func APIHostOwnershipConflict(err error) (*HostOwnershipConflict, bool) {
+	var statusErr *apierrs.StatusError
+	if !errors.As(err, &statusErr) {
+		return nil, false
+	}
+	if code := statusErr.Status().Code; code != http.StatusForbidden && code != http.StatusUnprocessableEntity {
+		return nil, false
+	}
+	return ParseHostOwnershipConflict(statusErr.Error())
+}
@@ -0,0 +1,134 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// Messages of the OpenShift router, see pkg/router/controller in openshift/router.
+	otherNamespaceMessage = "a route in another namespace holds hello.apps.example.com and is older than route-8a7e9a9d"
+	sameNamespaceMessage  = "route hello already exposes hello.apps.example.com and is older"
+)
+
+func TestParseHostOwnershipConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    *HostOwnershipConflict
+	}{{
+		name:    "other namespace",
+		message: otherNamespaceMessage,
+		want:    &HostOwnershipConflict{Host: "hello.apps.example.com", OtherNamespace: true},
+	}, {
+		name:    "older route",
+		message: sameNamespaceMessage,
+		want:    &HostOwnershipConflict{Host: "hello.apps.example.com", Holder: "hello"},
+	}, {
+		name:    "unrelated",
+		message: `endpoints "hello" not found`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := ParseHostOwnershipConflict(test.message)
+			if ok != (test.want != nil) || !cmp.Equal(got, test.want) {
+				t.Errorf("ParseHostOwnershipConflict() = %v, %v, want: %v", got, ok, test.want)
+			}
+		})
+	}
+}
+
+func TestRouteHostOwnershipConflict(t *testing.T) {
+	rejected := func(reason, message string) *routev1.Route {
+		return &routev1.Route{
+			Spec: routev1.RouteSpec{Host: "hello.apps.example.com"},
+			Status: routev1.RouteStatus{Ingress: []routev1.RouteIngress{{
+				Conditions: []routev1.RouteIngressCondition{{
+					Type:    routev1.RouteAdmitted,
+					Status:  corev1.ConditionFalse,
+					Reason:  reason,
+					Message: message,
+				}},
+			}}},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		route *routev1.Route
+		want  *HostOwnershipConflict
+	}{{
+		name:  "admitted",
+		route: &routev1.Route{},
+	}, {
+		name:  "other namespace",
+		route: rejected(HostAlreadyClaimedReason, otherNamespaceMessage),
+		want:  &HostOwnershipConflict{Host: "hello.apps.example.com", OtherNamespace: true},
+	}, {
+		name:  "unknown message",
+		route: rejected(HostAlreadyClaimedReason, "somebody else got there first"),
+		want:  &HostOwnershipConflict{Host: "hello.apps.example.com"},
+	}, {
+		name:  "other rejection",
+		route: rejected("ExtendedValidationFailed", "spec.tls.certificate: Invalid value"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := RouteHostOwnershipConflict(test.route)
+			if ok != (test.want != nil) || !cmp.Equal(got, test.want) {
+				t.Errorf("RouteHostOwnershipConflict() = %v, %v, want: %v", got, ok, test.want)
+			}
+		})
+	}
+}
+
+func TestAPIHostOwnershipConflict(t *testing.T) {
+	resource := routev1.Resource("routes")
+	kind := routev1.SchemeGroupVersion.WithKind("Route").GroupKind()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{{
+		name: "forbidden",
+		err:  apierrs.NewForbidden(resource, "hello", errors.New(otherNamespaceMessage)),
+		want: true,
+	}, {
+		name: "invalid",
+		err: apierrs.NewInvalid(kind, "hello", field.ErrorList{
+			field.Invalid(field.NewPath("spec", "host"), "hello.apps.example.com", sameNamespaceMessage),
+		}),
+		want: true,
+	}, {
+		name: "wrapped",
+		err:  fmt.Errorf("failed to create route: %w", apierrs.NewForbidden(resource, "hello", errors.New(otherNamespaceMessage))),
+		want: true,
+	}, {
+		name: "other status",
+		err:  apierrs.NewConflict(resource, "hello", errors.New(otherNamespaceMessage)),
+	}, {
+		name: "forbidden for other reasons",
+		err:  apierrs.NewForbidden(resource, "hello", errors.New("you do not have permission to set the host field of the route")),
+	}, {
+		name: "no status",
+		err:  errors.New(otherNamespaceMessage),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, got := APIHostOwnershipConflict(test.err); got != test.want {
+				t.Errorf("APIHostOwnershipConflict() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
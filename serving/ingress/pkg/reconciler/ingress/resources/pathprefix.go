@@ -0,0 +1,100 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/networking/pkg/apis/networking"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+const (
+	// PathPrefixAnnotation serves all hosts of an Ingress under the given path prefix, like
+	// "/payments". The router strips the prefix before forwarding requests, so the Knative
+	// Service sees the paths it'd see without it. It's meant for Ingresses of vanity hosts
+	// shared by several Knative Services, like those of DomainMappings.
+	PathPrefixAnnotation = "serving.knative.openshift.io/pathPrefix"
+
+	// RewriteTargetAnnotation makes the router replace the path of a Route in requests with
+	// the given path.
+	RewriteTargetAnnotation = "haproxy.router.openshift.io/rewrite-target"
+)
+
+// PathPrefixConflictError indicates that the path of a Route overlaps with the path of a Route
+// of another Ingress on the same host. The router would only send part of the requests to
+// each of them.
+type PathPrefixConflictError struct {
+	Host  string
+	Path  string
+	Route types.NamespacedName
+	// RoutePath is the path of the conflicting Route.
+	RoutePath string
+}
+
+func (e *PathPrefixConflictError) Error() string {
+	return fmt.Sprintf("path %s of host %s overlaps with path %s of route %s", e.Path, e.Host, e.RoutePath, e.Route)
+}
+
+// pathPrefix returns the path prefix the given annotations request, without a trailing slash,
+// or an empty string if they don't request one.
+func pathPrefix(annotations map[string]string) (string, error) {
+	value, ok := annotations[PathPrefixAnnotation]
+	if !ok {
+		return "", nil
+	}
+	prefix := strings.TrimRight(value, "/")
+	if !strings.HasPrefix(value, "/") || prefix == "" || strings.ContainsAny(prefix, "?# \t") {
+		return "", fmt.Errorf("%w %s: value %q must be an absolute path other than /", ErrInvalidAnnotation, PathPrefixAnnotation, value)
+	}
+	return prefix, nil
+}
+
+// applyPathPrefix serves the given path of a Route under the given prefix and makes the router
+// strip the prefix again.
+func applyPathPrefix(prefix, path string, annotations map[string]string) string {
+	target := path
+	if target == "" {
+		target = "/"
+	}
+	annotations[RewriteTargetAnnotation] = target
+	return prefix + path
+}
+
+// pathPrefixConflict returns an error if the given path of the host overlaps with the path of a
+// Route of another Ingress, as returned by the hostRoutesFunc. Routes serving all paths of
+// the host don't conflict, the router prefers the Routes of paths.
+func pathPrefixConflict(ci *networkingv1alpha1.Ingress, host, path string, o *options) error {
+	if o.hostRoutesFunc == nil {
+		return nil
+	}
+	for _, route := range o.hostRoutesFunc(host) {
+		if ownedBy(route, ci) || strings.TrimRight(route.Spec.Path, "/") == "" {
+			continue
+		}
+		if pathsOverlap(path, route.Spec.Path) {
+			return &PathPrefixConflictError{
+				Host:      host,
+				Path:      path,
+				Route:     types.NamespacedName{Namespace: route.Namespace, Name: route.Name},
+				RoutePath: route.Spec.Path,
+			}
+		}
+	}
+	return nil
+}
+
+// pathsOverlap returns whether the given paths are equal or one contains the other, comparing
+// whole path segments.
+func pathsOverlap(a, b string) bool {
+	a, b = strings.TrimRight(a, "/"), strings.TrimRight(b, "/")
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
+
+// ownedBy returns whether the given Route was generated for the given Ingress.
+func ownedBy(route *routev1.Route, ci *networkingv1alpha1.Ingress) bool {
+	return route.Labels[networking.IngressLabelKey] == ci.Name &&
+		route.Labels[serving.RouteNamespaceLabelKey] == ci.Labels[serving.RouteNamespaceLabelKey]
+}
@@ -0,0 +1,147 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/networking/pkg/apis/networking"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+func TestMakeRoutesPathPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefix     string
+		paths      []string
+		wantPaths  []string
+		wantTarget []string
+		wantErr    error
+	}{{
+		name:       "root path",
+		prefix:     "/payments",
+		paths:      []string{""},
+		wantPaths:  []string{"/payments"},
+		wantTarget: []string{"/"},
+	}, {
+		name:       "trailing slash",
+		prefix:     "/payments/",
+		paths:      []string{"/"},
+		wantPaths:  []string{"/payments"},
+		wantTarget: []string{"/"},
+	}, {
+		name:       "paths of the rule",
+		prefix:     "/payments",
+		paths:      []string{"/api", "/ui"},
+		wantPaths:  []string{"/payments/api", "/payments/ui"},
+		wantTarget: []string{"/api", "/ui"},
+	}, {
+		name:    "root",
+		prefix:  "/",
+		paths:   []string{""},
+		wantErr: ErrInvalidAnnotation,
+	}, {
+		name:    "relative",
+		prefix:  "payments",
+		paths:   []string{""},
+		wantErr: ErrInvalidAnnotation,
+	}, {
+		name:    "query",
+		prefix:  "/payments?v=1",
+		paths:   []string{""},
+		wantErr: ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := rule(withHosts([]string{externalDomain}))
+			r.HTTP.Paths = nil
+			for _, path := range test.paths {
+				r.HTTP.Paths = append(r.HTTP.Paths, networkingv1alpha1.HTTPIngressPath{Path: path})
+			}
+			ing := ingress(withRules(r))
+			ing.Annotations = map[string]string{PathPrefixAnnotation: test.prefix}
+
+			routes, err := MakeRoutes(ing)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want %v", err, test.wantErr)
+			}
+			if len(routes) != len(test.wantPaths) {
+				t.Fatalf("len(routes) = %d, want %d", len(routes), len(test.wantPaths))
+			}
+			for i, route := range routes {
+				if route.Spec.Path != test.wantPaths[i] {
+					t.Errorf("Path = %q, want %q", route.Spec.Path, test.wantPaths[i])
+				}
+				if got := route.Annotations[RewriteTargetAnnotation]; got != test.wantTarget[i] {
+					t.Errorf("%s = %q, want %q", RewriteTargetAnnotation, got, test.wantTarget[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMakeRoutesPathPrefixConflict(t *testing.T) {
+	hostRoute := func(ingressName, path string) *routev1.Route {
+		return &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: lbNamespace,
+				Name:      ingressName + "-route",
+				Labels: map[string]string{
+					networking.IngressLabelKey:     ingressName,
+					serving.RouteNamespaceLabelKey: "default",
+				},
+			},
+			Spec: routev1.RouteSpec{Host: externalHost, Path: path},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		existing *routev1.Route
+		wantErr  bool
+	}{{
+		name:     "same prefix",
+		existing: hostRoute("other", "/payments"),
+		wantErr:  true,
+	}, {
+		name:     "nested prefix",
+		existing: hostRoute("other", "/payments/refunds"),
+		wantErr:  true,
+	}, {
+		name:     "root path of the host",
+		existing: hostRoute("other", "/"),
+	}, {
+		name:     "prefix of another segment",
+		existing: hostRoute("other", "/pay"),
+	}, {
+		name:     "all paths of the host",
+		existing: hostRoute("other", ""),
+	}, {
+		name:     "own route",
+		existing: hostRoute("ingress", "/payments"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = map[string]string{PathPrefixAnnotation: "/payments"}
+
+			_, err := MakeRoutes(ing, WithHostRoutesFunc(func(host string) []*routev1.Route {
+				if host != externalHost {
+					t.Errorf("Looked up routes of host %q, want %q", host, externalHost)
+				}
+				return []*routev1.Route{test.existing}
+			}))
+			var conflictErr *PathPrefixConflictError
+			if got := errors.As(err, &conflictErr); got != test.wantErr {
+				t.Fatalf("MakeRoutes() = %v, want conflict: %v", err, test.wantErr)
+			}
+			if test.wantErr && conflictErr.RoutePath != test.existing.Spec.Path {
+				t.Errorf("RoutePath = %q, want %q", conflictErr.RoutePath, test.existing.Spec.Path)
+			}
+		})
+	}
+}
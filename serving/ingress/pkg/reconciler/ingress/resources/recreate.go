@@ -0,0 +1,46 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// RecreateOnChangeAnnotation makes Routes of an Ingress be recreated rather than updated in
+// place whenever their spec changes, for routers rejecting updates of fields RequiresRecreation
+// doesn't know about.
+const RecreateOnChangeAnnotation = "serving.knative.openshift.io/recreateOnChange"
+
+// recreateOnChange returns whether the given annotations request recreating Routes on changes.
+func recreateOnChange(annotations map[string]string) (bool, error) {
+	value, ok := annotations[RecreateOnChangeAnnotation]
+	if !ok {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w %s: value %q must be a boolean", ErrInvalidAnnotation, RecreateOnChangeAnnotation, value)
+	}
+	return enabled, nil
+}
+
+// RequiresRecreation returns whether the given existing Route has to be deleted and created
+// again to reach the desired state, rather than being updated in place. That's the case if
+// the following fields change:
+//
+//   - spec.host, which the router keeps serving under the previously admitted host.
+//   - spec.wildcardPolicy, which the Route API doesn't allow to change.
+//
+// If the desired Route carries the RecreateOnChangeAnnotation, any change of the spec requires
+// recreation.
+func RequiresRecreation(existing, desired *routev1.Route) bool {
+	if NormalizeHost(existing.Spec.Host) != NormalizeHost(desired.Spec.Host) ||
+		existing.Spec.WildcardPolicy != desired.Spec.WildcardPolicy {
+		return true
+	}
+	// The annotation was validated when generating the Route.
+	recreate, _ := recreateOnChange(desired.Annotations)
+	return recreate && !equality.Semantic.DeepEqual(existing.Spec, desired.Spec)
+}
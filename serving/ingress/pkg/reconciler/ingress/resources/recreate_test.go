@@ -0,0 +1,86 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRequiresRecreation(t *testing.T) {
+	existing := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{TimeoutAnnotation: "5s"},
+		},
+		Spec: routev1.RouteSpec{Host: "shop.example.com", WildcardPolicy: routev1.WildcardPolicyNone},
+	}
+
+	tests := []struct {
+		name    string
+		desired func(*routev1.Route)
+		want    bool
+	}{{
+		name:    "unchanged",
+		desired: func(*routev1.Route) {},
+	}, {
+		name: "host changed",
+		desired: func(r *routev1.Route) {
+			r.Spec.Host = "store.example.com"
+		},
+		want: true,
+	}, {
+		name: "host case changed",
+		desired: func(r *routev1.Route) {
+			r.Spec.Host = "Shop.example.com"
+		},
+	}, {
+		name: "wildcard policy changed",
+		desired: func(r *routev1.Route) {
+			r.Spec.WildcardPolicy = routev1.WildcardPolicySubdomain
+		},
+		want: true,
+	}, {
+		name: "timeout changed",
+		desired: func(r *routev1.Route) {
+			r.Annotations[TimeoutAnnotation] = "10s"
+		},
+	}, {
+		name: "path changed",
+		desired: func(r *routev1.Route) {
+			r.Spec.Path = "/api"
+		},
+	}, {
+		name: "path changed with recreation on change",
+		desired: func(r *routev1.Route) {
+			r.Annotations[RecreateOnChangeAnnotation] = "true"
+			r.Spec.Path = "/api"
+		},
+		want: true,
+	}, {
+		name: "timeout changed with recreation on change",
+		desired: func(r *routev1.Route) {
+			r.Annotations[RecreateOnChangeAnnotation] = "true"
+			r.Annotations[TimeoutAnnotation] = "10s"
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desired := existing.DeepCopy()
+			test.desired(desired)
+			if got := RequiresRecreation(existing, desired); got != test.want {
+				t.Errorf("RequiresRecreation() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesInvalidRecreateOnChange(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+	ing.Annotations = map[string]string{RecreateOnChangeAnnotation: "always"}
+
+	if _, err := MakeRoutes(ing); !errors.Is(err, ErrInvalidAnnotation) {
+		t.Errorf("MakeRoutes() = %v, want %v", err, ErrInvalidAnnotation)
+	}
+}
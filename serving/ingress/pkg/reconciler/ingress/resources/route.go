@@ -5,115 +5,425 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	routev1 "github.com/openshift/api/route/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/networking/pkg/apis/networking"
 	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/kmeta"
-	"knative.dev/pkg/ptr"
 	"knative.dev/serving/pkg/apis/config"
 )
 
 const (
-	TimeoutAnnotation      = "haproxy.router.openshift.io/timeout"
-	DisableRouteAnnotation = "serving.knative.openshift.io/disableRoute"
-	KourierHTTPPort        = "http2"
+	TimeoutAnnotation        = "haproxy.router.openshift.io/timeout"
+	DisableRouteAnnotation   = "serving.knative.openshift.io/disableRoute"
+	WeightRoundingAnnotation = "serving.knative.openshift.io/weightRounding"
+	HostSuffixAnnotation     = "serving.knative.openshift.io/hostSuffix"
+	FlowCollectionAnnotation = "network.openshift.io/flow-collection"
+	KourierHTTPPort          = "http2"
+
+	// GatewayServiceAnnotation overrides the name of the gateway Service the Routes of an Ingress
+	// target. The namespace is still the one of the gateway in the LoadBalancer status.
+	GatewayServiceAnnotation = "serving.knative.openshift.io/gatewayService"
 )
 
-var defaultTimeout = fmt.Sprintf("%vs", config.DefaultMaxRevisionTimeoutSeconds)
+var defaultTimeout = FormatTimeout(config.DefaultMaxRevisionTimeoutSeconds * time.Second)
 
 // ErrNoValidLoadbalancerDomain indicates that the current ingress does not have a DomainInternal field, or
 // said field does not contain a value we can work with.
 var ErrNoValidLoadbalancerDomain = errors.New("unable to find Ingress LoadBalancer with DomainInternal set")
 
+// ErrInvalidAnnotation indicates that an annotation on the current ingress has a value we cannot work with.
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
 // MakeRoutes creates OpenShift Routes from a Knative Ingress
-func MakeRoutes(ci *networkingv1alpha1.Ingress) ([]*routev1.Route, error) {
+func MakeRoutes(ci *networkingv1alpha1.Ingress, opts ...Option) ([]*routev1.Route, error) {
+	o := newOptions(opts)
 	routes := []*routev1.Route{}
 
+	// Skip making routes when the annotation is specified.
+	disabled, err := routeDisabled(ci.GetAnnotations())
+	if err != nil {
+		return nil, err
+	}
+	if disabled {
+		return routes, nil
+	}
+	// Knative leaves the rules empty while it initializes the Ingress of a Route. There's nothing
+	// to serve, not even the gateway has to be known.
+	if len(ci.Spec.Rules) == 0 {
+		return routes, nil
+	}
+	whitelist, err := ipWhitelist(ci, o)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	primary := &primaryRoutes{}
+
 	for _, rule := range ci.Spec.Rules {
-		// Skip route creation for cluster-local visibility.
+		// Skip route creation for cluster-local visibility. Hosts of such rules aren't marked as
+		// seen, so a host is served if any rule exposes it, regardless of the order of the rules.
 		if rule.Visibility == networkingv1alpha1.IngressVisibilityClusterLocal {
 			continue
 		}
-		for _, host := range rule.Hosts {
-			// Ignore domains like myksvc.myproject.svc.cluster.local
-			// TODO: This also ignores any top-level vanity domains
-			// like foo.com the user may have set. But, it tackles the
-			// autogenerated name case which is the biggest pain
-			// point.
-			parts := strings.Split(host, ".")
-			if len(parts) > 2 && parts[2] != "svc" {
-				route, err := makeRoute(ci, host, rule)
+		// Older Knative versions and hand-crafted ingresses produce catch-all rules without hosts.
+		// OpenShift generates the host of their Route. A single Route serves all of them, as
+		// the gateway matches any host with the first of them anyway.
+		if len(rule.Hosts) == 0 {
+			if seen[""] {
+				continue
+			}
+			seen[""] = true
+			route, err := makeRoute(ci, "", rule, hostRoutePath(rule), o)
+			if err != nil {
+				return nil, err
+			}
+			if route != nil {
+				routes = append(routes, route)
+			}
+			continue
+		}
+		hosts, err := domainTemplateHosts(ci, rule, o)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			host = NormalizeHost(host)
+			// Hosts only differing in case or a trailing dot are the same to the router.
+			if seen[host] {
+				continue
+			}
+			seen[host] = true
+
+			if internalHost(host) {
+				o.skipped(fmt.Sprintf("host %q is not served outside of the cluster", host))
+				continue
+			}
+			for _, path := range routePaths(rule) {
+				route, err := makeRoute(ci, host, rule, path, o)
 				if err != nil {
 					return nil, err
 				}
 				if route == nil {
 					continue
 				}
+				primary.add(host, rule, path, route)
 				routes = append(routes, route)
 			}
 		}
 	}
-
+	aliases, err := makeAliasRoutes(ci, primary, seen, o)
+	if err != nil {
+		return nil, err
+	}
+	routes = append(routes, aliases...)
+	applyIPWhitelist(routes, whitelist)
 	return routes, nil
 }
 
-func makeRoute(ci *networkingv1alpha1.Ingress, host string, rule networkingv1alpha1.IngressRule) (*routev1.Route, error) {
-	// Take over annotaitons from ingress.
-	annotations := ci.GetAnnotations()
-	if annotations == nil {
-		annotations = make(map[string]string)
+// routePath is a path of a rule that is served by a Route of its own.
+type routePath struct {
+	// path is the path the Route serves. It's empty if the Route serves all paths of the host.
+	path string
+	// timeout is the longest timeout of the paths of the rule the Route serves, if any is set.
+	timeout *time.Duration
+	// splits are the splits of the path of the rule the Route serves. They're only set if the
+	// Route serves a single path of the rule.
+	splits []networkingv1alpha1.IngressBackendSplit
+	// appendsHeaders is true if the gateway has to add headers to the requests of the path or of
+	// any of its splits, like the revision headers the activator needs or the tag header.
+	appendsHeaders bool
+}
+
+// routePaths returns the paths of the given rule that need a Route of their own, so that each
+// Route carries the timeout of the paths it serves. Paths only differing in their header matches,
+// like those Knative generates for tag header based routing, share a Route, which allows the
+// longest of their timeouts. The paths are returned in the order of the rule.
+func routePaths(rule networkingv1alpha1.IngressRule) []routePath {
+	if rule.HTTP == nil || len(rule.HTTP.Paths) == 0 {
+		return []routePath{{}}
+	}
+	paths := make([]routePath, 0, len(rule.HTTP.Paths))
+	index := make(map[string]int, len(rule.HTTP.Paths))
+	for _, p := range rule.HTTP.Paths {
+		// The root path matches all paths of the host, just like no path.
+		path := p.Path
+		if path == "/" {
+			path = ""
+		}
+		i, ok := index[path]
+		if !ok {
+			i = len(paths)
+			index[path] = i
+			paths = append(paths, routePath{path: path, splits: p.Splits})
+		} else {
+			paths[i].splits = nil
+		}
+		if appendsHeaders(p) {
+			paths[i].appendsHeaders = true
+		}
+		if t := p.DeprecatedTimeout; t != nil && (paths[i].timeout == nil || t.Duration > *paths[i].timeout) {
+			timeout := t.Duration
+			paths[i].timeout = &timeout
+		}
+	}
+	return paths
+}
+
+// appendsHeaders returns true if the given path or any of its splits adds headers to requests.
+func appendsHeaders(path networkingv1alpha1.HTTPIngressPath) bool {
+	if len(path.AppendHeaders) > 0 {
+		return true
+	}
+	for _, split := range path.Splits {
+		if len(split.AppendHeaders) > 0 {
+			return true
+		}
 	}
+	return false
+}
+
+// hostRoutePath returns the path of a Route serving all paths of the given rule, which allows
+// the longest of their timeouts.
+func hostRoutePath(rule networkingv1alpha1.IngressRule) routePath {
+	var merged routePath
+	for _, path := range routePaths(rule) {
+		if path.timeout != nil && (merged.timeout == nil || *path.timeout > *merged.timeout) {
+			merged.timeout = path.timeout
+		}
+	}
+	return merged
+}
+
+// makeRoute creates the Route serving the given path of the host of the rule. If the host is
+// empty, OpenShift generates it once the Route is created.
+func makeRoute(ci *networkingv1alpha1.Ingress, host string, rule networkingv1alpha1.IngressRule, path routePath, o *options) (*routev1.Route, error) {
+	// Take over annotaitons from ingress. The map is copied as it's modified below.
+	annotations := kmeta.CopyMap(ci.GetAnnotations())
 
 	// Skip making route when visibility of the rule is local only.
 	if rule.Visibility == networkingv1alpha1.IngressVisibilityClusterLocal {
 		return nil, nil
 	}
 
-	// Skip making route when the annotation is specified.
-	if _, ok := annotations[DisableRouteAnnotation]; ok {
-		return nil, nil
+	// Always set a timeout, as the router's default of 30s is way lower than what Knative allows.
+	annotations[TimeoutAnnotation] = o.routeTimeout()
+	if path.timeout != nil {
+		// Supported time units for openshift route annotations are microseconds (us), milliseconds (ms), seconds (s), minutes (m), hours (h), or days (d)
+		// But the timeout value from ingress is in xmys(ex: 10m0s) format, which has to be converted.
+		annotations[TimeoutAnnotation] = FormatTimeout(*path.timeout)
 	}
 
-	if rule.HTTP != nil {
-		for i := range rule.HTTP.Paths {
-			if rule.HTTP.Paths[i].DeprecatedTimeout != nil {
-				// Supported time units for openshift route annotations are microseconds (us), milliseconds (ms), seconds (s), minutes (m), hours (h), or days (d)
-				// But the timeout value from ingress is in xmys(ex: 10m0s) format
-				// So, in order to make openshift route to work converting it into seconds.
-				annotations[TimeoutAnnotation] = fmt.Sprintf("%vs", rule.HTTP.Paths[i].DeprecatedTimeout.Duration.Seconds())
-			} else {
-				annotations[TimeoutAnnotation] = defaultTimeout
-			}
-
-		}
+	// The tag is derived from the hosts of the Ingress, so before the suffix is replaced.
+	tag := trafficTag(ci, rule, host)
+	host, err := replaceHostSuffix(host, annotations)
+	if err != nil {
+		return nil, err
 	}
 
-	labels := kmeta.UnionMaps(ci.Labels, map[string]string{
+	labels := kmeta.UnionMaps(o.serviceLabels, ci.Labels, map[string]string{
 		networking.IngressLabelKey: ci.GetName(),
 	})
+	if tag != "" {
+		labels[TagLabelKey] = tag
+	}
+	if o.tenantLabel != nil {
+		tenant, err := o.tenantLabel.tenant(ci.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		labels[o.tenantLabel.Key] = tenant
+	}
 
-	name := routeName(string(ci.GetUID()), host)
-	serviceName := ""
-	namespace := ""
-	if ci.Status.PublicLoadBalancer != nil {
-		for _, lbIngress := range ci.Status.PublicLoadBalancer.Ingress {
-			if lbIngress.DomainInternal != "" {
-				// DomainInternal should look something like:
-				// kourier.knative-serving-ingress.svc.cluster.local
-				parts := strings.Split(lbIngress.DomainInternal, ".")
-				if len(parts) > 2 && parts[2] == "svc" {
-					serviceName = parts[0]
-					namespace = parts[1]
-				}
-			}
+	// The name is based on the host before shortening labels to stay stable if the policy changes.
+	// Routes serving all paths of a host are named after the host alone, like before Routes were
+	// generated per path.
+	name := routeName(string(ci.GetUID()), host+path.path)
+	dnsName, err := routeHost(host, o.longLabelPolicy)
+	if err != nil {
+		return nil, err
+	}
+	hostname, wildcardPolicy := wildcardRouteHost(dnsName)
+	// Generated hosts are part of the router's domain, like hosts of the apps domain.
+	custom := host != "" && o.customDomain(hostname)
+	if o.flowCollection {
+		annotations[FlowCollectionAnnotation] = "true"
+	}
+	http2, err := http2Requested(annotations, o.http2)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyHealthCheckInterval(annotations); err != nil {
+		return nil, err
+	}
+	// Routes are only recreated when they're applied, validate the annotation up front.
+	if _, err := recreateOnChange(annotations); err != nil {
+		return nil, err
+	}
+	forwardedHeaders, err := forwardedHeadersPolicy(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if forwardedHeaders != "" {
+		annotations[setForwardedHeadersRouteAnnotation] = forwardedHeaders
+	}
+	if custom {
+		for k, v := range o.externalDNS.annotations(dnsName) {
+			annotations[k] = v
+		}
+	}
+	termination, err := routeTermination(annotations, hostname, o.terminationPolicy, o.tlsDefaults)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTermination(annotations, termination); err != nil {
+		return nil, err
+	}
+	insecure, err := routeInsecurePolicy(annotations, termination, o.tlsDefaults)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCookieSameSite(annotations, insecure); err != nil {
+		return nil, err
+	}
+	// Hosts of the apps domain are covered by the router's default certificate, unless they
+	// need a certificate of their own to be served over HTTP/2. With passthrough, the gateway
+	// serves the certificate instead of the router.
+	if termination != routev1.TLSTerminationPassthrough && (custom || http2) {
+		certs := certManagerAnnotations(annotations)
+		for k, v := range certs {
+			annotations[k] = v
+		}
+		if http2 && certs == nil {
+			o.warn(HTTP2UnavailableReason, fmt.Sprintf(
+				"host %s is served over HTTP/1.1 only, HTTP/2 requires a certificate of its own, see %s", hostname, CertManagerIssuerAnnotation))
+		}
+	}
+	// The certificate is expected, but not issued yet. Serving the host without it would break
+	// TLS until the next change of the Ingress.
+	if err := pendingCertificate(ci, host, termination, o); err != nil {
+		return nil, err
+	}
+	// Without a certificate of its own, the router serves its default wildcard certificate, which
+	// only matches hosts of the apps domain.
+	if o.strictTLS && custom && !hasCertificate(ci, host, termination, annotations) {
+		return nil, &MissingCertificateError{Host: host}
+	}
+
+	gateway, target, err := routeService(ci, o)
+	if err != nil {
+		return nil, err
+	}
+	// Routes to a gateway that doesn't exist yet would fail until it's created.
+	if _, ok := annotations[GatewayServiceAnnotation]; ok && o.serviceExistsFunc != nil && !o.serviceExistsFunc(gateway.Namespace, gateway.Name) {
+		return nil, &MissingGatewayServiceError{Gateway: gateway}
+	}
+	// The port is looked up on the gateway, which the indirection serves the ports of.
+	targetPort := KourierHTTPSPort
+	if termination == routev1.TLSTerminationEdge {
+		port, selection, err := o.targetPort(gateway.Namespace, gateway.Name)
+		if err != nil {
+			return nil, err
 		}
+		targetPort = port
+		if selection != "" {
+			annotations[GatewayPortSelectionAnnotation] = selection
+		}
+	}
+	serviceName, namespace := target.Name, target.Namespace
+	backends := o.gatewayMigration.backends(namespace, serviceName)
+	allowLocal, err := allowClusterLocalSplits(annotations)
+	if err != nil {
+		return nil, err
+	}
+	var excluded map[types.NamespacedName]bool
+	if !allowLocal {
+		excluded = clusterLocalServices(ci)
+	}
+	// The Services of the splits serve plain HTTP, so only Routes terminating TLS at the router
+	// can target them.
+	var direct *directTarget
+	if termination == routev1.TLSTerminationEdge && host != "" {
+		direct, err = directServiceTarget(hostname, path, excluded, o)
+		if err != nil {
+			return nil, err
+		}
+		if direct != nil {
+			namespace, backends, targetPort = direct.namespace, direct.backends, direct.port
+			delete(annotations, GatewayPortSelectionAnnotation)
+		}
+	}
+	if direct == nil && targetsAny(path.splits, excluded) {
+		o.warn(ClusterLocalSplitsServedReason, fmt.Sprintf(
+			"The route of host %q targets the gateway, which serves the splits of cluster-local services: ignoring %s=false",
+			host, AllowClusterLocalSplitsAnnotation))
+	}
+	if host == "" {
+		name = generatedHostRouteName(ci, namespace)
+		annotations[HostGeneratedAnnotation] = "true"
 	}
 
-	if serviceName == "" || namespace == "" {
-		return nil, ErrNoValidLoadbalancerDomain
+	policy, err := roundingPolicy(annotations)
+	if err != nil {
+		return nil, err
+	}
+	keepDrained, err := keepDrainedBackends(annotations)
+	if err != nil {
+		return nil, err
+	}
+	// Unless they target the Services of the splits directly, Routes target gateways, which apply
+	// the splits of the rule themselves. Splits to cluster-local targets are thus served without a
+	// Route or backend of their own.
+	to, alternateBackends := routeTargets(backends, policy, keepDrained)
+	omitWeight, err := omitSingleBackendWeight(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if omitWeight {
+		if len(alternateBackends) > 0 {
+			o.warn(WeightRequiredReason, fmt.Sprintf(
+				"The route of host %q has %d backends, which need weights: ignoring %s", host, len(alternateBackends)+1, OmitSingleBackendWeightAnnotation))
+		} else {
+			to.Weight = nil
+		}
+	}
+	weight, err := primaryWeight(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if weight != nil {
+		if len(alternateBackends) > 0 {
+			o.warn(PrimaryWeightIgnoredReason, fmt.Sprintf(
+				"The route of host %q has %d backends, whose weights follow the traffic split: ignoring %s", host, len(alternateBackends)+1, PrimaryWeightAnnotation))
+		} else {
+			to.Weight = weight
+		}
+	}
+
+	routePath := path.path
+	prefix, err := pathPrefix(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		routePath = applyPathPrefix(prefix, path.path, annotations)
+		if err := pathPrefixConflict(ci, hostname, routePath, o); err != nil {
+			return nil, err
+		}
+	}
+
+	inMaintenance, err := maintenance(annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.minimalAnnotations {
+		minimalAnnotations(annotations)
 	}
 
 	route := &routev1.Route{
@@ -124,25 +434,162 @@ func makeRoute(ci *networkingv1alpha1.Ingress, host string, rule networkingv1alp
 			Annotations: annotations,
 		},
 		Spec: routev1.RouteSpec{
-			Host: host,
+			Host: hostname,
+			Path: routePath,
 			Port: &routev1.RoutePort{
-				TargetPort: intstr.FromString(KourierHTTPPort),
-			},
-			To: routev1.RouteTargetReference{
-				Kind:   "Service",
-				Name:   serviceName,
-				Weight: ptr.Int32(100),
+				TargetPort: intstr.Parse(targetPort),
 			},
-			TLS: &routev1.TLSConfig{
-				Termination:                   routev1.TLSTerminationEdge,
-				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
-			},
-			WildcardPolicy: routev1.WildcardPolicyNone,
+			To:                to,
+			AlternateBackends: alternateBackends,
+			TLS:               tlsConfig(termination, insecure, o.tlsDefaults.DestinationCACertificate),
+			WildcardPolicy:    wildcardPolicy,
 		},
 	}
+	if inMaintenance {
+		applyMaintenance(route, o.maintenanceService)
+	}
 	return route, nil
 }
 
+// RouteNamespace returns the namespace the Routes for the given Ingress are created in, without
+// building them. It returns ErrNoValidLoadbalancerDomain if the gateway serving the Ingress is
+// not known yet.
+func RouteNamespace(ci *networkingv1alpha1.Ingress, opts ...Option) (string, error) {
+	_, target, err := routeService(ci, newOptions(opts))
+	if err != nil {
+		return "", err
+	}
+	return target.Namespace, nil
+}
+
+// routeService returns the gateway Service serving the given Ingress and the Service its Routes
+// target. They differ if Routes are created in a namespace other than the gateway's, in which
+// case the Routes target the ExternalName Service pointing at the gateway.
+func routeService(ci *networkingv1alpha1.Ingress, o *options) (types.NamespacedName, types.NamespacedName, error) {
+	name, namespace, err := gatewayService(ci, o)
+	if err != nil {
+		return types.NamespacedName{}, types.NamespacedName{}, err
+	}
+	gateway := types.NamespacedName{Namespace: namespace, Name: name}
+	if o.routeNamespace == "" || namespace == o.routeNamespace {
+		return gateway, gateway, nil
+	}
+	if !o.externalNameIndirection {
+		return types.NamespacedName{}, types.NamespacedName{}, &GatewayNamespaceMismatchError{Gateway: gateway, RouteNamespace: o.routeNamespace}
+	}
+	return gateway, types.NamespacedName{Namespace: o.routeNamespace, Name: ExternalNameServiceName(gateway)}, nil
+}
+
+// gatewayService returns the name and namespace of the gateway Service Routes for the given
+// Ingress have to target. Public gateways are preferred over internal ones if the Ingress is
+// exposed by both.
+func gatewayService(ci *networkingv1alpha1.Ingress, o *options) (string, string, error) {
+	override, err := gatewayServiceOverride(ci.GetAnnotations())
+	if err != nil {
+		return "", "", err
+	}
+
+	var public, internal *types.NamespacedName
+	if ci.Status.PublicLoadBalancer != nil {
+		for _, lbIngress := range ci.Status.PublicLoadBalancer.Ingress {
+			if lbIngress.DomainInternal != "" {
+				// DomainInternal should look something like:
+				// kourier.knative-serving-ingress.svc.cluster.local
+				parts := strings.Split(lbIngress.DomainInternal, ".")
+				if len(parts) > 2 && parts[2] == "svc" {
+					gateway := &types.NamespacedName{Name: parts[0], Namespace: parts[1]}
+					if o.kourierSelector.IsInternal(gateway.Name) {
+						internal = gateway
+					} else {
+						public = gateway
+					}
+				}
+			}
+		}
+	}
+
+	// The LoadBalancer status is only populated once the gateway is configured. Fall back to
+	// the known gateway so Routes exist by the time the Ingress becomes ready.
+	if lbStatusPending(ci) && o.fallbackGateway != nil {
+		return overrideOr(override, o.fallbackGateway.Name), o.fallbackGateway.Namespace, nil
+	}
+
+	gateway := public
+	if gateway == nil {
+		gateway = internal
+	}
+	if gateway == nil || gateway.Name == "" || gateway.Namespace == "" {
+		return "", "", ErrNoValidLoadbalancerDomain
+	}
+	return overrideOr(override, gateway.Name), gateway.Namespace, nil
+}
+
+// MissingGatewayServiceError indicates that the gateway Service an Ingress requests with the
+// GatewayServiceAnnotation doesn't exist. It may still be created, so generating the Routes
+// should be retried.
+type MissingGatewayServiceError struct {
+	Gateway types.NamespacedName
+}
+
+func (e *MissingGatewayServiceError) Error() string {
+	return fmt.Sprintf("gateway service %s requested by %s doesn't exist", e.Gateway, GatewayServiceAnnotation)
+}
+
+// gatewayServiceOverride returns the name of the gateway Service the given annotations request,
+// or an empty string if they don't override it. Only a name can be given, so that the gateway
+// stays in the namespace the LoadBalancer status reports.
+func gatewayServiceOverride(annotations map[string]string) (string, error) {
+	name, ok := annotations[GatewayServiceAnnotation]
+	if !ok {
+		return "", nil
+	}
+	if errs := validation.IsDNS1035Label(name); len(errs) > 0 {
+		return "", fmt.Errorf("%w %s: value %q must be the name of a Service in the gateway namespace: %s",
+			ErrInvalidAnnotation, GatewayServiceAnnotation, name, strings.Join(errs, ", "))
+	}
+	return name, nil
+}
+
+// overrideOr returns the given override, or the given value if no override is set.
+func overrideOr(override, value string) string {
+	if override != "" {
+		return override
+	}
+	return value
+}
+
+// lbStatusPending returns true if the public LoadBalancer status of the given Ingress has not
+// been populated yet.
+func lbStatusPending(ci *networkingv1alpha1.Ingress) bool {
+	return ci.Status.PublicLoadBalancer == nil || len(ci.Status.PublicLoadBalancer.Ingress) == 0
+}
+
+// routeDisabled returns true if the given annotations disable route creation. An empty value
+// disables route creation to stay compatible with the annotation's original semantics.
+func routeDisabled(annotations map[string]string) (bool, error) {
+	value, ok := annotations[DisableRouteAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	switch strings.ToLower(value) {
+	case "", "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w %s: value %q must be one of \"true\" or \"false\"",
+			ErrInvalidAnnotation, DisableRouteAnnotation, value)
+	}
+}
+
+// NormalizeHost returns the canonical form of the given DNS host, which is lowercase and
+// without a trailing dot. Hosts must be normalized before being hashed into a route name
+// so that equivalent hosts always map to the same route.
+func NormalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
 func routeName(uid, host string) string {
 	return fmt.Sprintf("route-%s-%x", uid, hashHost(host))
 }
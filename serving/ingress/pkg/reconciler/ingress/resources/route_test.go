@@ -1,14 +1,19 @@
 package resources
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	routev1 "github.com/openshift/api/route/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/networking/pkg/apis/networking"
 	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	"knative.dev/pkg/ptr"
@@ -20,12 +25,17 @@ const (
 	externalDomain  = "public.default.domainName"
 	externalDomain2 = "another.public.default.domainName"
 
+	// externalHost and externalHost2 are the normalized hosts Routes for externalDomain and
+	// externalDomain2 are generated with.
+	externalHost  = "public.default.domainname"
+	externalHost2 = "another.public.default.domainname"
+
 	lbService   = "lb-service"
 	lbNamespace = "lb-namespace"
 
 	uid        = "8a7e9a9d-fbc6-11e9-a88e-0261aff8d6d8"
-	routeName0 = "route-" + uid + "-323531366235"
-	routeName1 = "route-" + uid + "-663738313063"
+	routeName0 = "route-" + uid + "-633335653831"
+	routeName1 = "route-" + uid + "-363435363733"
 )
 
 func TestMakeRoute(t *testing.T) {
@@ -66,7 +76,7 @@ func TestMakeRoute(t *testing.T) {
 					Name:      routeName0,
 				},
 				Spec: routev1.RouteSpec{
-					Host: externalDomain,
+					Host: externalHost,
 					To: routev1.RouteTargetReference{
 						Kind:   "Service",
 						Name:   lbService,
@@ -108,13 +118,49 @@ func TestMakeRoute(t *testing.T) {
 						serving.RouteNamespaceLabelKey: "default",
 					},
 					Annotations: map[string]string{
-						TimeoutAnnotation: "3600s",
+						TimeoutAnnotation: "1h",
 					},
 					Namespace: lbNamespace,
 					Name:      routeName0,
 				},
 				Spec: routev1.RouteSpec{
-					Host: externalDomain,
+					Host: externalHost,
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   lbService,
+						Weight: ptr.Int32(100),
+					},
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromString(KourierHTTPPort),
+					},
+					TLS: &routev1.TLSConfig{
+						Termination:                   routev1.TLSTerminationEdge,
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+					},
+					WildcardPolicy: routev1.WildcardPolicyNone,
+				},
+			}},
+		},
+		{
+			name: "valid, no HTTP section",
+			ingress: ingress(withRules(
+				rule(withHosts([]string{localDomain, externalDomain}), withoutHTTP)),
+			),
+			want: []*routev1.Route{{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						networking.IngressLabelKey:     "ingress",
+						serving.RouteLabelKey:          "route1",
+						serving.RouteNamespaceLabelKey: "default",
+					},
+					Annotations: map[string]string{
+						TimeoutAnnotation: defaultTimeout,
+					},
+					Namespace: lbNamespace,
+					Name:      routeName0,
+				},
+				Spec: routev1.RouteSpec{
+					Host: externalHost,
 					To: routev1.RouteTargetReference{
 						Kind:   "Service",
 						Name:   lbService,
@@ -151,7 +197,7 @@ func TestMakeRoute(t *testing.T) {
 					Name:      routeName0,
 				},
 				Spec: routev1.RouteSpec{
-					Host: externalDomain,
+					Host: externalHost,
 					To: routev1.RouteTargetReference{
 						Kind:   "Service",
 						Name:   lbService,
@@ -180,7 +226,7 @@ func TestMakeRoute(t *testing.T) {
 					Name:      routeName1,
 				},
 				Spec: routev1.RouteSpec{
-					Host: externalDomain2,
+					Host: externalHost2,
 					To: routev1.RouteTargetReference{
 						Kind:   "Service",
 						Name:   lbService,
@@ -218,7 +264,7 @@ func TestMakeRoute(t *testing.T) {
 					Name:      routeName1,
 				},
 				Spec: routev1.RouteSpec{
-					Host: externalDomain2,
+					Host: externalHost2,
 					To: routev1.RouteTargetReference{
 						Kind:   "Service",
 						Name:   lbService,
@@ -236,6 +282,114 @@ func TestMakeRoute(t *testing.T) {
 				},
 			}},
 		},
+		{
+			name: "mixed case and trailing dot",
+			ingress: ingress(withRules(
+				rule(withHosts([]string{"Public.Default.DomainName."}))),
+			),
+			want: []*routev1.Route{{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						networking.IngressLabelKey:     "ingress",
+						serving.RouteLabelKey:          "route1",
+						serving.RouteNamespaceLabelKey: "default",
+					},
+					Annotations: map[string]string{
+						TimeoutAnnotation: defaultTimeout,
+					},
+					Namespace: lbNamespace,
+					Name:      routeName0,
+				},
+				Spec: routev1.RouteSpec{
+					Host: externalHost,
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   lbService,
+						Weight: ptr.Int32(100),
+					},
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromString(KourierHTTPPort),
+					},
+					TLS: &routev1.TLSConfig{
+						Termination:                   routev1.TLSTerminationEdge,
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+					},
+					WildcardPolicy: routev1.WildcardPolicyNone,
+				},
+			}},
+		},
+		{
+			name: "lowercase host",
+			ingress: ingress(withRules(
+				rule(withHosts([]string{externalHost}))),
+			),
+			want: []*routev1.Route{{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						networking.IngressLabelKey:     "ingress",
+						serving.RouteLabelKey:          "route1",
+						serving.RouteNamespaceLabelKey: "default",
+					},
+					Annotations: map[string]string{
+						TimeoutAnnotation: defaultTimeout,
+					},
+					Namespace: lbNamespace,
+					Name:      routeName0,
+				},
+				Spec: routev1.RouteSpec{
+					Host: externalHost,
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   lbService,
+						Weight: ptr.Int32(100),
+					},
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromString(KourierHTTPPort),
+					},
+					TLS: &routev1.TLSConfig{
+						Termination:                   routev1.TLSTerminationEdge,
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+					},
+					WildcardPolicy: routev1.WildcardPolicyNone,
+				},
+			}},
+		},
+		{
+			name: "equivalent hosts are deduplicated",
+			ingress: ingress(withRules(
+				rule(withHosts([]string{externalHost, "PUBLIC.default.domainname."})),
+			)),
+			want: []*routev1.Route{{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						networking.IngressLabelKey:     "ingress",
+						serving.RouteLabelKey:          "route1",
+						serving.RouteNamespaceLabelKey: "default",
+					},
+					Annotations: map[string]string{
+						TimeoutAnnotation: defaultTimeout,
+					},
+					Namespace: lbNamespace,
+					Name:      routeName0,
+				},
+				Spec: routev1.RouteSpec{
+					Host: externalHost,
+					To: routev1.RouteTargetReference{
+						Kind:   "Service",
+						Name:   lbService,
+						Weight: ptr.Int32(100),
+					},
+					Port: &routev1.RoutePort{
+						TargetPort: intstr.FromString(KourierHTTPPort),
+					},
+					TLS: &routev1.TLSConfig{
+						Termination:                   routev1.TLSTerminationEdge,
+						InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+					},
+					WildcardPolicy: routev1.WildcardPolicyNone,
+				},
+			}},
+		},
 		{
 			name: "invalid LB domain",
 			ingress: ingress(withLBInternalDomain("not.a.private.name"), withRules(
@@ -316,6 +470,10 @@ func withDisabledAnnotation(ing *networkingv1alpha1.Ingress) {
 	ing.SetAnnotations(annos)
 }
 
+func withoutLBStatus(ing *networkingv1alpha1.Ingress) {
+	ing.Status.PublicLoadBalancer = nil
+}
+
 func withLBInternalDomain(domain string) ingressOption {
 	return func(ing *networkingv1alpha1.Ingress) {
 		ing.Status.PublicLoadBalancer.Ingress[0].DomainInternal = domain
@@ -334,6 +492,10 @@ func withHosts(hosts []string) ruleOption {
 	}
 }
 
+func withoutHTTP(rule *networkingv1alpha1.IngressRule) {
+	rule.HTTP = nil
+}
+
 func withTimeout(timeout time.Duration) ruleOption {
 	return func(rule *networkingv1alpha1.IngressRule) {
 		rule.HTTP = &networkingv1alpha1.HTTPIngressRuleValue{
@@ -343,3 +505,676 @@ func withTimeout(timeout time.Duration) ruleOption {
 		}
 	}
 }
+
+func TestMakeRoutesTargetPort(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+
+	routes, err := MakeRoutes(ing, WithTargetPortFunc(func(namespace, name string) (string, string, error) {
+		if namespace != lbNamespace || name != lbService {
+			t.Errorf("got gateway %s/%s, want: %s/%s", namespace, name, lbNamespace, lbService)
+		}
+		return "http", PortSelectedByProtocol, nil
+	}))
+	if err != nil {
+		t.Fatalf("MakeRoutes() = %v", err)
+	}
+	if got, want := routes[0].Spec.Port.TargetPort, intstr.FromString("http"); got != want {
+		t.Errorf("TargetPort = %v, want: %v", got, want)
+	}
+	if got := routes[0].Annotations[GatewayPortSelectionAnnotation]; got != PortSelectedByProtocol {
+		t.Errorf("Port selection = %q, want: %q", got, PortSelectedByProtocol)
+	}
+
+	routes, err = MakeRoutes(ing, WithTargetPortFunc(func(string, string) (string, string, error) {
+		return "8080", PortSelectedByNumber, nil
+	}))
+	if err != nil {
+		t.Fatalf("MakeRoutes() = %v", err)
+	}
+	if got, want := routes[0].Spec.Port.TargetPort, intstr.FromInt(8080); got != want {
+		t.Errorf("TargetPort = %v, want number: %v", got, want)
+	}
+
+	routes, err = MakeRoutes(ing, WithTargetPortFunc(func(string, string) (string, string, error) { return "", "", nil }))
+	if err != nil {
+		t.Fatalf("MakeRoutes() = %v", err)
+	}
+	if got, want := routes[0].Spec.Port.TargetPort, intstr.FromString(KourierHTTPPort); got != want {
+		t.Errorf("TargetPort = %v, want fallback: %v", got, want)
+	}
+	if got, ok := routes[0].Annotations[GatewayPortSelectionAnnotation]; ok {
+		t.Errorf("Port selection = %q, want none for the fallback", got)
+	}
+
+	portErr := &NoGatewayPortError{Service: lbNamespace + "/" + lbService}
+	_, err = MakeRoutes(ing, WithTargetPortFunc(func(string, string) (string, string, error) { return "", "", portErr }))
+	if !errors.Is(err, portErr) {
+		t.Errorf("MakeRoutes() = %v, want: %v", err, portErr)
+	}
+}
+
+func TestRouteDisabled(t *testing.T) {
+	tests := []struct {
+		value        string
+		wantDisabled bool
+		wantErr      bool
+	}{
+		{value: "", wantDisabled: true},
+		{value: "true", wantDisabled: true},
+		{value: "True", wantDisabled: true},
+		{value: "1", wantDisabled: true},
+		{value: "false", wantDisabled: false},
+		{value: "FALSE", wantDisabled: false},
+		{value: "0", wantDisabled: false},
+		{value: "yes", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("value %q", test.value), func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = map[string]string{DisableRouteAnnotation: test.value}
+
+			routes, err := MakeRoutes(ing)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidAnnotation) {
+					t.Fatalf("MakeRoutes() = %v, want: %v", err, ErrInvalidAnnotation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			if got := len(routes) == 0; got != test.wantDisabled {
+				t.Errorf("disabled = %v, want: %v", got, test.wantDisabled)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesInvalidRoundingPolicy(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+	ing.Annotations = map[string]string{WeightRoundingAnnotation: "nearest"}
+
+	if _, err := MakeRoutes(ing); !errors.Is(err, ErrInvalidAnnotation) {
+		t.Errorf("MakeRoutes() = %v, want: %v", err, ErrInvalidAnnotation)
+	}
+}
+
+func TestMakeRoutesFallbackGateway(t *testing.T) {
+	tests := []struct {
+		name          string
+		ingress       *networkingv1alpha1.Ingress
+		wantService   string
+		wantNamespace string
+		wantErr       error
+	}{{
+		name:          "status not populated yet",
+		ingress:       ingress(withoutLBStatus, withRules(rule(withHosts([]string{externalDomain})))),
+		wantService:   "kourier",
+		wantNamespace: "knative-serving-ingress",
+	}, {
+		name:          "status populated",
+		ingress:       ingress(withRules(rule(withHosts([]string{externalDomain})))),
+		wantService:   lbService,
+		wantNamespace: lbNamespace,
+	}, {
+		name: "invalid status",
+		ingress: ingress(withLBInternalDomain("not.a.private.name"),
+			withRules(rule(withHosts([]string{externalDomain})))),
+		wantErr: ErrNoValidLoadbalancerDomain,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routes, err := MakeRoutes(test.ingress, WithFallbackGateway("knative-serving-ingress", "kourier"))
+			if err != test.wantErr {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			if got := routes[0].Spec.To.Name; got != test.wantService {
+				t.Errorf("To.Name = %q, want: %q", got, test.wantService)
+			}
+			if got := routes[0].Namespace; got != test.wantNamespace {
+				t.Errorf("Namespace = %q, want: %q", got, test.wantNamespace)
+			}
+		})
+	}
+
+	// Without a fallback, the Ingress has to be ready.
+	ing := ingress(withoutLBStatus, withRules(rule(withHosts([]string{externalDomain}))))
+	if _, err := MakeRoutes(ing); err != ErrNoValidLoadbalancerDomain {
+		t.Errorf("MakeRoutes() = %v, want: %v", err, ErrNoValidLoadbalancerDomain)
+	}
+}
+
+func TestRouteNamespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		ingress *networkingv1alpha1.Ingress
+		opts    []Option
+		want    string
+		wantErr error
+	}{{
+		name:    "valid status",
+		ingress: ingress(withRules(rule(withHosts([]string{externalDomain})))),
+		want:    lbNamespace,
+	}, {
+		name:    "status not populated yet",
+		ingress: ingress(withoutLBStatus, withRules(rule(withHosts([]string{externalDomain})))),
+		wantErr: ErrNoValidLoadbalancerDomain,
+	}, {
+		name:    "status not populated yet with fallback",
+		ingress: ingress(withoutLBStatus, withRules(rule(withHosts([]string{externalDomain})))),
+		opts:    []Option{WithFallbackGateway("knative-serving-ingress", "kourier")},
+		want:    "knative-serving-ingress",
+	}, {
+		name:    "route namespace",
+		ingress: ingress(withRules(rule(withHosts([]string{externalDomain})))),
+		opts:    []Option{WithRouteNamespace("knative-serving-ingress"), WithExternalNameIndirection()},
+		want:    "knative-serving-ingress",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := RouteNamespace(test.ingress, test.opts...)
+			if err != test.wantErr {
+				t.Fatalf("RouteNamespace() = %v, want: %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("RouteNamespace() = %q, want: %q", got, test.want)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			// The namespace has to match the one of the Routes built for the Ingress.
+			routes, err := MakeRoutes(test.ingress, test.opts...)
+			if err != nil {
+				t.Fatalf("MakeRoutes() = %v", err)
+			}
+			if routes[0].Namespace != got {
+				t.Errorf("RouteNamespace() = %q, want the namespace of the Routes %q", got, routes[0].Namespace)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesStrictTLS(t *testing.T) {
+	ingressTLS := []networkingv1alpha1.IngressTLS{{
+		Hosts:      []string{externalDomain},
+		SecretName: "cert",
+	}}
+	passthrough := map[string]string{TerminationAnnotation: string(routev1.TLSTerminationPassthrough)}
+
+	tests := []struct {
+		name            string
+		strict          bool
+		annotations     map[string]string
+		tls             []networkingv1alpha1.IngressTLS
+		opts            []Option
+		wantErr         bool
+		wantTermination routev1.TLSTerminationType
+	}{{
+		name:    "strict and missing",
+		strict:  true,
+		wantErr: true,
+	}, {
+		// The certificate of the Ingress is never copied onto the Route, so the router would
+		// serve its default certificate.
+		name:    "strict and only covered by the ingress",
+		strict:  true,
+		tls:     ingressTLS,
+		wantErr: true,
+	}, {
+		name:            "strict and issued by cert-manager",
+		strict:          true,
+		annotations:     map[string]string{CertManagerIssuerAnnotation: "letsencrypt"},
+		wantTermination: routev1.TLSTerminationEdge,
+	}, {
+		name:            "strict and served by the gateway",
+		strict:          true,
+		annotations:     passthrough,
+		tls:             ingressTLS,
+		wantTermination: routev1.TLSTerminationPassthrough,
+	}, {
+		name:        "strict and served by the gateway covered by wildcard",
+		strict:      true,
+		annotations: passthrough,
+		tls: []networkingv1alpha1.IngressTLS{{
+			Hosts:      []string{"*.default.domainName"},
+			SecretName: "cert",
+		}},
+		wantTermination: routev1.TLSTerminationPassthrough,
+	}, {
+		name:        "strict and served by the gateway for another host",
+		strict:      true,
+		annotations: passthrough,
+		tls: []networkingv1alpha1.IngressTLS{{
+			Hosts:      []string{externalDomain2},
+			SecretName: "cert",
+		}},
+		wantErr: true,
+	}, {
+		name:            "strict and apps domain",
+		strict:          true,
+		opts:            []Option{WithAppsDomain("default.domainName")},
+		wantTermination: routev1.TLSTerminationEdge,
+	}, {
+		name:            "lenient and missing",
+		wantTermination: routev1.TLSTerminationEdge,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Spec.TLS = test.tls
+			ing.Annotations = make(map[string]string, len(test.annotations))
+			for k, v := range test.annotations {
+				ing.Annotations[k] = v
+			}
+
+			routes, err := MakeRoutes(ing, append(test.opts, WithStrictTLS(test.strict))...)
+			var certErr *MissingCertificateError
+			if got := errors.As(err, &certErr); got != test.wantErr {
+				t.Fatalf("MakeRoutes() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				if certErr.Host != externalHost {
+					t.Errorf("Host = %q, want: %q", certErr.Host, externalHost)
+				}
+				return
+			}
+			if got := routes[0].Spec.TLS.Termination; got != test.wantTermination {
+				t.Errorf("Termination = %q, want: %q", got, test.wantTermination)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesPendingCertificate(t *testing.T) {
+	ingressTLS := []networkingv1alpha1.IngressTLS{{
+		Hosts:           []string{externalDomain},
+		SecretNamespace: "certs",
+		SecretName:      "cert",
+	}}
+	secretExists := func(exists bool) Option {
+		return WithSecretExistsFunc(func(namespace, name string) (bool, error) {
+			if namespace != "certs" || name != "cert" {
+				t.Errorf("Looked up secret %s/%s, want certs/cert", namespace, name)
+			}
+			return exists, nil
+		})
+	}
+
+	tests := []struct {
+		name        string
+		tls         []networkingv1alpha1.IngressTLS
+		opts        []Option
+		wantPending bool
+	}{{
+		name: "secret present",
+		tls:  ingressTLS,
+		opts: []Option{secretExists(true), WithStrictTLS(true)},
+	}, {
+		name:        "secret expected but missing",
+		tls:         ingressTLS,
+		opts:        []Option{secretExists(false)},
+		wantPending: true,
+	}, {
+		name:        "secret expected but missing with strict TLS",
+		tls:         ingressTLS,
+		opts:        []Option{secretExists(false), WithStrictTLS(true)},
+		wantPending: true,
+	}, {
+		// Without a certificate of the Ingress, there is nothing to wait for.
+		name: "no certificate configured",
+		opts: []Option{secretExists(false)},
+	}, {
+		name: "secrets not looked up",
+		tls:  ingressTLS,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Spec.TLS = test.tls
+			ing.Annotations = map[string]string{TerminationAnnotation: string(routev1.TLSTerminationPassthrough)}
+
+			routes, err := MakeRoutes(ing, test.opts...)
+			var pendingErr *PendingCertificateError
+			if got := errors.As(err, &pendingErr); got != test.wantPending {
+				t.Fatalf("MakeRoutes() = %v, wantPending: %v", err, test.wantPending)
+			}
+			if test.wantPending {
+				want := &PendingCertificateError{Host: externalHost, Secret: types.NamespacedName{Namespace: "certs", Name: "cert"}}
+				if !cmp.Equal(pendingErr, want) {
+					t.Errorf("Error = %v, want: %v", pendingErr, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("MakeRoutes() =", err)
+			}
+			if len(routes) != 1 {
+				t.Errorf("Got %d routes, want 1", len(routes))
+			}
+		})
+	}
+}
+
+func TestMakeRoutesSecretLookupError(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+	ing.Spec.TLS = []networkingv1alpha1.IngressTLS{{
+		Hosts:           []string{externalDomain},
+		SecretNamespace: "certs",
+		SecretName:      "cert",
+	}}
+	ing.Annotations = map[string]string{TerminationAnnotation: string(routev1.TLSTerminationPassthrough)}
+	lookupErr := errors.New("forbidden")
+
+	_, err := MakeRoutes(ing, WithSecretExistsFunc(func(string, string) (bool, error) {
+		return false, lookupErr
+	}))
+	if !errors.Is(err, lookupErr) {
+		t.Errorf("MakeRoutes() = %v, want an error wrapping %v", err, lookupErr)
+	}
+	var pendingErr *PendingCertificateError
+	if errors.As(err, &pendingErr) {
+		t.Errorf("MakeRoutes() = %v, want no PendingCertificateError", err)
+	}
+}
+
+func TestMakeRoutesSkipsClusterLocalHosts(t *testing.T) {
+	var skipped []string
+	routes, err := MakeRoutes(ingress(withRules(rule(withHosts([]string{localDomain, externalDomain})))),
+		WithSkipFunc(func(reason string) {
+			skipped = append(skipped, reason)
+		}))
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	if len(routes) != 1 || routes[0].Spec.Host != externalHost {
+		t.Errorf("Got %d routes, want a single route for %s", len(routes), externalHost)
+	}
+	if want := []string{`host "` + localDomain + `" is not served outside of the cluster`}; !cmp.Equal(skipped, want) {
+		t.Errorf("Skipped (-want, +got) = %s", cmp.Diff(want, skipped))
+	}
+}
+
+func TestMakeRoutesDuplicateHostVisibility(t *testing.T) {
+	// Tags and DomainMappings can list a host under both visibilities.
+	external := rule(withHosts([]string{externalDomain}))
+	local := rule(withLocalVisibilityRule, withHosts([]string{externalDomain}))
+
+	externalFirst, err := MakeRoutes(ingress(withRules(external, local)))
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	localFirst, err := MakeRoutes(ingress(withRules(local, external)))
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	if len(externalFirst) != 1 || externalFirst[0].Spec.Host != externalHost {
+		t.Errorf("Got %d routes, want a single route for %s", len(externalFirst), externalHost)
+	}
+	if !cmp.Equal(externalFirst, localFirst) {
+		t.Errorf("Routes depend on the order of rules (-external first, +local first) = %s", cmp.Diff(externalFirst, localFirst))
+	}
+}
+
+func TestMakeRoutesNoRules(t *testing.T) {
+	// The gateway isn't known yet either while Knative initializes the Ingress.
+	ing := ingress(withoutLBStatus)
+	ing.Spec.Rules = nil
+
+	routes, err := MakeRoutes(ing)
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	if routes == nil || len(routes) != 0 {
+		t.Errorf("MakeRoutes() = %v, want an empty slice", routes)
+	}
+}
+
+func TestMakeRoutesEmptyHosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		ingress       *networkingv1alpha1.Ingress
+		wantHosts     []string
+		wantGenerated int
+	}{{
+		name:          "only empty rules",
+		ingress:       ingress(withRules(rule(withHosts(nil)), rule(withHosts([]string{})))),
+		wantHosts:     []string{""},
+		wantGenerated: 1,
+	}, {
+		name:          "empty rule next to a cluster-local rule",
+		ingress:       ingress(withRules(rule(withHosts(nil)), rule(withLocalVisibilityRule))),
+		wantHosts:     []string{""},
+		wantGenerated: 1,
+	}, {
+		name:          "empty rule next to a valid rule",
+		ingress:       ingress(withRules(rule(withHosts(nil)), rule(withHosts([]string{externalDomain})))),
+		wantHosts:     []string{"", externalHost},
+		wantGenerated: 1,
+	}, {
+		name:    "empty cluster-local rule",
+		ingress: ingress(withRules(rule(withHosts(nil), withLocalVisibilityRule))),
+	}, {
+		name:    "no rules",
+		ingress: ingress(),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routes, err := MakeRoutes(test.ingress)
+			if err != nil {
+				t.Fatal("MakeRoutes() =", err)
+			}
+			hosts := make([]string, 0, len(routes))
+			generated := 0
+			for _, route := range routes {
+				hosts = append(hosts, route.Spec.Host)
+				if route.Annotations[HostGeneratedAnnotation] == "true" {
+					generated++
+				}
+			}
+			if !cmp.Equal(hosts, test.wantHosts, cmpopts.EquateEmpty()) {
+				t.Errorf("Hosts (-want, +got) = %s", cmp.Diff(test.wantHosts, hosts, cmpopts.EquateEmpty()))
+			}
+			if generated != test.wantGenerated {
+				t.Errorf("Got %d routes with generated hosts, want: %d", generated, test.wantGenerated)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesGeneratedHost(t *testing.T) {
+	r := rule(withHosts(nil), withTimeout(5*time.Minute))
+	route, err := makeRoute(ingress(), "", r, hostRoutePath(r), newOptions([]Option{
+		WithStrictTLS(true),
+		WithExternalDNS(ExternalDNS{}),
+	}))
+	if err != nil {
+		t.Fatal("makeRoute() =", err)
+	}
+
+	// OpenShift generates the host as <name>-<namespace>, which has to fit into a DNS label.
+	if label := route.Name + "-" + route.Namespace; len(label) > validation.DNS1123LabelMaxLength {
+		t.Errorf("Generated host label %q is longer than %d characters", label, validation.DNS1123LabelMaxLength)
+	}
+	if !strings.HasPrefix(route.Name, "ingress-") {
+		t.Errorf("Name = %q, want it to start with the name of the ingress", route.Name)
+	}
+	if got := route.Annotations[TimeoutAnnotation]; got != "5m" {
+		t.Errorf("Timeout = %q, want: %q", got, "5m")
+	}
+	// Generated hosts are part of the router's domain, so they neither need a certificate of
+	// their own nor DNS records.
+	for key := range route.Annotations {
+		if strings.HasPrefix(key, "external-dns.alpha.kubernetes.io/") {
+			t.Errorf("Route with generated host has external-dns annotation %s", key)
+		}
+	}
+}
+
+func TestGeneratedHostRouteName(t *testing.T) {
+	ing := ingress()
+	ing.Name = strings.Repeat("a", 63)
+	for _, namespace := range []string{"knative-serving-ingress", "ns", strings.Repeat("n", 62)} {
+		name := generatedHostRouteName(ing, namespace)
+		if len(namespace) < 50 && len(name)+len(namespace)+1 > validation.DNS1123LabelMaxLength {
+			t.Errorf("generatedHostRouteName(%q) = %q, too long for a generated host", namespace, name)
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			t.Errorf("generatedHostRouteName(%q) = %q, not a valid name: %v", namespace, name, errs)
+		}
+	}
+
+	other := ingress()
+	other.UID = "other"
+	if generatedHostRouteName(ingress(), "ns") == generatedHostRouteName(other, "ns") {
+		t.Error("Ingresses of the same name in different namespaces share a route name")
+	}
+}
+
+func TestPreserveGeneratedHost(t *testing.T) {
+	generated := &routev1.Route{}
+	generated.Annotations = map[string]string{HostGeneratedAnnotation: "true"}
+	existing := &routev1.Route{Spec: routev1.RouteSpec{Host: "route1-abcdef-knative-serving-ingress.apps.example.com"}}
+
+	PreserveGeneratedHost(generated, existing)
+	if generated.Spec.Host != existing.Spec.Host {
+		t.Errorf("Host = %q, want the generated host %q", generated.Spec.Host, existing.Spec.Host)
+	}
+
+	explicit := &routev1.Route{Spec: routev1.RouteSpec{Host: externalHost}}
+	PreserveGeneratedHost(explicit, existing)
+	if explicit.Spec.Host != externalHost {
+		t.Errorf("Host = %q, want: %q", explicit.Spec.Host, externalHost)
+	}
+}
+
+func TestMakeRoutesMixedVisibilitySplit(t *testing.T) {
+	// A canary between an external and a cluster-local version of a service.
+	external := rule(withHosts([]string{externalDomain}))
+	external.HTTP.Paths[0].Splits = []networkingv1alpha1.IngressBackendSplit{{
+		IngressBackend: networkingv1alpha1.IngressBackend{ServiceName: "external", ServiceNamespace: "default"},
+		Percent:        80,
+	}, {
+		IngressBackend: networkingv1alpha1.IngressBackend{ServiceName: "internal", ServiceNamespace: "default"},
+		Percent:        20,
+	}}
+	local := rule(withLocalVisibilityRule, withHosts([]string{localDomain}))
+	local.HTTP.Paths[0].Splits = []networkingv1alpha1.IngressBackendSplit{{
+		IngressBackend: networkingv1alpha1.IngressBackend{ServiceName: "internal", ServiceNamespace: "default"},
+		Percent:        100,
+	}}
+
+	ing := ingress(withRules(external, local))
+	ing.Status.PrivateLoadBalancer = &networkingv1alpha1.LoadBalancerStatus{
+		Ingress: []networkingv1alpha1.LoadBalancerIngressStatus{{
+			DomainInternal: "kourier-internal.knative-serving-ingress.svc.cluster.local",
+		}},
+	}
+
+	routes, err := MakeRoutes(ing)
+	if err != nil {
+		t.Fatalf("MakeRoutes() = %v", err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("got %d routes, want: 1", len(routes))
+	}
+	// The gateway applies the split, including the cluster-local target.
+	want := routev1.RouteTargetReference{Kind: "Service", Name: lbService, Weight: ptr.Int32(100)}
+	if !cmp.Equal(routes[0].Spec.To, want) {
+		t.Errorf("To (-want, +got) = %s", cmp.Diff(want, routes[0].Spec.To))
+	}
+	if len(routes[0].Spec.AlternateBackends) != 0 {
+		t.Errorf("AlternateBackends = %v, want none", routes[0].Spec.AlternateBackends)
+	}
+	if routes[0].Namespace != lbNamespace {
+		t.Errorf("Namespace = %q, want: %q", routes[0].Namespace, lbNamespace)
+	}
+}
+
+func TestMakeRoutesGatewayServiceOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotation  *string
+		opts        []Option
+		noLBStatus  bool
+		wantGateway types.NamespacedName
+		wantErr     error
+	}{{
+		name:        "resolved from the LoadBalancer status",
+		wantGateway: types.NamespacedName{Namespace: lbNamespace, Name: lbService},
+	}, {
+		name:        "override",
+		annotation:  ptr.String("custom-gateway"),
+		wantGateway: types.NamespacedName{Namespace: lbNamespace, Name: "custom-gateway"},
+	}, {
+		name:        "override of the fallback gateway",
+		annotation:  ptr.String("custom-gateway"),
+		opts:        []Option{WithFallbackGateway("fallback-namespace", "kourier")},
+		noLBStatus:  true,
+		wantGateway: types.NamespacedName{Namespace: "fallback-namespace", Name: "custom-gateway"},
+	}, {
+		name:       "namespaced override",
+		annotation: ptr.String("other-namespace/custom-gateway"),
+		wantErr:    ErrInvalidAnnotation,
+	}, {
+		name:       "empty override",
+		annotation: ptr.String(""),
+		wantErr:    ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			if test.annotation != nil {
+				ing.Annotations = map[string]string{GatewayServiceAnnotation: *test.annotation}
+			}
+			if test.noLBStatus {
+				withoutLBStatus(ing)
+			}
+
+			routes, err := MakeRoutes(ing, test.opts...)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			got := types.NamespacedName{Namespace: routes[0].Namespace, Name: routes[0].Spec.To.Name}
+			if got != test.wantGateway {
+				t.Errorf("Route targets %v, want: %v", got, test.wantGateway)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesMissingGatewayServiceOverride(t *testing.T) {
+	exists := func(namespace, name string) bool {
+		return name == lbService
+	}
+
+	ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+	ing.Annotations = map[string]string{GatewayServiceAnnotation: "kourier-experimental"}
+	_, err := MakeRoutes(ing, WithServiceExistsFunc(exists))
+	var gatewayErr *MissingGatewayServiceError
+	if !errors.As(err, &gatewayErr) {
+		t.Fatalf("MakeRoutes() = %v, want a MissingGatewayServiceError", err)
+	}
+	if want := (types.NamespacedName{Namespace: lbNamespace, Name: "kourier-experimental"}); gatewayErr.Gateway != want {
+		t.Errorf("Gateway = %v, want: %v", gatewayErr.Gateway, want)
+	}
+
+	// The gateway of the LoadBalancer status isn't checked, Kourier reports it once it's ready.
+	if _, err := MakeRoutes(ingress(withRules(rule(withHosts([]string{externalDomain})))), WithServiceExistsFunc(func(string, string) bool {
+		return false
+	})); err != nil {
+		t.Errorf("MakeRoutes() without override = %v", err)
+	}
+}
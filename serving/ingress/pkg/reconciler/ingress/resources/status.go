@@ -0,0 +1,83 @@
+package resources
+
+import (
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IsRouteAdmitted returns true if at least one router has admitted the given Route.
+func IsRouteAdmitted(route *routev1.Route) bool {
+	for _, ingress := range route.Status.Ingress {
+		if isAdmitted(ingress) {
+			return true
+		}
+	}
+	return false
+}
+
+// RouterCanonicalHostname returns the canonical hostname of the first router that admitted
+// the given Route. This is the externally reachable name users need to point their DNS to.
+// An empty string is returned if the Route has not been admitted or the router did not
+// report a canonical hostname.
+func RouterCanonicalHostname(route *routev1.Route) string {
+	for _, ingress := range route.Status.Ingress {
+		if isAdmitted(ingress) && ingress.RouterCanonicalHostname != "" {
+			return ingress.RouterCanonicalHostname
+		}
+	}
+	return ""
+}
+
+// RouteFailedSince returns the time since which the given Route has been rejected by all routers
+// that reported a status for it. False is returned if the Route is admitted or still pending.
+func RouteFailedSince(route *routev1.Route) (time.Time, bool) {
+	if IsRouteAdmitted(route) {
+		return time.Time{}, false
+	}
+
+	var since time.Time
+	failed := false
+	for _, ingress := range route.Status.Ingress {
+		for _, cond := range ingress.Conditions {
+			if cond.Type != routev1.RouteAdmitted || cond.Status != corev1.ConditionFalse {
+				continue
+			}
+			failed = true
+			// Take the latest rejection, the Route has failed on all routers since.
+			if cond.LastTransitionTime != nil && cond.LastTransitionTime.Time.After(since) {
+				since = cond.LastTransitionTime.Time
+			}
+		}
+	}
+	return since, failed
+}
+
+// RouteAdmittedSince returns the time the given Route was first admitted by a router. False is
+// returned if no router admitted it yet.
+func RouteAdmittedSince(route *routev1.Route) (time.Time, bool) {
+	var since time.Time
+	admitted := false
+	for _, ingress := range route.Status.Ingress {
+		for _, cond := range ingress.Conditions {
+			if cond.Type != routev1.RouteAdmitted || cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			admitted = true
+			if cond.LastTransitionTime != nil && (since.IsZero() || cond.LastTransitionTime.Time.Before(since)) {
+				since = cond.LastTransitionTime.Time
+			}
+		}
+	}
+	return since, admitted
+}
+
+func isAdmitted(ingress routev1.RouteIngress) bool {
+	for _, cond := range ingress.Conditions {
+		if cond.Type == routev1.RouteAdmitted && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRouterCanonicalHostname(t *testing.T) {
+	tests := []struct {
+		name         string
+		ingress      []routev1.RouteIngress
+		wantAdmitted bool
+		want         string
+	}{{
+		name: "no status",
+	}, {
+		name: "not admitted",
+		ingress: []routev1.RouteIngress{{
+			RouterCanonicalHostname: "router-default.apps.example.com",
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:   routev1.RouteAdmitted,
+				Status: corev1.ConditionFalse,
+			}},
+		}},
+	}, {
+		name: "admitted",
+		ingress: []routev1.RouteIngress{{
+			RouterCanonicalHostname: "router-default.apps.example.com",
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:   routev1.RouteAdmitted,
+				Status: corev1.ConditionTrue,
+			}},
+		}},
+		wantAdmitted: true,
+		want:         "router-default.apps.example.com",
+	}, {
+		name: "admitted without canonical hostname",
+		ingress: []routev1.RouteIngress{{
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:   routev1.RouteAdmitted,
+				Status: corev1.ConditionTrue,
+			}},
+		}},
+		wantAdmitted: true,
+	}, {
+		name: "second router admitted",
+		ingress: []routev1.RouteIngress{{
+			RouterCanonicalHostname: "router-sharded.apps.example.com",
+		}, {
+			RouterCanonicalHostname: "router-default.apps.example.com",
+			Conditions: []routev1.RouteIngressCondition{{
+				Type:   routev1.RouteAdmitted,
+				Status: corev1.ConditionTrue,
+			}},
+		}},
+		wantAdmitted: true,
+		want:         "router-default.apps.example.com",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			route := &routev1.Route{Status: routev1.RouteStatus{Ingress: test.ingress}}
+			if got := IsRouteAdmitted(route); got != test.wantAdmitted {
+				t.Errorf("IsRouteAdmitted() = %v, want: %v", got, test.wantAdmitted)
+			}
+			if got := RouterCanonicalHostname(route); got != test.want {
+				t.Errorf("RouterCanonicalHostname() = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRouteFailedSince(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Minute))
+
+	rejected := func(since metav1.Time) routev1.RouteIngress {
+		return routev1.RouteIngress{Conditions: []routev1.RouteIngressCondition{{
+			Type:               routev1.RouteAdmitted,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: &since,
+		}}}
+	}
+	admitted := routev1.RouteIngress{Conditions: []routev1.RouteIngressCondition{{
+		Type:   routev1.RouteAdmitted,
+		Status: corev1.ConditionTrue,
+	}}}
+
+	tests := []struct {
+		name       string
+		ingress    []routev1.RouteIngress
+		wantFailed bool
+		wantSince  time.Time
+	}{{
+		name: "pending",
+	}, {
+		name:    "admitted",
+		ingress: []routev1.RouteIngress{admitted},
+	}, {
+		name:    "admitted by another router",
+		ingress: []routev1.RouteIngress{rejected(earlier), admitted},
+	}, {
+		name:       "rejected",
+		ingress:    []routev1.RouteIngress{rejected(earlier)},
+		wantFailed: true,
+		wantSince:  earlier.Time,
+	}, {
+		name:       "rejected by all routers",
+		ingress:    []routev1.RouteIngress{rejected(later), rejected(earlier)},
+		wantFailed: true,
+		wantSince:  later.Time,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			route := &routev1.Route{Status: routev1.RouteStatus{Ingress: test.ingress}}
+			since, failed := RouteFailedSince(route)
+			if failed != test.wantFailed {
+				t.Errorf("failed = %v, want: %v", failed, test.wantFailed)
+			}
+			if !since.Equal(test.wantSince) {
+				t.Errorf("since = %v, want: %v", since, test.wantSince)
+			}
+		})
+	}
+}
+
+func TestRouteAdmittedSince(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Minute))
+
+	admitted := func(since metav1.Time) routev1.RouteIngress {
+		return routev1.RouteIngress{Conditions: []routev1.RouteIngressCondition{{
+			Type:               routev1.RouteAdmitted,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: &since,
+		}}}
+	}
+	rejected := routev1.RouteIngress{Conditions: []routev1.RouteIngressCondition{{
+		Type:               routev1.RouteAdmitted,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: &earlier,
+	}}}
+
+	tests := []struct {
+		name         string
+		ingress      []routev1.RouteIngress
+		wantAdmitted bool
+		wantSince    time.Time
+	}{{
+		name: "pending",
+	}, {
+		name:    "rejected",
+		ingress: []routev1.RouteIngress{rejected},
+	}, {
+		name:         "admitted",
+		ingress:      []routev1.RouteIngress{admitted(later)},
+		wantAdmitted: true,
+		wantSince:    later.Time,
+	}, {
+		name:         "admitted by several routers",
+		ingress:      []routev1.RouteIngress{admitted(later), rejected, admitted(earlier)},
+		wantAdmitted: true,
+		wantSince:    earlier.Time,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			route := &routev1.Route{Status: routev1.RouteStatus{Ingress: test.ingress}}
+			since, admitted := RouteAdmittedSince(route)
+			if admitted != test.wantAdmitted {
+				t.Errorf("admitted = %v, want: %v", admitted, test.wantAdmitted)
+			}
+			if !since.Equal(test.wantSince) {
+				t.Errorf("since = %v, want: %v", since, test.wantSince)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	networkingpkg "knative.dev/networking/pkg"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// TagLabelKey is the label carrying the traffic tag of the Knative Service a Route serves.
+// Routes of untagged hosts don't carry it.
+const TagLabelKey = "serving.knative.dev/tag"
+
+// trafficTag returns the traffic tag the given host of the rule serves, or an empty string if
+// the host serves the untagged target of the Knative Route.
+//
+// Knative sets the tag header on the paths of tagged rules if tag header based routing is
+// enabled. Otherwise, the tag is derived from the other rules of the Ingress: Knative renders the
+// host of a tag from the same domain template as the untagged host, only with the tag prefixed to
+// the name of the Route. So a host is tagged if another rule has the host without that prefix,
+// like svc-ns.apps.example.com for latest-svc-ns.apps.example.com.
+func trafficTag(ci *networkingv1alpha1.Ingress, rule networkingv1alpha1.IngressRule, host string) string {
+	if rule.HTTP != nil {
+		for _, path := range rule.HTTP.Paths {
+			if tag := path.AppendHeaders[networkingpkg.TagHeaderName]; tag != "" {
+				return tag
+			}
+		}
+	}
+
+	route := ci.Labels[serving.RouteLabelKey]
+	if route == "" {
+		return ""
+	}
+	own := normalizedHosts(rule)
+	untagged := sets.NewString()
+	for _, other := range ci.Spec.Rules {
+		untagged.Insert(normalizedHosts(other).Difference(own).UnsortedList()...)
+	}
+
+	// The name of the Route might occur more than once, like in foo-foo.apps.example.com for the
+	// Route foo in the namespace foo, so every occurrence is tried.
+	suffix := "-" + route
+	for start := 0; ; {
+		i := strings.Index(host[start:], suffix)
+		if i < 0 {
+			return ""
+		}
+		i += start
+		labelStart := strings.LastIndex(host[:i], ".") + 1
+		if tag := host[labelStart:i]; tag != "" && untagged.Has(host[:labelStart]+host[i+1:]) {
+			return tag
+		}
+		start = i + 1
+	}
+}
+
+// normalizedHosts returns the normalized hosts of the given rule.
+func normalizedHosts(rule networkingv1alpha1.IngressRule) sets.String {
+	hosts := sets.NewString()
+	for _, host := range rule.Hosts {
+		hosts.Insert(NormalizeHost(host))
+	}
+	return hosts
+}
@@ -0,0 +1,151 @@
+package resources
+
+import (
+	"testing"
+
+	networkingpkg "knative.dev/networking/pkg"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+func TestTrafficTag(t *testing.T) {
+	// Knative's default domain template, {{.Name}}.{{.Namespace}}.{{.Domain}}.
+	defaultTemplate := []string{
+		"route1.default.domainname",
+		"latest-route1.default.domainname",
+		"my-tag-route1.default.domainname",
+	}
+	// The {{.Name}}-{{.Namespace}}.{{.Domain}} template used on OpenShift.
+	nameNamespaceTemplate := []string{
+		"route1-default.apps.example.com",
+		"latest-route1-default.apps.example.com",
+	}
+
+	tests := []struct {
+		name  string
+		route string
+		hosts []string
+		host  string
+		want  string
+	}{{
+		name:  "untagged",
+		hosts: defaultTemplate,
+		host:  "route1.default.domainname",
+	}, {
+		name:  "tagged",
+		hosts: defaultTemplate,
+		host:  "latest-route1.default.domainname",
+		want:  "latest",
+	}, {
+		name:  "tag with dashes",
+		hosts: defaultTemplate,
+		host:  "my-tag-route1.default.domainname",
+		want:  "my-tag",
+	}, {
+		name:  "untagged name-namespace host",
+		hosts: nameNamespaceTemplate,
+		host:  "route1-default.apps.example.com",
+	}, {
+		name:  "tagged name-namespace host",
+		hosts: nameNamespaceTemplate,
+		host:  "latest-route1-default.apps.example.com",
+		want:  "latest",
+	}, {
+		name:  "namespace named like the route",
+		route: "foo",
+		hosts: []string{"foo-foo.apps.example.com", "latest-foo-foo.apps.example.com"},
+		host:  "foo-foo.apps.example.com",
+	}, {
+		name:  "tagged host of a namespace named like the route",
+		route: "foo",
+		hosts: []string{"foo-foo.apps.example.com", "latest-foo-foo.apps.example.com"},
+		host:  "latest-foo-foo.apps.example.com",
+		want:  "latest",
+	}, {
+		name:  "no untagged host",
+		hosts: []string{"latest-route1.default.domainname"},
+		host:  "latest-route1.default.domainname",
+	}, {
+		name:  "other route",
+		hosts: []string{"route2.default.domainname", "latest-route2.default.domainname"},
+		host:  "latest-route2.default.domainname",
+	}, {
+		name:  "route name in another label",
+		hosts: []string{"foo.route1.domainname", "foo.latest-route1.domainname"},
+		host:  "foo.latest-route1.domainname",
+		want:  "latest",
+	}, {
+		name:  "bare suffix",
+		hosts: []string{"route1.default.domainname", "-route1.default.domainname"},
+		host:  "-route1.default.domainname",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Knative generates a rule per tag.
+			rules := make([]networkingv1alpha1.IngressRule, 0, len(test.hosts))
+			var hostRule networkingv1alpha1.IngressRule
+			for _, host := range test.hosts {
+				r := rule(withHosts([]string{host}))
+				if host == test.host {
+					hostRule = r
+				}
+				rules = append(rules, r)
+			}
+			ing := ingress(withRules(rules...))
+			if test.route != "" {
+				ing.Labels[serving.RouteLabelKey] = test.route
+			}
+
+			if got := trafficTag(ing, hostRule, test.host); got != test.want {
+				t.Errorf("trafficTag(%q) = %q, want: %q", test.host, got, test.want)
+			}
+		})
+	}
+
+	ing := ingress(withRules(rule(withHosts(defaultTemplate[:2]))))
+	delete(ing.Labels, serving.RouteLabelKey)
+	if got := trafficTag(ing, ing.Spec.Rules[0], "latest-route1.default.domainname"); got != "" {
+		t.Errorf("trafficTag() without route label = %q, want: %q", got, "")
+	}
+}
+
+func TestTrafficTagHeader(t *testing.T) {
+	// With tag header based routing, the tag is taken from the header, whatever the host.
+	tagged := rule(withHosts([]string{"custom.example.com"}))
+	tagged.HTTP.Paths[0].AppendHeaders = map[string]string{networkingpkg.TagHeaderName: "latest"}
+	ing := ingress(withRules(rule(withHosts([]string{"route1.default.domainname"})), tagged))
+
+	if got := trafficTag(ing, tagged, "custom.example.com"); got != "latest" {
+		t.Errorf("trafficTag() = %q, want: %q", got, "latest")
+	}
+}
+
+func TestMakeRoutesTrafficTags(t *testing.T) {
+	ing := ingress(withRules(
+		rule(withHosts([]string{"route1.default.domainname"})),
+		rule(withHosts([]string{"latest-route1.default.domainname"})),
+		rule(withHosts([]string{"previous-route1.default.domainname"})),
+	))
+
+	routes, err := MakeRoutes(ing)
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("got %d routes, want: 3", len(routes))
+	}
+
+	if _, ok := routes[0].Labels[TagLabelKey]; ok {
+		t.Errorf("Route of untagged host has label %s", TagLabelKey)
+	}
+	tagged := routes[1:]
+	if tagged[0].Name == tagged[1].Name {
+		t.Errorf("Routes of both tags are named %q", tagged[0].Name)
+	}
+	for i, want := range []string{"latest", "previous"} {
+		if got := tagged[i].Labels[TagLabelKey]; got != want {
+			t.Errorf("Label %s of route for %s = %q, want: %q", TagLabelKey, tagged[i].Spec.Host, got, want)
+		}
+	}
+}
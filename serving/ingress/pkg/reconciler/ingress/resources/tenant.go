@@ -0,0 +1,54 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// TenantLabel labels Routes with the tenant of the namespace of their Ingress, so that routers
+// can be sharded by tenant.
+type TenantLabel struct {
+	// Key is the key of the label.
+	Key string
+
+	// Tenants maps namespaces to their tenant. Namespaces missing from it are their own tenant.
+	Tenants map[string]string
+}
+
+// Validate returns an error if the label key or one of the mapped tenants isn't a legal label.
+func (t *TenantLabel) Validate() error {
+	if errs := validation.IsQualifiedName(t.Key); len(errs) > 0 {
+		return fmt.Errorf("invalid label key %q: %s", t.Key, strings.Join(errs, "; "))
+	}
+	for namespace, tenant := range t.Tenants {
+		if err := validateTenant(tenant); err != nil {
+			return fmt.Errorf("invalid tenant of namespace %s: %w", namespace, err)
+		}
+	}
+	return nil
+}
+
+// tenant returns the tenant of the given namespace.
+func (t *TenantLabel) tenant(namespace string) (string, error) {
+	tenant, ok := t.Tenants[namespace]
+	if !ok {
+		tenant = namespace
+	}
+	if err := validateTenant(tenant); err != nil {
+		return "", fmt.Errorf("invalid tenant of namespace %s: %w", namespace, err)
+	}
+	return tenant, nil
+}
+
+// validateTenant returns an error if the given tenant isn't a legal label value.
+func validateTenant(tenant string) error {
+	if tenant == "" {
+		return fmt.Errorf("tenant must not be empty")
+	}
+	if errs := validation.IsValidLabelValue(tenant); len(errs) > 0 {
+		return fmt.Errorf("invalid label value %q: %s", tenant, strings.Join(errs, "; "))
+	}
+	return nil
+}
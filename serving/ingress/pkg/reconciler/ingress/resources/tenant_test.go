@@ -0,0 +1,88 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestMakeRoutesTenantLabel(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		label     TenantLabel
+		want      string
+		wantErr   bool
+	}{{
+		name:      "namespace is the tenant",
+		namespace: "shop-dev",
+		label:     TenantLabel{Key: "tenant"},
+		want:      "shop-dev",
+	}, {
+		name:      "mapped namespace",
+		namespace: "shop-dev",
+		label:     TenantLabel{Key: "example.com/tenant", Tenants: map[string]string{"shop-dev": "shop"}},
+		want:      "shop",
+	}, {
+		name:      "unmapped namespace",
+		namespace: "billing",
+		label:     TenantLabel{Key: "tenant", Tenants: map[string]string{"shop-dev": "shop"}},
+		want:      "billing",
+	}, {
+		name:      "illegal mapped tenant",
+		namespace: "shop-dev",
+		label:     TenantLabel{Key: "tenant", Tenants: map[string]string{"shop-dev": "shop/dev"}},
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))), func(i *networkingv1alpha1.Ingress) {
+				i.Namespace = test.namespace
+				i.Labels[test.label.Key] = "spoofed"
+			})
+
+			routes, err := MakeRoutes(ing, WithTenantLabel(test.label))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("MakeRoutes() = %v, want error: %v", err, test.wantErr)
+			}
+			for _, route := range routes {
+				if got := route.Labels[test.label.Key]; got != test.want {
+					t.Errorf("%s = %q, want %q", test.label.Key, got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTenantLabelValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		label   TenantLabel
+		wantErr bool
+	}{{
+		name:  "valid",
+		label: TenantLabel{Key: "example.com/tenant", Tenants: map[string]string{"shop-dev": "shop"}},
+	}, {
+		name:    "illegal key",
+		label:   TenantLabel{Key: "tenant!"},
+		wantErr: true,
+	}, {
+		name:    "empty tenant",
+		label:   TenantLabel{Key: "tenant", Tenants: map[string]string{"shop-dev": ""}},
+		wantErr: true,
+	}, {
+		name:    "tenant too long",
+		label:   TenantLabel{Key: "tenant", Tenants: map[string]string{"shop-dev": strings.Repeat("a", 64)}},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.label.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, want error: %v", err, test.wantErr)
+			}
+		})
+	}
+}
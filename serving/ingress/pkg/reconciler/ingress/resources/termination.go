@@ -0,0 +1,182 @@
+package resources
+
+import (
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// TerminationAnnotation sets the TLS termination of the Routes of an Ingress to one of "edge",
+// "passthrough" or "reencrypt". It takes precedence over the TerminationPolicy set by
+// WithTerminationPolicy and the TLSDefaults set by WithTLSDefaults.
+//
+// With "passthrough" and "reencrypt", the router forwards TLS to the HTTPS port of the gateway,
+// which has to serve a certificate for the host, for example from the TLS section of the Ingress.
+const TerminationAnnotation = "serving.knative.openshift.io/tlsTermination"
+
+// InsecurePolicyAnnotation sets how the Routes of an Ingress handle plain HTTP to one of "Allow",
+// "Redirect", "None" or "Unset". It takes precedence over the TLSDefaults set by WithTLSDefaults.
+const InsecurePolicyAnnotation = "serving.knative.openshift.io/insecureEdgeTerminationPolicy"
+
+// InsecurePolicyUnset leaves the insecure edge termination policy of Routes unset, so that the
+// router's default applies. It's only supported with edge and reencrypt termination.
+const InsecurePolicyUnset routev1.InsecureEdgeTerminationPolicyType = "Unset"
+
+// KourierHTTPSPort is the name of the HTTPS port of Kourier's gateway Services.
+const KourierHTTPSPort = "https"
+
+// TerminationPolicy maps host suffixes to the TLS termination of the Routes of their hosts. The
+// longest suffix matching a host wins. Hosts without a match use the termination of the
+// TLSDefaults, edge by default.
+type TerminationPolicy map[string]routev1.TLSTerminationType
+
+// TLSDefaults are the TLS settings of Routes that neither their Ingress nor the TerminationPolicy
+// set.
+type TLSDefaults struct {
+	// Termination is the TLS termination of hosts without a match in the TerminationPolicy.
+	// Edge if empty.
+	Termination routev1.TLSTerminationType
+
+	// InsecurePolicy is how plain HTTP is handled. If empty, it's allowed for edge and reencrypt
+	// and redirected for passthrough.
+	InsecurePolicy routev1.InsecureEdgeTerminationPolicyType
+
+	// DestinationCACertificate is the PEM encoded CA the router verifies the certificate of the
+	// gateway with on reencrypt.
+	DestinationCACertificate string
+}
+
+// Validate returns an error if the defaults can't be applied to Routes.
+func (d *TLSDefaults) Validate() error {
+	if d.Termination != "" {
+		if _, err := ParseTermination(string(d.Termination)); err != nil {
+			return err
+		}
+	}
+	if d.InsecurePolicy != "" {
+		if err := validateInsecurePolicy(d.termination(), d.InsecurePolicy); err != nil {
+			return err
+		}
+	}
+	if d.DestinationCACertificate != "" {
+		if block, _ := pem.Decode([]byte(d.DestinationCACertificate)); block == nil {
+			return fmt.Errorf("destination CA certificate must be PEM encoded")
+		}
+	}
+	return nil
+}
+
+// termination returns the default termination, edge if unset.
+func (d *TLSDefaults) termination() routev1.TLSTerminationType {
+	if d.Termination == "" {
+		return routev1.TLSTerminationEdge
+	}
+	return d.Termination
+}
+
+// ParseTermination parses the TLS termination of a Route.
+func ParseTermination(value string) (routev1.TLSTerminationType, error) {
+	switch termination := routev1.TLSTerminationType(strings.ToLower(value)); termination {
+	case routev1.TLSTerminationEdge, routev1.TLSTerminationPassthrough, routev1.TLSTerminationReencrypt:
+		return termination, nil
+	default:
+		return "", fmt.Errorf("termination %q must be one of %q, %q or %q", value,
+			routev1.TLSTerminationEdge, routev1.TLSTerminationPassthrough, routev1.TLSTerminationReencrypt)
+	}
+}
+
+// termination returns the termination of the longest suffix matching the given host.
+func (p TerminationPolicy) termination(host string) (routev1.TLSTerminationType, bool) {
+	var match string
+	for suffix := range p {
+		if (host == suffix || strings.HasSuffix(host, "."+suffix)) && len(suffix) > len(match) {
+			match = suffix
+		}
+	}
+	if match == "" {
+		return "", false
+	}
+	return p[match], true
+}
+
+// routeTermination returns the TLS termination of the Route for the given host.
+func routeTermination(annotations map[string]string, host string, policy TerminationPolicy, defaults TLSDefaults) (routev1.TLSTerminationType, error) {
+	if value, ok := annotations[TerminationAnnotation]; ok {
+		termination, err := ParseTermination(value)
+		if err != nil {
+			return "", fmt.Errorf("%w %s: %v", ErrInvalidAnnotation, TerminationAnnotation, err)
+		}
+		return termination, nil
+	}
+	if termination, ok := policy.termination(host); ok {
+		return termination, nil
+	}
+	return defaults.termination(), nil
+}
+
+// ParseInsecurePolicy parses how a Route handles plain HTTP.
+func ParseInsecurePolicy(value string) (routev1.InsecureEdgeTerminationPolicyType, error) {
+	for _, policy := range []routev1.InsecureEdgeTerminationPolicyType{
+		routev1.InsecureEdgeTerminationPolicyAllow,
+		routev1.InsecureEdgeTerminationPolicyRedirect,
+		routev1.InsecureEdgeTerminationPolicyNone,
+		InsecurePolicyUnset,
+	} {
+		if strings.EqualFold(value, string(policy)) {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("insecure policy %q must be one of %q, %q, %q or %q", value,
+		routev1.InsecureEdgeTerminationPolicyAllow, routev1.InsecureEdgeTerminationPolicyRedirect, routev1.InsecureEdgeTerminationPolicyNone, InsecurePolicyUnset)
+}
+
+// validateInsecurePolicy returns an error if the router doesn't support the given policy with
+// the given termination. The router can't serve plain HTTP on a passthrough host, and leaving the
+// policy unset is only supported for edge and reencrypt.
+func validateInsecurePolicy(termination routev1.TLSTerminationType, policy routev1.InsecureEdgeTerminationPolicyType) error {
+	if termination == routev1.TLSTerminationPassthrough && (policy == routev1.InsecureEdgeTerminationPolicyAllow || policy == InsecurePolicyUnset) {
+		return fmt.Errorf("insecure policy %q is not supported with termination %q", policy, termination)
+	}
+	return nil
+}
+
+// routeInsecurePolicy returns how the Route with the given termination handles plain HTTP.
+func routeInsecurePolicy(annotations map[string]string, termination routev1.TLSTerminationType, defaults TLSDefaults) (routev1.InsecureEdgeTerminationPolicyType, error) {
+	if value, ok := annotations[InsecurePolicyAnnotation]; ok {
+		policy, err := ParseInsecurePolicy(value)
+		if err == nil {
+			err = validateInsecurePolicy(termination, policy)
+		}
+		if err != nil {
+			return "", fmt.Errorf("%w %s: %v", ErrInvalidAnnotation, InsecurePolicyAnnotation, err)
+		}
+		return policy, nil
+	}
+	// The defaults are only validated against the default termination, the TerminationPolicy
+	// or the Ingress may still pick passthrough.
+	if defaults.InsecurePolicy != "" && validateInsecurePolicy(termination, defaults.InsecurePolicy) == nil {
+		return defaults.InsecurePolicy, nil
+	}
+	if termination == routev1.TLSTerminationPassthrough {
+		return routev1.InsecureEdgeTerminationPolicyRedirect, nil
+	}
+	return routev1.InsecureEdgeTerminationPolicyAllow, nil
+}
+
+// tlsConfig returns the TLS config of a Route with the given termination and insecure policy.
+// The destination CA is only used on reencrypt, the router rejects it otherwise.
+func tlsConfig(termination routev1.TLSTerminationType, insecure routev1.InsecureEdgeTerminationPolicyType, destinationCA string) *routev1.TLSConfig {
+	tls := &routev1.TLSConfig{
+		Termination:                   termination,
+		InsecureEdgeTerminationPolicy: insecure,
+	}
+	if insecure == InsecurePolicyUnset {
+		tls.InsecureEdgeTerminationPolicy = ""
+	}
+	if termination == routev1.TLSTerminationReencrypt {
+		tls.DestinationCACertificate = destinationCA
+	}
+	return tls
+}
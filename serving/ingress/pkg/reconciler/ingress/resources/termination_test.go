@@ -0,0 +1,240 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+func TestMakeRoutesTermination(t *testing.T) {
+	policy := TerminationPolicy{
+		"example.com":        routev1.TLSTerminationReencrypt,
+		"secure.example.com": routev1.TLSTerminationPassthrough,
+	}
+
+	tests := []struct {
+		name         string
+		host         string
+		annotations  map[string]string
+		want         routev1.TLSTerminationType
+		wantInsecure routev1.InsecureEdgeTerminationPolicyType
+		wantPort     string
+		wantErr      error
+	}{{
+		name:         "no match",
+		host:         "foo.default.apps.other.com",
+		want:         routev1.TLSTerminationEdge,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyAllow,
+		wantPort:     KourierHTTPPort,
+	}, {
+		name:         "from policy",
+		host:         "foo.default.example.com",
+		want:         routev1.TLSTerminationReencrypt,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyAllow,
+		wantPort:     KourierHTTPSPort,
+	}, {
+		name:         "longest suffix wins",
+		host:         "foo.secure.example.com",
+		want:         routev1.TLSTerminationPassthrough,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyRedirect,
+		wantPort:     KourierHTTPSPort,
+	}, {
+		name:         "suffix matches whole labels only",
+		host:         "foo.default.notexample.com",
+		want:         routev1.TLSTerminationEdge,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyAllow,
+		wantPort:     KourierHTTPPort,
+	}, {
+		name:         "annotation overrides policy",
+		host:         "foo.secure.example.com",
+		annotations:  map[string]string{TerminationAnnotation: "edge"},
+		want:         routev1.TLSTerminationEdge,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyAllow,
+		wantPort:     KourierHTTPPort,
+	}, {
+		name:        "invalid annotation",
+		host:        "foo.default.example.com",
+		annotations: map[string]string{TerminationAnnotation: "offload"},
+		wantErr:     ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{test.host}))))
+			ing.Annotations = test.annotations
+
+			routes, err := MakeRoutes(ing, WithTerminationPolicy(policy))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+
+			tls := routes[0].Spec.TLS
+			if tls.Termination != test.want {
+				t.Errorf("Termination = %q, want: %q", tls.Termination, test.want)
+			}
+			if tls.InsecureEdgeTerminationPolicy != test.wantInsecure {
+				t.Errorf("InsecureEdgeTerminationPolicy = %q, want: %q", tls.InsecureEdgeTerminationPolicy, test.wantInsecure)
+			}
+			if got := routes[0].Spec.Port.TargetPort.StrVal; got != test.wantPort {
+				t.Errorf("TargetPort = %q, want: %q", got, test.wantPort)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesPassthroughWithoutCertManager(t *testing.T) {
+	ing := ingress(withRules(rule(withHosts([]string{"foo.vanity.com"}))))
+	ing.Annotations = map[string]string{
+		TerminationAnnotation:       "passthrough",
+		CertManagerIssuerAnnotation: "letsencrypt",
+	}
+
+	routes, err := MakeRoutes(ing, WithAppsDomain("apps.example.com"))
+	if err != nil {
+		t.Fatalf("MakeRoutes() = %v", err)
+	}
+	if got, ok := routes[0].Annotations[certManagerIssuerNameRouteAnnotation]; ok {
+		t.Errorf("Issuer = %q, want none, the gateway serves the certificate", got)
+	}
+}
+
+func TestMakeRoutesTLSDefaults(t *testing.T) {
+	const ca = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	defaults := TLSDefaults{
+		Termination:              routev1.TLSTerminationReencrypt,
+		InsecurePolicy:           routev1.InsecureEdgeTerminationPolicyAllow,
+		DestinationCACertificate: ca,
+	}
+	policy := TerminationPolicy{"secure.example.com": routev1.TLSTerminationPassthrough}
+
+	tests := []struct {
+		name         string
+		host         string
+		annotations  map[string]string
+		want         routev1.TLSTerminationType
+		wantInsecure routev1.InsecureEdgeTerminationPolicyType
+		wantCA       string
+		wantErr      error
+	}{{
+		name:         "defaults",
+		host:         "foo.default.example.com",
+		want:         routev1.TLSTerminationReencrypt,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyAllow,
+		wantCA:       ca,
+	}, {
+		name:         "policy picks passthrough",
+		host:         "foo.secure.example.com",
+		want:         routev1.TLSTerminationPassthrough,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyRedirect,
+	}, {
+		name:         "annotations override defaults",
+		host:         "foo.default.example.com",
+		annotations:  map[string]string{TerminationAnnotation: "edge", InsecurePolicyAnnotation: "none"},
+		want:         routev1.TLSTerminationEdge,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyNone,
+	}, {
+		name:        "annotations allowing plain HTTP on passthrough",
+		host:        "foo.default.example.com",
+		annotations: map[string]string{TerminationAnnotation: "passthrough", InsecurePolicyAnnotation: "Allow"},
+		wantErr:     ErrInvalidAnnotation,
+	}, {
+		name:        "invalid insecure policy annotation",
+		host:        "foo.default.example.com",
+		annotations: map[string]string{InsecurePolicyAnnotation: "Upgrade"},
+		wantErr:     ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{test.host}))))
+			ing.Annotations = test.annotations
+
+			routes, err := MakeRoutes(ing, WithTerminationPolicy(policy), WithTLSDefaults(defaults))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+
+			tls := routes[0].Spec.TLS
+			if tls.Termination != test.want {
+				t.Errorf("Termination = %q, want: %q", tls.Termination, test.want)
+			}
+			if tls.InsecureEdgeTerminationPolicy != test.wantInsecure {
+				t.Errorf("InsecureEdgeTerminationPolicy = %q, want: %q", tls.InsecureEdgeTerminationPolicy, test.wantInsecure)
+			}
+			if tls.DestinationCACertificate != test.wantCA {
+				t.Errorf("DestinationCACertificate = %q, want: %q", tls.DestinationCACertificate, test.wantCA)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesUnsetInsecurePolicy(t *testing.T) {
+	policy := TerminationPolicy{
+		"example.com":        routev1.TLSTerminationReencrypt,
+		"secure.example.com": routev1.TLSTerminationPassthrough,
+	}
+	defaults := TLSDefaults{InsecurePolicy: InsecurePolicyUnset}
+
+	tests := []struct {
+		name         string
+		host         string
+		annotations  map[string]string
+		want         routev1.TLSTerminationType
+		wantInsecure routev1.InsecureEdgeTerminationPolicyType
+		wantErr      error
+	}{{
+		name: "edge",
+		host: "foo.default.apps.other.com",
+		want: routev1.TLSTerminationEdge,
+	}, {
+		name: "reencrypt",
+		host: "foo.default.example.com",
+		want: routev1.TLSTerminationReencrypt,
+	}, {
+		name:         "policy picks passthrough",
+		host:         "foo.secure.example.com",
+		want:         routev1.TLSTerminationPassthrough,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyRedirect,
+	}, {
+		name:         "annotation overrides defaults",
+		host:         "foo.default.apps.other.com",
+		annotations:  map[string]string{InsecurePolicyAnnotation: "Redirect"},
+		want:         routev1.TLSTerminationEdge,
+		wantInsecure: routev1.InsecureEdgeTerminationPolicyRedirect,
+	}, {
+		name:        "annotation on passthrough",
+		host:        "foo.default.apps.other.com",
+		annotations: map[string]string{TerminationAnnotation: "passthrough", InsecurePolicyAnnotation: "unset"},
+		wantErr:     ErrInvalidAnnotation,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{test.host}))))
+			ing.Annotations = test.annotations
+
+			routes, err := MakeRoutes(ing, WithTerminationPolicy(policy), WithTLSDefaults(defaults))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("MakeRoutes() = %v, want: %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+
+			tls := routes[0].Spec.TLS
+			if tls.Termination != test.want {
+				t.Errorf("Termination = %q, want: %q", tls.Termination, test.want)
+			}
+			if tls.InsecureEdgeTerminationPolicy != test.wantInsecure {
+				t.Errorf("InsecureEdgeTerminationPolicy = %q, want: %q", tls.InsecureEdgeTerminationPolicy, test.wantInsecure)
+			}
+		})
+	}
+}
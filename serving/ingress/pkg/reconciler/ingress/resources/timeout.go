@@ -0,0 +1,113 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// haproxyUnits are the units supported for timeouts by HAProxy, largest first.
+var haproxyUnits = []struct {
+	suffix   string
+	duration time.Duration
+}{
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+}
+
+// ParseTimeout parses a user provided timeout. It accepts Go durations like "1h30m", bare
+// integers which are interpreted as seconds and HAProxy style values like "2d".
+func ParseTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("invalid timeout %q: must not be empty", value)
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return checkTimeout(value, time.Duration(seconds)*time.Second)
+	}
+	// Days are the only HAProxy unit Go durations don't support.
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		if n, err := strconv.ParseInt(days, 10, 64); err == nil {
+			return checkTimeout(value, time.Duration(n)*24*time.Hour)
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", value, err)
+	}
+	return checkTimeout(value, d)
+}
+
+func checkTimeout(value string, d time.Duration) (time.Duration, error) {
+	if d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must not be negative", value)
+	}
+	return d, nil
+}
+
+// FormatTimeout renders the given timeout in the largest HAProxy unit that represents it
+// exactly, like "90s" or "2m". Timeouts more precise than HAProxy supports are rounded up
+// to the next microsecond.
+func FormatTimeout(d time.Duration) string {
+	if rest := d % time.Microsecond; rest != 0 {
+		d += time.Microsecond - rest
+	}
+	if d == 0 {
+		return "0s"
+	}
+	for _, unit := range haproxyUnits {
+		if d%unit.duration == 0 {
+			return fmt.Sprintf("%d%s", d/unit.duration, unit.suffix)
+		}
+	}
+	// Unreachable, as every duration is a multiple of a microsecond at this point.
+	return fmt.Sprintf("%dus", d/time.Microsecond)
+}
+
+// PreserveTimeout copies the timeout of the existing Route to the desired one if both are the
+// same duration, so Routes aren't updated just because the timeout is formatted differently,
+// like "600s" by earlier versions instead of "10m". Timeouts HAProxy rejects are replaced.
+func PreserveTimeout(desired, existing *routev1.Route) {
+	want, ok := desired.Annotations[TimeoutAnnotation]
+	if !ok {
+		return
+	}
+	have, ok := existing.Annotations[TimeoutAnnotation]
+	if !ok || have == want || !isHAProxyTimeout(have) {
+		return
+	}
+	wantDuration, err := ParseTimeout(want)
+	if err != nil {
+		return
+	}
+	if haveDuration, err := ParseTimeout(have); err == nil && haveDuration == wantDuration {
+		desired.Annotations[TimeoutAnnotation] = have
+	}
+}
+
+// isHAProxyTimeout returns true if the given timeout is a number of a single HAProxy unit, or of
+// seconds if it has none.
+func isHAProxyTimeout(value string) bool {
+	number := strings.TrimRight(value, "abcdefghijklmnopqrstuvwxyz")
+	if _, err := strconv.ParseUint(number, 10, 64); err != nil {
+		return false
+	}
+	suffix := value[len(number):]
+	if suffix == "" {
+		return true
+	}
+	for _, unit := range haproxyUnits {
+		if suffix == unit.suffix {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{{
+		value: "90",
+		want:  90 * time.Second,
+	}, {
+		value: "0",
+		want:  0,
+	}, {
+		value: " 30 ",
+		want:  30 * time.Second,
+	}, {
+		value: "10m",
+		want:  10 * time.Minute,
+	}, {
+		value: "1h30m",
+		want:  90 * time.Minute,
+	}, {
+		value: "10m0s",
+		want:  10 * time.Minute,
+	}, {
+		value: "1.5s",
+		want:  1500 * time.Millisecond,
+	}, {
+		value: "250ms",
+		want:  250 * time.Millisecond,
+	}, {
+		value: "5us",
+		want:  5 * time.Microsecond,
+	}, {
+		value: "2d",
+		want:  48 * time.Hour,
+	}, {
+		value:   "",
+		wantErr: true,
+	}, {
+		value:   "-5",
+		wantErr: true,
+	}, {
+		value:   "-1m",
+		wantErr: true,
+	}, {
+		value:   "1.5d",
+		wantErr: true,
+	}, {
+		value:   "d",
+		wantErr: true,
+	}, {
+		value:   "ten minutes",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := ParseTimeout(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseTimeout() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("ParseTimeout() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    string
+	}{{
+		timeout: 0,
+		want:    "0s",
+	}, {
+		timeout: 48 * time.Hour,
+		want:    "2d",
+	}, {
+		timeout: 25 * time.Hour,
+		want:    "25h",
+	}, {
+		timeout: 2 * time.Hour,
+		want:    "2h",
+	}, {
+		timeout: 90 * time.Minute,
+		want:    "90m",
+	}, {
+		timeout: 10 * time.Minute,
+		want:    "10m",
+	}, {
+		timeout: 90 * time.Second,
+		want:    "90s",
+	}, {
+		timeout: time.Second,
+		want:    "1s",
+	}, {
+		timeout: 500 * time.Millisecond,
+		want:    "500ms",
+	}, {
+		timeout: 1500 * time.Millisecond,
+		want:    "1500ms",
+	}, {
+		timeout: 5 * time.Microsecond,
+		want:    "5us",
+	}, {
+		timeout: 1,
+		want:    "1us",
+	}, {
+		timeout: time.Millisecond + 1,
+		want:    "1001us",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := FormatTimeout(test.timeout); got != test.want {
+				t.Errorf("FormatTimeout(%v) = %q, want: %q", test.timeout, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutRoundTrip(t *testing.T) {
+	for _, value := range []string{"90s", "2m", "1h", "3d", "250ms", "7us"} {
+		d, err := ParseTimeout(value)
+		if err != nil {
+			t.Fatalf("ParseTimeout(%q) = %v", value, err)
+		}
+		if got := FormatTimeout(d); got != value {
+			t.Errorf("FormatTimeout(ParseTimeout(%q)) = %q", value, got)
+		}
+	}
+}
+
+func TestPreserveTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		desired  string
+		want     string
+	}{{
+		name:     "same duration in seconds",
+		existing: "600s",
+		desired:  "10m",
+		want:     "600s",
+	}, {
+		name:     "same duration without unit",
+		existing: "600",
+		desired:  "10m",
+		want:     "600",
+	}, {
+		name:     "different duration",
+		existing: "600s",
+		desired:  "5m",
+		want:     "5m",
+	}, {
+		name:     "rejected by HAProxy",
+		existing: "10m0s",
+		desired:  "10m",
+		want:     "10m",
+	}, {
+		name:     "unparsable",
+		existing: "ten minutes",
+		desired:  "10m",
+		want:     "10m",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desired := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{TimeoutAnnotation: test.desired}}}
+			existing := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{TimeoutAnnotation: test.existing}}}
+			PreserveTimeout(desired, existing)
+			if got := desired.Annotations[TimeoutAnnotation]; got != test.want {
+				t.Errorf("Timeout = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMakeRoutesPathTimeouts(t *testing.T) {
+	r := rule(withHosts([]string{externalDomain}))
+	r.HTTP.Paths = []networkingv1alpha1.HTTPIngressPath{{
+		Path:              "/a",
+		DeprecatedTimeout: &metav1.Duration{Duration: 10 * time.Second},
+	}, {
+		Path:              "/b",
+		DeprecatedTimeout: &metav1.Duration{Duration: 60 * time.Second},
+	}, {
+		Path: "/c",
+	}}
+
+	routes, err := MakeRoutes(ingress(withRules(r)))
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	// Every path gets a Route of its own, carrying the timeout of that path.
+	want := map[string]string{"/a": "10s", "/b": "1m", "/c": defaultTimeout}
+	if len(routes) != len(want) {
+		t.Fatalf("Got %d routes, want: %d", len(routes), len(want))
+	}
+	names := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		if got := route.Annotations[TimeoutAnnotation]; got != want[route.Spec.Path] {
+			t.Errorf("Timeout of path %q = %q, want: %q", route.Spec.Path, got, want[route.Spec.Path])
+		}
+		names[route.Name] = true
+	}
+	if len(names) != len(routes) {
+		t.Errorf("Routes of different paths share names: %v", names)
+	}
+}
+
+func TestMakeRoutesSharedPathTimeout(t *testing.T) {
+	r := rule(withHosts([]string{externalDomain}))
+	// Knative generates paths only differing in their header matches for tag header based routing.
+	r.HTTP.Paths = []networkingv1alpha1.HTTPIngressPath{{
+		Headers:           map[string]networkingv1alpha1.HeaderMatch{"Knative-Serving-Tag": {Exact: "latest"}},
+		DeprecatedTimeout: &metav1.Duration{Duration: 60 * time.Second},
+	}, {
+		DeprecatedTimeout: &metav1.Duration{Duration: 10 * time.Second},
+	}}
+
+	routes, err := MakeRoutes(ingress(withRules(r)))
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("Got %d routes, want: 1", len(routes))
+	}
+	// A single Route serves both paths, so it allows the longest timeout, regardless of the order.
+	if got, want := routes[0].Annotations[TimeoutAnnotation], "1m"; got != want {
+		t.Errorf("Timeout = %q, want: %q", got, want)
+	}
+	if routes[0].Spec.Path != "" {
+		t.Errorf("Path = %q, want the route to serve all paths", routes[0].Spec.Path)
+	}
+	if want := routeName(string(ingress().UID), externalHost); routes[0].Name != want {
+		t.Errorf("Name = %q, want the name of the host: %q", routes[0].Name, want)
+	}
+}
+
+func TestMakeRoutesOverrideTimeoutAnnotation(t *testing.T) {
+	r := rule(withHosts([]string{externalDomain}))
+	r.HTTP.Paths = []networkingv1alpha1.HTTPIngressPath{{
+		DeprecatedTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+	}}
+	ing := ingress(withRules(r))
+	// Go durations like this one are rejected by HAProxy.
+	ing.Annotations = map[string]string{TimeoutAnnotation: "10m0s"}
+
+	routes, err := MakeRoutes(ing)
+	if err != nil {
+		t.Fatal("MakeRoutes() =", err)
+	}
+	for _, route := range routes {
+		if got, want := route.Annotations[TimeoutAnnotation], "10m"; got != want {
+			t.Errorf("Timeout = %q, want: %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/types"
+	networkingv1alpha1 "knative.dev/networking/pkg/apis/networking/v1alpha1"
+)
+
+// MissingCertificateError indicates that strict TLS is enabled, but a host of the Ingress is
+// not covered by any certificate.
+type MissingCertificateError struct {
+	Host string
+}
+
+func (e *MissingCertificateError) Error() string {
+	return fmt.Sprintf("strict TLS is enabled but no certificate covers host %q", e.Host)
+}
+
+// PendingCertificateError indicates that a certificate of the Ingress covers a host, but its
+// secret doesn't exist yet, for example because cert-manager is still issuing it. Unlike a
+// MissingCertificateError, it resolves on its own, so generating the Routes should be retried.
+type PendingCertificateError struct {
+	Host   string
+	Secret types.NamespacedName
+}
+
+func (e *PendingCertificateError) Error() string {
+	return fmt.Sprintf("secret %s of the certificate of host %q doesn't exist yet", e.Secret, e.Host)
+}
+
+// hasCertificate returns true if a certificate other than the router's default one is served for
+// the given host. That's the case if cert-manager injects a certificate into the Route, or if the
+// gateway terminates TLS with a certificate of the Ingress. Certificates of the Ingress are never
+// copied onto the Route, so the router can't serve them.
+func hasCertificate(ci *networkingv1alpha1.Ingress, host string, termination routev1.TLSTerminationType, annotations map[string]string) bool {
+	if termination == routev1.TLSTerminationPassthrough {
+		return ingressCertificate(ci, host) != nil
+	}
+	return certManagerAnnotations(annotations) != nil
+}
+
+// pendingCertificate returns a PendingCertificateError if the gateway is expected to serve a
+// certificate of the Ingress for the given host, but its secret doesn't exist yet. Hosts without
+// a certificate of the Ingress don't wait for one.
+func pendingCertificate(ci *networkingv1alpha1.Ingress, host string, termination routev1.TLSTerminationType, o *options) error {
+	if termination != routev1.TLSTerminationPassthrough || o.secretExistsFunc == nil {
+		return nil
+	}
+	tls := ingressCertificate(ci, host)
+	if tls == nil {
+		return nil
+	}
+	secret := types.NamespacedName{Namespace: tls.SecretNamespace, Name: tls.SecretName}
+	exists, err := o.secretExistsFunc(secret.Namespace, secret.Name)
+	if err != nil {
+		return fmt.Errorf("failed to look up secret %s of the certificate of host %q: %w", secret, host, err)
+	}
+	if !exists {
+		return &PendingCertificateError{Host: host, Secret: secret}
+	}
+	return nil
+}
+
+// ingressCertificate returns the first TLS entry of the Ingress covering the given host, or nil
+// if there is none. Wildcard hosts cover exactly one additional label.
+func ingressCertificate(ci *networkingv1alpha1.Ingress, host string) *networkingv1alpha1.IngressTLS {
+	for i := range ci.Spec.TLS {
+		tls := &ci.Spec.TLS[i]
+		if tls.SecretName == "" {
+			continue
+		}
+		for _, tlsHost := range tls.Hosts {
+			tlsHost = NormalizeHost(tlsHost)
+			if tlsHost == host {
+				return tls
+			}
+			if strings.HasPrefix(tlsHost, "*.") {
+				if i := strings.Index(host, "."); i > 0 && host[i:] == tlsHost[1:] {
+					return tls
+				}
+			}
+		}
+	}
+	return nil
+}
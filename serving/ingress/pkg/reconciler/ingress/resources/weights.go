@@ -0,0 +1,212 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"knative.dev/pkg/ptr"
+)
+
+// RoundingPolicy defines how percentages that don't add up to 100 are turned into Route weights.
+// That's the case if splits to cluster-local Services are dropped from the backends of a Route,
+// see AllowClusterLocalSplitsAnnotation. The WeightRoundingAnnotation selects it per Ingress.
+type RoundingPolicy string
+
+const (
+	// RoundingLargestRemainder hands out the points lost to rounding to the backends with the
+	// largest fractional parts first.
+	RoundingLargestRemainder RoundingPolicy = "largest-remainder"
+
+	// RoundingTruncate truncates all weights and assigns the points lost to rounding to the
+	// primary backend.
+	RoundingTruncate RoundingPolicy = "truncate"
+)
+
+// KeepDrainedBackendsAnnotation keeps backends that receive no traffic on the Routes of an
+// Ingress, with a weight of 0, instead of dropping them. The router then keeps serving the
+// connections that are still open to them while sending no new requests.
+const KeepDrainedBackendsAnnotation = "serving.knative.openshift.io/keepDrainedBackends"
+
+// OmitSingleBackendWeightAnnotation leaves the weight of Routes with a single backend unset
+// instead of setting it to 100, for routers that warn about explicit weights. Routes with
+// alternate backends always carry weights.
+const OmitSingleBackendWeightAnnotation = "serving.knative.openshift.io/omitSingleBackendWeight"
+
+// WeightRequiredReason is the reason of the warning that the weight of a Route can't be omitted.
+const WeightRequiredReason = "WeightRequired"
+
+// PrimaryWeightAnnotation sets the weight of the backend of Routes with a single backend, for
+// canaries ramping traffic at the router level. It overrides OmitSingleBackendWeightAnnotation.
+// The weights of Routes with alternate backends are derived from the traffic split instead.
+const PrimaryWeightAnnotation = "serving.knative.openshift.io/primaryWeight"
+
+// PrimaryWeightIgnoredReason is the reason of the warning that the primary weight of a Route
+// can't be set.
+const PrimaryWeightIgnoredReason = "PrimaryWeightIgnored"
+
+// maxRouteWeight is the highest weight the router accepts for a backend.
+const maxRouteWeight = 256
+
+// backend is a weighted target of a Route.
+type backend struct {
+	name    string
+	percent int
+}
+
+// roundingPolicy returns the weight rounding policy requested by the given annotations.
+func roundingPolicy(annotations map[string]string) (RoundingPolicy, error) {
+	value, ok := annotations[WeightRoundingAnnotation]
+	if !ok {
+		return RoundingLargestRemainder, nil
+	}
+
+	switch policy := RoundingPolicy(value); policy {
+	case RoundingLargestRemainder, RoundingTruncate:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w %s: value %q must be one of %q or %q",
+			ErrInvalidAnnotation, WeightRoundingAnnotation, value, RoundingLargestRemainder, RoundingTruncate)
+	}
+}
+
+// keepDrainedBackends returns true if the given annotations request keeping backends that
+// receive no traffic.
+func keepDrainedBackends(annotations map[string]string) (bool, error) {
+	value, ok := annotations[KeepDrainedBackendsAnnotation]
+	if !ok {
+		return false, nil
+	}
+	keep, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w %s: value %q must be a boolean", ErrInvalidAnnotation, KeepDrainedBackendsAnnotation, value)
+	}
+	return keep, nil
+}
+
+// omitSingleBackendWeight returns true if the given annotations request omitting the weight of
+// Routes with a single backend.
+func omitSingleBackendWeight(annotations map[string]string) (bool, error) {
+	value, ok := annotations[OmitSingleBackendWeightAnnotation]
+	if !ok {
+		return false, nil
+	}
+	omit, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w %s: value %q must be a boolean", ErrInvalidAnnotation, OmitSingleBackendWeightAnnotation, value)
+	}
+	return omit, nil
+}
+
+// primaryWeight returns the weight of the primary backend the given annotations request, or nil
+// if they don't request one.
+func primaryWeight(annotations map[string]string) (*int32, error) {
+	value, ok := annotations[PrimaryWeightAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	weight, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || weight < 0 || weight > maxRouteWeight {
+		return nil, fmt.Errorf("%w %s: value %q must be an integer between 0 and %d",
+			ErrInvalidAnnotation, PrimaryWeightAnnotation, value, maxRouteWeight)
+	}
+	return ptr.Int32(int32(weight)), nil
+}
+
+// PreserveDefaultWeight copies the weight the API server defaulted on the existing Route to the
+// desired one, if the desired Route omits it, so that the Route isn't updated over and over again.
+func PreserveDefaultWeight(desired, existing *routev1.Route) {
+	if desired.Spec.To.Weight == nil && len(desired.Spec.AlternateBackends) == 0 {
+		desired.Spec.To.Weight = existing.Spec.To.Weight
+	}
+}
+
+// withoutDrained returns the given backends without the ones receiving 0 percent of the traffic.
+// If no backend receives any traffic, the primary one is kept.
+func withoutDrained(backends []backend) []backend {
+	var out []backend
+	for _, b := range backends {
+		if b.percent > 0 {
+			out = append(out, b)
+		}
+	}
+	if len(out) == 0 && len(backends) > 0 {
+		return backends[:1]
+	}
+	return out
+}
+
+// routeTargets turns the given backends into the primary and alternate targets of a Route.
+// The first backend is the primary one. Backends receiving 0 percent of the traffic are
+// dropped, unless keepDrained is set.
+func routeTargets(backends []backend, policy RoundingPolicy, keepDrained bool) (routev1.RouteTargetReference, []routev1.RouteTargetReference) {
+	if !keepDrained {
+		backends = withoutDrained(backends)
+	}
+	percents := make([]int, len(backends))
+	for i, b := range backends {
+		percents[i] = b.percent
+	}
+	weights := normalizeWeights(percents, policy)
+
+	targets := make([]routev1.RouteTargetReference, len(backends))
+	for i, b := range backends {
+		targets[i] = routev1.RouteTargetReference{
+			Kind:   "Service",
+			Name:   b.name,
+			Weight: ptr.Int32(weights[i]),
+		}
+	}
+
+	if len(targets) == 1 {
+		return targets[0], nil
+	}
+	return targets[0], targets[1:]
+}
+
+// normalizeWeights scales the given percentages so that they add up to exactly 100, using
+// the given policy to distribute the points lost to rounding.
+func normalizeWeights(percents []int, policy RoundingPolicy) []int32 {
+	weights := make([]int32, len(percents))
+	if len(percents) == 0 {
+		return weights
+	}
+
+	total := 0
+	for _, p := range percents {
+		total += p
+	}
+	if total == 0 {
+		// Nothing to scale, send everything to the primary backend.
+		weights[0] = 100
+		return weights
+	}
+
+	remainders := make([]int, len(percents))
+	assigned := int32(0)
+	for i, p := range percents {
+		weights[i] = int32(p * 100 / total)
+		remainders[i] = p * 100 % total
+		assigned += weights[i]
+	}
+	missing := 100 - assigned
+
+	switch policy {
+	case RoundingTruncate:
+		weights[0] += missing
+	default:
+		order := make([]int, len(percents))
+		for i := range order {
+			order[i] = i
+		}
+		// Stable to prefer earlier backends if their remainders are equal.
+		sort.SliceStable(order, func(i, j int) bool {
+			return remainders[order[i]] > remainders[order[j]]
+		})
+		for i := int32(0); i < missing; i++ {
+			weights[order[i]]++
+		}
+	}
+	return weights
+}
@@ -0,0 +1,339 @@
+package resources
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/ptr"
+)
+
+func TestNormalizeWeights(t *testing.T) {
+	tests := []struct {
+		name     string
+		percents []int
+		policy   RoundingPolicy
+		want     []int32
+	}{{
+		name:     "single backend",
+		percents: []int{100},
+		policy:   RoundingLargestRemainder,
+		want:     []int32{100},
+	}, {
+		name:     "already adds up",
+		percents: []int{20, 80},
+		policy:   RoundingTruncate,
+		want:     []int32{20, 80},
+	}, {
+		name:     "scaled up",
+		percents: []int{1, 3},
+		policy:   RoundingLargestRemainder,
+		want:     []int32{25, 75},
+	}, {
+		name:     "all zero",
+		percents: []int{0, 0},
+		policy:   RoundingLargestRemainder,
+		want:     []int32{100, 0},
+	}, {
+		name:     "largest remainder",
+		percents: []int{1, 1, 4},
+		policy:   RoundingLargestRemainder,
+		want:     []int32{17, 17, 66},
+	}, {
+		name:     "truncate",
+		percents: []int{1, 1, 4},
+		policy:   RoundingTruncate,
+		want:     []int32{18, 16, 66},
+	}, {
+		name:     "largest remainder prefers larger fractions",
+		percents: []int{1, 2, 4},
+		policy:   RoundingLargestRemainder,
+		want:     []int32{14, 29, 57},
+	}, {
+		name:     "truncate same input",
+		percents: []int{1, 2, 4},
+		policy:   RoundingTruncate,
+		want:     []int32{15, 28, 57},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := normalizeWeights(test.percents, test.policy)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("normalizeWeights() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRoundingPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        RoundingPolicy
+		wantErr     bool
+	}{{
+		name: "default",
+		want: RoundingLargestRemainder,
+	}, {
+		name:        "largest remainder",
+		annotations: map[string]string{WeightRoundingAnnotation: "largest-remainder"},
+		want:        RoundingLargestRemainder,
+	}, {
+		name:        "truncate",
+		annotations: map[string]string{WeightRoundingAnnotation: "truncate"},
+		want:        RoundingTruncate,
+	}, {
+		name:        "invalid",
+		annotations: map[string]string{WeightRoundingAnnotation: "round-half-up"},
+		wantErr:     true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := roundingPolicy(test.annotations)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("roundingPolicy() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("roundingPolicy() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRouteTargets(t *testing.T) {
+	to, alternates := routeTargets([]backend{{name: "a", percent: 1}, {name: "b", percent: 2}}, RoundingLargestRemainder, false)
+
+	wantTo := routev1.RouteTargetReference{Kind: "Service", Name: "a", Weight: ptr.Int32(33)}
+	wantAlternates := []routev1.RouteTargetReference{{Kind: "Service", Name: "b", Weight: ptr.Int32(67)}}
+	if !cmp.Equal(to, wantTo) {
+		t.Errorf("to = %v, want: %v", to, wantTo)
+	}
+	if !cmp.Equal(alternates, wantAlternates) {
+		t.Errorf("alternateBackends = %v, want: %v", alternates, wantAlternates)
+	}
+}
+
+func TestRouteTargetsDrained(t *testing.T) {
+	tests := []struct {
+		name          string
+		backends      []backend
+		keepDrained   bool
+		wantTo        routev1.RouteTargetReference
+		wantAlternate []routev1.RouteTargetReference
+	}{{
+		name:     "drained alternate dropped",
+		backends: []backend{{name: "a", percent: 100}, {name: "b", percent: 0}},
+		wantTo:   routev1.RouteTargetReference{Kind: "Service", Name: "a", Weight: ptr.Int32(100)},
+	}, {
+		name:        "drained alternate kept",
+		backends:    []backend{{name: "a", percent: 100}, {name: "b", percent: 0}},
+		keepDrained: true,
+		wantTo:      routev1.RouteTargetReference{Kind: "Service", Name: "a", Weight: ptr.Int32(100)},
+		wantAlternate: []routev1.RouteTargetReference{
+			{Kind: "Service", Name: "b", Weight: ptr.Int32(0)},
+		},
+	}, {
+		name:     "drained primary dropped",
+		backends: []backend{{name: "a", percent: 0}, {name: "b", percent: 100}},
+		wantTo:   routev1.RouteTargetReference{Kind: "Service", Name: "b", Weight: ptr.Int32(100)},
+	}, {
+		name:        "drained primary kept",
+		backends:    []backend{{name: "a", percent: 0}, {name: "b", percent: 100}},
+		keepDrained: true,
+		wantTo:      routev1.RouteTargetReference{Kind: "Service", Name: "a", Weight: ptr.Int32(0)},
+		wantAlternate: []routev1.RouteTargetReference{
+			{Kind: "Service", Name: "b", Weight: ptr.Int32(100)},
+		},
+	}, {
+		name:     "all drained",
+		backends: []backend{{name: "a", percent: 0}, {name: "b", percent: 0}},
+		wantTo:   routev1.RouteTargetReference{Kind: "Service", Name: "a", Weight: ptr.Int32(100)},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			to, alternates := routeTargets(test.backends, RoundingLargestRemainder, test.keepDrained)
+			if !cmp.Equal(to, test.wantTo) {
+				t.Errorf("To (-want, +got) = %s", cmp.Diff(test.wantTo, to))
+			}
+			if !cmp.Equal(alternates, test.wantAlternate) {
+				t.Errorf("AlternateBackends (-want, +got) = %s", cmp.Diff(test.wantAlternate, alternates))
+			}
+		})
+	}
+}
+
+func TestOmitSingleBackendWeight(t *testing.T) {
+	migrating := WithGatewayMigration(GatewayMigration{
+		From:   types.NamespacedName{Namespace: lbNamespace, Name: lbService},
+		To:     types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"},
+		Weight: 30,
+	})
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		opts        []Option
+		wantWeight  *int32
+		wantWarning bool
+		wantErr     bool
+	}{{
+		name:       "explicit by default",
+		wantWeight: ptr.Int32(100),
+	}, {
+		name:        "explicit if disabled",
+		annotations: map[string]string{OmitSingleBackendWeightAnnotation: "false"},
+		wantWeight:  ptr.Int32(100),
+	}, {
+		name:        "omitted",
+		annotations: map[string]string{OmitSingleBackendWeightAnnotation: "true"},
+	}, {
+		name:        "kept with alternate backends",
+		annotations: map[string]string{OmitSingleBackendWeightAnnotation: "true"},
+		opts:        []Option{migrating},
+		wantWeight:  ptr.Int32(70),
+		wantWarning: true,
+	}, {
+		name:        "invalid",
+		annotations: map[string]string{OmitSingleBackendWeightAnnotation: "yes"},
+		wantErr:     true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = test.annotations
+			var warnings []string
+			opts := append(test.opts, WithWarningFunc(func(reason, _ string) {
+				warnings = append(warnings, reason)
+			}))
+
+			routes, err := MakeRoutes(ing, opts...)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidAnnotation) {
+					t.Fatalf("MakeRoutes() = %v, want: %v", err, ErrInvalidAnnotation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("MakeRoutes() =", err)
+			}
+			if got := routes[0].Spec.To.Weight; !cmp.Equal(got, test.wantWeight) {
+				t.Errorf("Weight = %v, want: %v", got, test.wantWeight)
+			}
+			if got := len(warnings) > 0; got != test.wantWarning {
+				t.Errorf("Warnings = %v, want a warning: %v", warnings, test.wantWarning)
+			}
+		})
+	}
+}
+
+func TestPrimaryWeight(t *testing.T) {
+	migrating := WithGatewayMigration(GatewayMigration{
+		From:   types.NamespacedName{Namespace: lbNamespace, Name: lbService},
+		To:     types.NamespacedName{Namespace: lbNamespace, Name: "istio-ingressgateway"},
+		Weight: 30,
+	})
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		opts        []Option
+		wantWeight  *int32
+		wantWarning bool
+		wantErr     bool
+	}{{
+		name:        "ramp start",
+		annotations: map[string]string{PrimaryWeightAnnotation: "10"},
+		wantWeight:  ptr.Int32(10),
+	}, {
+		name:        "ramp quarter",
+		annotations: map[string]string{PrimaryWeightAnnotation: "25"},
+		wantWeight:  ptr.Int32(25),
+	}, {
+		name:        "ramp half",
+		annotations: map[string]string{PrimaryWeightAnnotation: "50"},
+		wantWeight:  ptr.Int32(50),
+	}, {
+		name:        "drained",
+		annotations: map[string]string{PrimaryWeightAnnotation: "0"},
+		wantWeight:  ptr.Int32(0),
+	}, {
+		name:        "maximum",
+		annotations: map[string]string{PrimaryWeightAnnotation: "256"},
+		wantWeight:  ptr.Int32(256),
+	}, {
+		name: "overrides omitting the weight",
+		annotations: map[string]string{
+			PrimaryWeightAnnotation:           "25",
+			OmitSingleBackendWeightAnnotation: "true",
+		},
+		wantWeight: ptr.Int32(25),
+	}, {
+		name:        "ignored with alternate backends",
+		annotations: map[string]string{PrimaryWeightAnnotation: "25"},
+		opts:        []Option{migrating},
+		wantWeight:  ptr.Int32(70),
+		wantWarning: true,
+	}, {
+		name:        "above maximum",
+		annotations: map[string]string{PrimaryWeightAnnotation: "257"},
+		wantErr:     true,
+	}, {
+		name:        "negative",
+		annotations: map[string]string{PrimaryWeightAnnotation: "-1"},
+		wantErr:     true,
+	}, {
+		name:        "not an integer",
+		annotations: map[string]string{PrimaryWeightAnnotation: "12.5"},
+		wantErr:     true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := ingress(withRules(rule(withHosts([]string{externalDomain}))))
+			ing.Annotations = test.annotations
+			var warnings []string
+			opts := append(test.opts, WithWarningFunc(func(reason, _ string) {
+				warnings = append(warnings, reason)
+			}))
+
+			routes, err := MakeRoutes(ing, opts...)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidAnnotation) {
+					t.Fatalf("MakeRoutes() = %v, want: %v", err, ErrInvalidAnnotation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("MakeRoutes() =", err)
+			}
+			if got := routes[0].Spec.To.Weight; !cmp.Equal(got, test.wantWeight) {
+				t.Errorf("Weight = %v, want: %v", got, test.wantWeight)
+			}
+			if got := len(warnings) > 0; got != test.wantWarning {
+				t.Errorf("Warnings = %v, want a warning: %v", warnings, test.wantWarning)
+			}
+		})
+	}
+}
+
+func TestPreserveDefaultWeight(t *testing.T) {
+	existing := &routev1.Route{Spec: routev1.RouteSpec{To: routev1.RouteTargetReference{Weight: ptr.Int32(100)}}}
+
+	omitted := &routev1.Route{}
+	PreserveDefaultWeight(omitted, existing)
+	if got := omitted.Spec.To.Weight; !cmp.Equal(got, ptr.Int32(100)) {
+		t.Errorf("Weight = %v, want the defaulted weight 100", got)
+	}
+
+	explicit := &routev1.Route{Spec: routev1.RouteSpec{To: routev1.RouteTargetReference{Weight: ptr.Int32(70)}}}
+	PreserveDefaultWeight(explicit, existing)
+	if got := explicit.Spec.To.Weight; !cmp.Equal(got, ptr.Int32(70)) {
+		t.Errorf("Weight = %v, want: 70", got)
+	}
+}
@@ -0,0 +1,70 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/logging"
+	logtesting "knative.dev/pkg/logging/testing"
+
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/config"
+	. "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/testing"
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func TestReconcileRouteConflictRetry(t *testing.T) {
+	fake := NewFakeRouteClient(WithAdmittedRoutes(1), WithConflictOnUpdate())
+	clientset := fake.Clientset()
+	listers := NewListers(fake.Objects())
+	r := &Reconciler{
+		routeClient: clientset.RouteV1(),
+		routeLister: listers.GetRouteLister(),
+	}
+
+	ctx := logging.WithLogger(context.Background(), logtesting.TestLogger(t))
+	ctx = config.ToContext(ctx, &config.Config{Ingress: &config.Ingress{}})
+	desired := fake.Routes()[0].DeepCopy()
+	desired.Spec.Path = "/changed"
+
+	// The first update hits the conflict and has to be retried by requeueing the Ingress.
+	err := r.reconcileRoute(ctx, ing(ingNamespace, ingName), desired)
+	var statusErr *apierrs.StatusError
+	if !errors.As(err, &statusErr) || !apierrs.IsConflict(statusErr) {
+		t.Fatalf("reconcileRoute() = %v, want a conflict", err)
+	}
+
+	if err := r.reconcileRoute(ctx, ing(ingNamespace, ingName), desired); err != nil {
+		t.Fatalf("Retried reconcileRoute() = %v", err)
+	}
+	got, err := clientset.RouteV1().Routes(RouteNamespace).Get(ctx, desired.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get route: %v", err)
+	}
+	if got.Spec.Path != desired.Spec.Path {
+		t.Errorf("Path = %q, want: %q", got.Spec.Path, desired.Spec.Path)
+	}
+}
+
+func TestReconcileQuotaExceeded(t *testing.T) {
+	key := ingNamespace + "/" + ingName
+
+	table := TableTest{{
+		Name:                    "route creation exceeds quota",
+		SkipNamespaceValidation: true,
+		Key:                     key,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
+		WithReactors:            NewFakeRouteClient(WithQuotaExceeded()).Reactors(),
+		WantCreates:             []runtime.Object{route(ingressNamespace, routeName)},
+		WantErr:                 true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError", "failed to create route :routes.route.openshift.io %q is forbidden: exceeded quota: routes, "+
+				"requested: count/routes.route.openshift.io=1, used: count/routes.route.openshift.io=10, limited: count/routes.route.openshift.io=10", routeName),
+		},
+	}}
+	table.Test(t, newFactory(nil))
+}
@@ -0,0 +1,170 @@
+package ingress
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	networkinglisters "knative.dev/networking/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	"knative.dev/serving/pkg/apis/serving"
+
+	routev1lister "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/listers/route/v1"
+	"github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/ingress/resources"
+)
+
+// routeStatusInterval is the interval the state of the generated Routes is recorded in. Only the
+// informer caches are read, so recording is cheap.
+const routeStatusInterval = 30 * time.Second
+
+// topRouteNamespaces is the number of namespaces the Routes are recorded for individually. The
+// Routes of all other namespaces are recorded as otherNamespaces, to bound the cardinality.
+const (
+	topRouteNamespaces = 10
+	otherNamespaces    = "other"
+)
+
+// The admission states of Routes, as recorded by the state tag.
+const (
+	routeStateAdmitted = "admitted"
+	routeStateFailed   = "failed"
+	routeStatePending  = "pending"
+)
+
+// routeStatusRecorder records how many of the generated Routes routers admitted, how many
+// Routes outlived their Ingress, how the Routes are distributed across namespaces and how long
+// admissions took. It also records the Ingresses whose gateway is unknown.
+type routeStatusRecorder struct {
+	routeLister   routev1lister.RouteLister
+	ingressLister networkinglisters.IngressLister
+	clock         clock.Clock
+
+	// lastRecorded is the time of the previous recording. Admissions before it have already
+	// been recorded.
+	lastRecorded time.Time
+
+	// namespaces are the namespace tags of the previous recording. Namespaces that dropped out
+	// are recorded with zero Routes, as the last value of a gauge is reported forever.
+	namespaces sets.String
+}
+
+func newRouteStatusRecorder(routeLister routev1lister.RouteLister, ingressLister networkinglisters.IngressLister, clock clock.Clock) *routeStatusRecorder {
+	return &routeStatusRecorder{
+		routeLister:   routeLister,
+		ingressLister: ingressLister,
+		clock:         clock,
+		// Routes admitted before the controller started were recorded by its predecessor, if at all.
+		lastRecorded: clock.Now(),
+		namespaces:   sets.NewString(),
+	}
+}
+
+// record records the state of all generated Routes.
+func (r *routeStatusRecorder) record(ctx context.Context) {
+	routes, err := listGeneratedRoutes(r.routeLister)
+	if err != nil {
+		logging.FromContext(ctx).Errorw("Failed to record route status", zap.Error(err))
+		return
+	}
+
+	now := r.clock.Now()
+	states := map[string]int64{routeStateAdmitted: 0, routeStateFailed: 0, routeStatePending: 0}
+	orphans := int64(0)
+	namespaces := make(map[string]int64)
+	for _, route := range routes {
+		namespaces[route.Labels[serving.RouteNamespaceLabelKey]]++
+
+		if admittedAt, ok := resources.RouteAdmittedSince(route); ok {
+			states[routeStateAdmitted]++
+			if admittedAt.After(r.lastRecorded) && !admittedAt.After(now) {
+				latency := admittedAt.Sub(route.CreationTimestamp.Time)
+				metrics.Record(ctx, routeAdmissionLatencyM.M(latency.Seconds()))
+			}
+		} else if _, failed := resources.RouteFailedSince(route); failed {
+			states[routeStateFailed]++
+		} else {
+			states[routeStatePending]++
+		}
+
+		if isOrphan(r.ingressLister, route) {
+			orphans++
+		}
+	}
+	r.lastRecorded = now
+
+	for state, count := range states {
+		if ctx, err := tag.New(ctx, tag.Insert(stateKey, state)); err == nil {
+			metrics.Record(ctx, routesM.M(count))
+		}
+	}
+	metrics.Record(ctx, orphanRoutesM.M(orphans))
+
+	recorded := sets.NewString()
+	for namespace, count := range topNamespaces(namespaces, topRouteNamespaces) {
+		recorded.Insert(namespace)
+		if ctx, err := tag.New(ctx, tag.Insert(namespaceKey, namespace)); err == nil {
+			metrics.Record(ctx, namespaceRoutesM.M(count))
+		}
+	}
+	for _, namespace := range r.namespaces.Difference(recorded).UnsortedList() {
+		if ctx, err := tag.New(ctx, tag.Insert(namespaceKey, namespace)); err == nil {
+			metrics.Record(ctx, namespaceRoutesM.M(0))
+		}
+	}
+	r.namespaces = recorded
+
+	ingresses, err := r.ingressLister.List(labels.Everything())
+	if err != nil {
+		logging.FromContext(ctx).Errorw("Failed to record unavailable gateways", zap.Error(err))
+		return
+	}
+	recordGatewayUnavailable(ctx, ingresses, now)
+}
+
+// topNamespaces returns the counts of the n namespaces with the highest counts. The counts of
+// all other namespaces are summed up as otherNamespaces. Ties are broken by name, so that the
+// same namespaces are returned for the same counts.
+func topNamespaces(counts map[string]int64, n int) map[string]int64 {
+	namespaces := make([]string, 0, len(counts))
+	for namespace := range counts {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		if counts[namespaces[i]] != counts[namespaces[j]] {
+			return counts[namespaces[i]] > counts[namespaces[j]]
+		}
+		return namespaces[i] < namespaces[j]
+	})
+
+	top := make(map[string]int64, n+1)
+	for i, namespace := range namespaces {
+		if i < n {
+			top[namespace] = counts[namespace]
+		} else {
+			top[otherNamespaces] += counts[namespace]
+		}
+	}
+	return top
+}
+
+// runRouteStatus records the state of all generated Routes every interval until the context is
+// done.
+func runRouteStatus(ctx context.Context, interval time.Duration, recorder *routeStatusRecorder) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			recorder.record(ctx)
+		}
+	}
+}
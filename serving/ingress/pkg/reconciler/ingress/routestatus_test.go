@@ -0,0 +1,212 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	"go.opencensus.io/stats/view"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"knative.dev/networking/pkg/apis/networking"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/metrics"
+	"knative.dev/serving/pkg/apis/serving"
+
+	. "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/reconciler/testing"
+)
+
+func TestRecordRouteStatus(t *testing.T) {
+	metrics.InitForTesting()
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+
+	created := func(at time.Time) routeOption {
+		return func(r *routev1.Route) {
+			r.CreationTimestamp = metav1.Time{Time: at}
+		}
+	}
+	admittedAt := func(at time.Time) routeOption {
+		return func(r *routev1.Route) {
+			withAdmitted(canonicalHostname)(r)
+			r.Status.Ingress[0].Conditions[0].LastTransitionTime = &metav1.Time{Time: at}
+		}
+	}
+	orphaned := func(r *routev1.Route) {
+		r.Labels[networking.IngressLabelKey] = "gone"
+	}
+
+	listers := NewListers([]runtime.Object{
+		// The Ingress all Routes but the orphaned one belong to.
+		ing("testNs", "test"),
+		route(ingressNamespace, "admitted-before-start", created(start.Add(-time.Hour)), admittedAt(start.Add(-time.Minute))),
+		route(ingressNamespace, "admitted", created(start.Add(-20*time.Second)), admittedAt(start.Add(10*time.Second))),
+		route(ingressNamespace, "failed", withRejected(start)),
+		route(ingressNamespace, "pending"),
+		route(ingressNamespace, "orphaned", orphaned, withRejected(start)),
+	})
+	recorder := newRouteStatusRecorder(listers.GetRouteLister(), listers.GetIngressLister(), fakeClock)
+	fakeClock.Step(routeStatusInterval)
+	recorder.record(context.Background())
+
+	rows, err := view.RetrieveData(routesM.Name())
+	if err != nil {
+		t.Fatalf("RetrieveData(%s) = %v", routesM.Name(), err)
+	}
+	got := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		got[row.Tags[0].Value] = row.Data.(*view.LastValueData).Value
+	}
+	want := map[string]float64{routeStateAdmitted: 2, routeStateFailed: 2, routeStatePending: 1}
+	for state, count := range want {
+		if got[state] != count {
+			t.Errorf("routes{state=%q} = %v, want: %v", state, got[state], count)
+		}
+	}
+
+	rows, err = view.RetrieveData(orphanRoutesM.Name())
+	if err != nil {
+		t.Fatalf("RetrieveData(%s) = %v", orphanRoutesM.Name(), err)
+	}
+	if len(rows) != 1 || rows[0].Data.(*view.LastValueData).Value != 1 {
+		t.Errorf("orphan_routes = %v, want: 1", rows)
+	}
+
+	rows, err = view.RetrieveData(routeAdmissionLatencyM.Name())
+	if err != nil {
+		t.Fatalf("RetrieveData(%s) = %v", routeAdmissionLatencyM.Name(), err)
+	}
+	// Only the admission since the controller started is recorded.
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows of route_admission_latencies, want: 1", len(rows))
+	}
+	data := rows[0].Data.(*view.DistributionData)
+	if data.Count != 1 || data.Mean != 30 {
+		t.Errorf("route_admission_latencies count = %d, mean = %v, want: 1, 30", data.Count, data.Mean)
+	}
+
+	// Admissions are only recorded once.
+	fakeClock.Step(routeStatusInterval)
+	recorder.record(context.Background())
+	rows, err = view.RetrieveData(routeAdmissionLatencyM.Name())
+	if err != nil {
+		t.Fatalf("RetrieveData(%s) = %v", routeAdmissionLatencyM.Name(), err)
+	}
+	if got := rows[0].Data.(*view.DistributionData).Count; got != 1 {
+		t.Errorf("route_admission_latencies count = %d after the second recording, want: 1", got)
+	}
+}
+
+func TestRecordNamespaceRoutes(t *testing.T) {
+	metrics.InitForTesting()
+	fakeClock := clock.NewFakeClock(time.Now())
+	inNamespace := func(namespace string) routeOption {
+		return func(r *routev1.Route) {
+			r.Labels[serving.RouteNamespaceLabelKey] = namespace
+		}
+	}
+
+	listers := NewListers([]runtime.Object{
+		route(ingressNamespace, "a", inNamespace("a")),
+		route(ingressNamespace, "b", inNamespace("b")),
+	})
+	recorder := newRouteStatusRecorder(listers.GetRouteLister(), listers.GetIngressLister(), fakeClock)
+	recorder.record(context.Background())
+
+	listers = NewListers([]runtime.Object{
+		route(ingressNamespace, "a", inNamespace("a")),
+	})
+	recorder.routeLister = listers.GetRouteLister()
+	recorder.record(context.Background())
+
+	rows, err := view.RetrieveData(namespaceRoutesM.Name())
+	if err != nil {
+		t.Fatalf("RetrieveData(%s) = %v", namespaceRoutesM.Name(), err)
+	}
+	got := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		got[row.Tags[0].Value] = row.Data.(*view.LastValueData).Value
+	}
+	// The namespace without Routes is reset rather than reported with its last count.
+	want := map[string]float64{"a": 1, "b": 0}
+	for namespace, count := range want {
+		if value, ok := got[namespace]; !ok || value != count {
+			t.Errorf("namespace_routes{namespace=%q} = %v, want: %v", namespace, value, count)
+		}
+	}
+}
+
+func TestTopNamespaces(t *testing.T) {
+	counts := map[string]int64{"a": 5, "b": 3, "c": 3, "d": 1, "e": 1}
+
+	tests := []struct {
+		n    int
+		want map[string]int64
+	}{{
+		n:    5,
+		want: counts,
+	}, {
+		n:    2,
+		want: map[string]int64{"a": 5, "b": 3, otherNamespaces: 5},
+	}, {
+		n:    0,
+		want: map[string]int64{otherNamespaces: 13},
+	}}
+
+	for _, test := range tests {
+		if got := topNamespaces(counts, test.n); !cmp.Equal(got, test.want) {
+			t.Errorf("topNamespaces(%d) (-want, +got) = %s", test.n, cmp.Diff(test.want, got))
+		}
+	}
+}
+
+func TestRecordGatewayUnavailable(t *testing.T) {
+	metrics.InitForTesting()
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	failingSince := func(since time.Time) ingressOption {
+		return func(i *v1alpha1.Ingress) {
+			withGateway(corev1.ConditionFalse)(i)
+			// GetCondition returns a copy.
+			for j := range i.Status.Conditions {
+				if i.Status.Conditions[j].Type == IngressConditionGateway {
+					i.Status.Conditions[j].LastTransitionTime = apis.VolatileTime{Inner: metav1.Time{Time: since}}
+				}
+			}
+		}
+	}
+
+	recordGatewayUnavailable(context.Background(), []*v1alpha1.Ingress{
+		ing("a", "failing-long", failingSince(now.Add(-time.Hour))),
+		ing("b", "failing", failingSince(now.Add(-time.Minute))),
+		ing("c", "recovered", withGateway(corev1.ConditionTrue)),
+		ing("d", "healthy"),
+	}, now)
+	assertGatewayUnavailable(t, 2, time.Hour.Seconds())
+
+	// The gauges go back to zero once the gateway is available again.
+	recordGatewayUnavailable(context.Background(), []*v1alpha1.Ingress{
+		ing("a", "failing-long", withGateway(corev1.ConditionTrue)),
+	}, now)
+	assertGatewayUnavailable(t, 0, 0)
+}
+
+func assertGatewayUnavailable(t *testing.T, wantCount, wantAge float64) {
+	t.Helper()
+	for name, want := range map[string]float64{
+		gatewayUnavailableIngressesM.Name(): wantCount,
+		gatewayUnavailableAgeM.Name():       wantAge,
+	} {
+		rows, err := view.RetrieveData(name)
+		if err != nil {
+			t.Fatalf("RetrieveData(%s) = %v", name, err)
+		}
+		if len(rows) != 1 || rows[0].Data.(*view.LastValueData).Value != want {
+			t.Errorf("%s = %v, want: %v", name, rows, want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package ingress
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// serviceLabelsEnvKey configures the comma-separated labels of Knative Services that are copied
+// onto the Routes of their Ingresses.
+const serviceLabelsEnvKey = "KSERVICE_LABELS"
+
+var kserviceResource = schema.GroupVersionResource{
+	Group:    serving.GroupName,
+	Version:  "v1",
+	Resource: "services",
+}
+
+// serviceLabelsFromEnv reads the labels of Knative Services to copy onto Routes. None are copied
+// by default.
+func serviceLabelsFromEnv() []string {
+	var keys []string
+	for _, key := range strings.Split(os.Getenv(serviceLabelsEnvKey), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// serviceLabelCopier copies an allowlist of labels of Knative Services onto the Routes of their
+// Ingresses. Knative doesn't propagate arbitrary labels of a Service to its Ingress.
+type serviceLabelCopier struct {
+	keys []string
+	// indexer caches the Knative Services. No lister is generated for them, as no other part of
+	// the controller reads them.
+	indexer cache.Indexer
+}
+
+// newKServiceInformer returns an informer of all Knative Services. It's only started if labels of
+// Services are copied, to not cache them otherwise.
+func newKServiceInformer(ctx context.Context, client dynamic.Interface, resync time.Duration) cache.SharedIndexInformer {
+	resource := client.Resource(kserviceResource)
+	return cache.NewSharedIndexInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return resource.List(ctx, opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return resource.Watch(ctx, opts)
+		},
+	}, &unstructured.Unstructured{}, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+}
+
+// labels returns the allowlisted labels of the Knative Service of the given Ingress. Ingresses
+// not created for a Knative Service, or whose Service isn't known, get none.
+func (c *serviceLabelCopier) labels(ing *v1alpha1.Ingress) map[string]string {
+	name := ing.Labels[serving.ServiceLabelKey]
+	if name == "" {
+		return nil
+	}
+	// Lookups in the informer's store never fail.
+	obj, exists, _ := c.indexer.GetByKey(ing.Namespace + "/" + name)
+	svc, ok := obj.(metav1.Object)
+	if !exists || !ok {
+		return nil
+	}
+
+	copied := make(map[string]string, len(c.keys))
+	for _, key := range c.keys {
+		if value, ok := svc.GetLabels()[key]; ok {
+			copied[key] = value
+		}
+	}
+	return copied
+}
+
+// ingressFilter returns a filter passing the Ingresses of the given Knative Service.
+func (c *serviceLabelCopier) ingressFilter(obj interface{}) func(interface{}) bool {
+	svc, ok := obj.(metav1.Object)
+	if !ok {
+		return func(interface{}) bool { return false }
+	}
+	selector := labels.SelectorFromSet(labels.Set{serving.ServiceLabelKey: svc.GetName()})
+	return func(obj interface{}) bool {
+		ing, ok := obj.(metav1.Object)
+		return ok && ing.GetNamespace() == svc.GetNamespace() && selector.Matches(labels.Set(ing.GetLabels()))
+	}
+}
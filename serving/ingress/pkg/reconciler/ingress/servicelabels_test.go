@@ -0,0 +1,156 @@
+package ingress
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+func kservice(namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	svc := &unstructured.Unstructured{}
+	svc.SetAPIVersion("serving.knative.dev/v1")
+	svc.SetKind("Service")
+	svc.SetNamespace(namespace)
+	svc.SetName(name)
+	svc.SetLabels(labels)
+	return svc
+}
+
+func newServiceLabelCopier(t *testing.T, keys []string, services ...*unstructured.Unstructured) *serviceLabelCopier {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, svc := range services {
+		if err := indexer.Add(svc); err != nil {
+			t.Fatal("Failed to add service:", err)
+		}
+	}
+	return &serviceLabelCopier{keys: keys, indexer: indexer}
+}
+
+func withServiceLabel(name string) func(*v1alpha1.Ingress) {
+	return func(i *v1alpha1.Ingress) {
+		i.Labels[serving.ServiceLabelKey] = name
+	}
+}
+
+func TestServiceLabelsFromEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{{
+		name: "unset",
+	}, {
+		name:  "list",
+		value: "costcenter, team,,",
+		want:  []string{"costcenter", "team"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.value != "" {
+				os.Setenv(serviceLabelsEnvKey, test.value)
+				defer os.Unsetenv(serviceLabelsEnvKey)
+			}
+
+			if got := serviceLabelsFromEnv(); !cmp.Equal(got, test.want) {
+				t.Errorf("serviceLabelsFromEnv() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestServiceLabelCopier(t *testing.T) {
+	copier := newServiceLabelCopier(t, []string{"costcenter", "team"},
+		kservice(ingNamespace, "svc", map[string]string{"costcenter": "cc1", "other": "ignored"}))
+
+	tests := []struct {
+		name    string
+		ingress *v1alpha1.Ingress
+		want    map[string]string
+	}{{
+		name:    "allowlisted labels",
+		ingress: ing(ingNamespace, ingName, withServiceLabel("svc")),
+		want:    map[string]string{"costcenter": "cc1"},
+	}, {
+		name:    "no service label",
+		ingress: ing(ingNamespace, ingName),
+	}, {
+		name:    "unknown service",
+		ingress: ing(ingNamespace, ingName, withServiceLabel("unknown")),
+	}, {
+		name:    "service in another namespace",
+		ingress: ing("other", ingName, withServiceLabel("svc")),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := copier.labels(test.ingress); !cmp.Equal(got, test.want) {
+				t.Errorf("labels() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestServiceLabelIngressFilter(t *testing.T) {
+	copier := newServiceLabelCopier(t, []string{"team"})
+	filter := copier.ingressFilter(kservice(ingNamespace, "svc", nil))
+
+	if !filter(ing(ingNamespace, ingName, withServiceLabel("svc"))) {
+		t.Error("Filter rejected the ingress of the service")
+	}
+	if filter(ing(ingNamespace, ingName, withServiceLabel("other"))) {
+		t.Error("Filter passed the ingress of another service")
+	}
+	if filter(ing("other", ingName, withServiceLabel("svc"))) {
+		t.Error("Filter passed the ingress of a service in another namespace")
+	}
+	if copier.ingressFilter(cache.DeletedFinalStateUnknown{})(ing(ingNamespace, ingName, withServiceLabel("svc"))) {
+		t.Error("Filter of a tombstone passed an ingress")
+	}
+}
+
+func TestReconcileServiceLabels(t *testing.T) {
+	withLabels := func(labels map[string]string) routeOption {
+		return func(r *routev1.Route) {
+			for key, value := range labels {
+				r.Labels[key] = value
+			}
+		}
+	}
+	serviceLabels := map[string]string{serving.ServiceLabelKey: "svc", "team": "a"}
+
+	table := TableTest{{
+		Name:                    "copy labels of the service",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName, withServiceLabel("svc"))},
+		WantCreates:             []runtime.Object{route(ingressNamespace, routeName, withLabels(serviceLabels))},
+		WantEvents:              []string{routeCreated(routeName, routeHost)},
+	}, {
+		Name:                    "remove labels the service dropped",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects: []runtime.Object{
+			ing(ingNamespace, ingName, withServiceLabel("other")),
+			route(ingressNamespace, routeName, withLabels(map[string]string{serving.ServiceLabelKey: "other", "team": "b"})),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route(ingressNamespace, routeName, withLabels(map[string]string{serving.ServiceLabelKey: "other"})),
+		}},
+	}}
+	table.Test(t, newFactory(func(r *Reconciler) {
+		r.serviceLabels = newServiceLabelCopier(t, []string{"team"},
+			kservice(ingNamespace, "svc", map[string]string{"team": "a"}),
+			kservice(ingNamespace, "other", nil))
+	}))
+}
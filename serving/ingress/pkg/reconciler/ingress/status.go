@@ -0,0 +1,134 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/logging"
+)
+
+// ownedConditions are the conditions of Ingresses this controller reports. All other conditions,
+// including the readiness, are owned by Kourier.
+var ownedConditions = []apis.ConditionType{
+	IngressConditionGateway,
+	IngressConditionHostOwnership,
+}
+
+// ownedStatusAnnotations are the annotations of the status of Ingresses this controller reports.
+var ownedStatusAnnotations = []string{
+	RouteAdmittedAtAnnotation,
+	CNAMETargetsAnnotation,
+	RouterHostnamesAnnotation,
+	InMaintenanceAnnotation,
+	LoadBalancerAddressAnnotation,
+}
+
+// jsonPatchOperation is an operation of a JSON patch, see RFC 6902.
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// patchStatus writes the changes of the owned conditions and status annotations of the given
+// Ingress since the given status. The status is owned by Kourier, which updates it as a whole, so
+// it's patched rather than updated. Conditions are replaced by index, guarded by a test of their
+// type, so the patch fails and the reconciliation is retried if Kourier reordered them meanwhile.
+func (r *Reconciler) patchStatus(ctx context.Context, ing *v1alpha1.Ingress, before *v1alpha1.IngressStatus) error {
+	ops := statusPatch(before, &ing.Status, r.clock.Now())
+	if len(ops) == 0 {
+		return nil
+	}
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("failed to create ingress status patch: %w", err)
+	}
+
+	logging.FromContext(ctx).Info("Patching status of ingress")
+	ctx, span := startSpan(ctx, "PatchIngressStatus")
+	_, err = r.ingressClient.Ingresses(ing.Namespace).Patch(ctx, ing.Name, types.JSONPatchType, patch, metav1.PatchOptions{}, "status")
+	endSpan(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to patch ingress status: %w", err)
+	}
+	return nil
+}
+
+// statusPatch returns the operations patching the owned conditions and status annotations from
+// the before status to the after status. Changed conditions transitioned at the given time.
+func statusPatch(before, after *v1alpha1.IngressStatus, now time.Time) []jsonPatchOperation {
+	var ops []jsonPatchOperation
+
+	// Kourier initializes the conditions of the Ingresses it serves, so they're only missing
+	// before Kourier got to the Ingress.
+	hasConditions := len(before.Conditions) > 0
+	for _, t := range ownedConditions {
+		cond := after.GetCondition(t)
+		if cond == nil {
+			continue
+		}
+		prev := before.GetCondition(t)
+		if prev != nil && equality.Semantic.DeepEqual(*prev, *cond) {
+			continue
+		}
+		changed := *cond
+		changed.LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(now)}
+
+		switch i := conditionIndex(before.Conditions, t); {
+		case i >= 0:
+			path := fmt.Sprintf("/status/conditions/%d", i)
+			ops = append(ops,
+				jsonPatchOperation{Op: "test", Path: path + "/type", Value: t},
+				jsonPatchOperation{Op: "replace", Path: path, Value: changed})
+		case hasConditions:
+			ops = append(ops, jsonPatchOperation{Op: "add", Path: "/status/conditions/-", Value: changed})
+		default:
+			ops = append(ops, jsonPatchOperation{Op: "add", Path: "/status/conditions", Value: []apis.Condition{changed}})
+			hasConditions = true
+		}
+	}
+
+	hasAnnotations := len(before.Annotations) > 0
+	for _, key := range ownedStatusAnnotations {
+		value, ok := after.Annotations[key]
+		prev, hadPrev := before.Annotations[key]
+		if ok == hadPrev && value == prev {
+			continue
+		}
+		path := "/status/annotations/" + escapeJSONPointer(key)
+		switch {
+		case !ok:
+			ops = append(ops, jsonPatchOperation{Op: "remove", Path: path})
+		case hasAnnotations:
+			ops = append(ops, jsonPatchOperation{Op: "add", Path: path, Value: value})
+		default:
+			ops = append(ops, jsonPatchOperation{Op: "add", Path: "/status/annotations", Value: map[string]string{key: value}})
+			hasAnnotations = true
+		}
+	}
+	return ops
+}
+
+// conditionIndex returns the index of the condition of the given type, or -1 if there is none.
+func conditionIndex(conditions duckv1.Conditions, t apis.ConditionType) int {
+	for i, cond := range conditions {
+		if cond.Type == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// escapeJSONPointer escapes the given key to be used as a token of a JSON pointer, see RFC 6901.
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
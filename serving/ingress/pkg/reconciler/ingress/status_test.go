@@ -0,0 +1,113 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+	fakenetworkingclientset "knative.dev/networking/pkg/client/clientset/versioned/fake"
+	"knative.dev/pkg/apis"
+)
+
+// statusPatchAction returns the action patching the status of the test Ingress with the given
+// JSON patch.
+func statusPatchAction(patch string) clientgotesting.PatchActionImpl {
+	return clientgotesting.PatchActionImpl{
+		Name:       ingName,
+		ActionImpl: clientgotesting.ActionImpl{Namespace: ingNamespace},
+		Patch:      []byte(patch),
+	}
+}
+
+// applyStatusPatch patches the status of the given Ingress with the given JSON patch the way the
+// API server does.
+func applyStatusPatch(ing *v1alpha1.Ingress, patch []byte) (*v1alpha1.Ingress, error) {
+	client := fakenetworkingclientset.NewSimpleClientset(ing).NetworkingV1alpha1()
+	return client.Ingresses(ing.Namespace).Patch(context.Background(), ing.Name, types.JSONPatchType, patch, metav1.PatchOptions{}, "status")
+}
+
+// kourierReady makes Kourier report the Ingress as ready.
+func kourierReady(i *v1alpha1.Ingress) {
+	i.Status.InitializeConditions()
+	i.Status.MarkNetworkConfigured()
+	i.Status.MarkLoadBalancerReady(nil, nil)
+}
+
+func TestStatusPatch(t *testing.T) {
+	before := ing(ingNamespace, ingName, kourierReady, withGateway(corev1.ConditionTrue), func(i *v1alpha1.Ingress) {
+		i.Status.Annotations = map[string]string{"kourier": "annotation", InMaintenanceAnnotation: "true"}
+	})
+	after := before.DeepCopy()
+	withGateway(corev1.ConditionFalse)(after)
+	withHostOwnership(corev1.ConditionFalse, "conflict")(after)
+	delete(after.Status.Annotations, InMaintenanceAnnotation)
+	after.Status.Annotations[CNAMETargetsAnnotation] = "cname"
+
+	now := time.Date(2020, 12, 2, 0, 0, 0, 0, time.UTC)
+	ops := statusPatch(&before.Status, &after.Status, now)
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatal("Failed to marshal the patch:", err)
+	}
+
+	// Meanwhile, Kourier found the Ingress not ready anymore and rewrote its status.
+	current := before.DeepCopy()
+	current.Status.MarkIngressNotReady("Changed", "changed by Kourier")
+	got, err := applyStatusPatch(current, patch)
+	if err != nil {
+		t.Fatalf("Failed to apply patch %s: %v", patch, err)
+	}
+
+	// The changed conditions are replaced in place or appended, everything else is kept.
+	// Compare to the marshaled ingress, as transition times lose their precision.
+	doc, err := json.Marshal(current)
+	if err != nil {
+		t.Fatal("Failed to marshal the ingress:", err)
+	}
+	want := &v1alpha1.Ingress{}
+	if err := json.Unmarshal(doc, want); err != nil {
+		t.Fatal("Failed to unmarshal the ingress:", err)
+	}
+	want.Status.Annotations = map[string]string{"kourier": "annotation", CNAMETargetsAnnotation: "cname"}
+	for _, typ := range ownedConditions {
+		cond := *after.Status.GetCondition(typ)
+		cond.LastTransitionTime = apis.VolatileTime{Inner: metav1.NewTime(now)}
+		if i := conditionIndex(want.Status.Conditions, typ); i >= 0 {
+			want.Status.Conditions[i] = cond
+		} else {
+			want.Status.Conditions = append(want.Status.Conditions, cond)
+		}
+	}
+	if !cmp.Equal(got.Status, want.Status) {
+		t.Error("Patched status (-want, +got) =", cmp.Diff(want.Status, got.Status))
+	}
+
+	if ops := statusPatch(&after.Status, &after.Status, now); len(ops) != 0 {
+		t.Errorf("Patch of an unchanged status = %v, want none", ops)
+	}
+}
+
+func TestStatusPatchReorderedConditions(t *testing.T) {
+	before := ing(ingNamespace, ingName, kourierReady, withGateway(corev1.ConditionTrue))
+	after := before.DeepCopy()
+	withGateway(corev1.ConditionFalse)(after)
+	patch, err := json.Marshal(statusPatch(&before.Status, &after.Status, time.Now()))
+	if err != nil {
+		t.Fatal("Failed to marshal the patch:", err)
+	}
+
+	// Kourier rewrote the conditions meanwhile, so the gateway condition isn't at its index anymore.
+	current := before.DeepCopy()
+	current.Status.Conditions = current.Status.Conditions[1:]
+
+	if _, err := applyStatusPatch(current, patch); err == nil {
+		t.Errorf("Patch %s applied to reordered conditions, want it to fail", patch)
+	}
+}
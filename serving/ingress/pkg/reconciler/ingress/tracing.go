@@ -0,0 +1,124 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"contrib.go.opencensus.io/exporter/ocagent"
+	"go.opencensus.io/trace"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"knative.dev/networking/pkg/apis/networking/v1alpha1"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+const (
+	// tracingEndpointEnvKey configures the address of the OpenCensus agent traces are exported
+	// to, for example an OpenTelemetry collector with the opencensus receiver enabled. Traces are
+	// exported in the OpenCensus agent protocol, not OTLP, as the vendored dependencies only
+	// include OpenCensus. Tracing is disabled if it is not set.
+	tracingEndpointEnvKey = "TRACING_OPENCENSUS_ENDPOINT"
+
+	// tracingSampleRateEnvKey configures the fraction of reconciliations that are traced.
+	tracingSampleRateEnvKey = "TRACING_SAMPLE_RATE"
+
+	// defaultTracingSampleRate traces every reconciliation, so that slow ones aren't missed.
+	defaultTracingSampleRate = 1.0
+
+	tracingServiceName = "openshift-ingress-controller"
+)
+
+// Span attributes. Only the names of objects are recorded, never their spec, which contains
+// the certificates and keys of Routes.
+const (
+	ingressNamespaceAttribute = "ingress.namespace"
+	ingressNameAttribute      = "ingress.name"
+	routeNamespaceAttribute   = "route.namespace"
+	routeNameAttribute        = "route.name"
+	routeCountAttribute       = "routes"
+)
+
+// tracingConfig configures the export of traces of reconciliations.
+type tracingConfig struct {
+	// endpoint is the address of the agent traces are exported to. Empty disables tracing.
+	endpoint   string
+	sampleRate float64
+}
+
+// tracingConfigFromEnv returns the tracing configuration of the environment.
+func tracingConfigFromEnv() (tracingConfig, error) {
+	cfg := tracingConfig{
+		endpoint:   os.Getenv(tracingEndpointEnvKey),
+		sampleRate: defaultTracingSampleRate,
+	}
+	if raw := os.Getenv(tracingSampleRateEnvKey); raw != "" {
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return tracingConfig{}, fmt.Errorf("failed to parse %s: %w", tracingSampleRateEnvKey, err)
+		}
+		if rate < 0 || rate > 1 {
+			return tracingConfig{}, fmt.Errorf("%s must be between 0 and 1, was %v", tracingSampleRateEnvKey, rate)
+		}
+		cfg.sampleRate = rate
+	}
+	return cfg, nil
+}
+
+// setupTracing exports traces to the configured endpoint until the context is done. If tracing
+// is disabled, the process-wide sampler is left untouched and spans are dropped for lack of an
+// exporter.
+func setupTracing(ctx context.Context, cfg tracingConfig) error {
+	if cfg.endpoint == "" {
+		return nil
+	}
+
+	exporter, err := ocagent.NewExporter(
+		ocagent.WithInsecure(),
+		ocagent.WithAddress(cfg.endpoint),
+		ocagent.WithServiceName(tracingServiceName))
+	if err != nil {
+		return fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	trace.RegisterExporter(exporter)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(cfg.sampleRate)})
+
+	go func() {
+		<-ctx.Done()
+		trace.UnregisterExporter(exporter)
+		// Stopping flushes the spans that haven't been exported yet.
+		exporter.Stop()
+	}()
+	return nil
+}
+
+// startSpan starts a span with the given attributes as a child of the span of the context.
+func startSpan(ctx context.Context, name string, attrs ...trace.Attribute) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, name)
+	span.AddAttributes(attrs...)
+	return ctx, span
+}
+
+// endSpan ends the given span with the outcome of the traced operation. Only the reason of
+// API errors is recorded, their messages may echo fields of the object.
+func endSpan(span *trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: string(apierrs.ReasonForError(err))})
+	}
+	span.End()
+}
+
+func ingressAttributes(ing *v1alpha1.Ingress) []trace.Attribute {
+	return []trace.Attribute{
+		trace.StringAttribute(ingressNamespaceAttribute, ing.Namespace),
+		trace.StringAttribute(ingressNameAttribute, ing.Name),
+	}
+}
+
+func routeAttributes(route *routev1.Route) []trace.Attribute {
+	return []trace.Attribute{
+		trace.StringAttribute(routeNamespaceAttribute, route.Namespace),
+		trace.StringAttribute(routeNameAttribute, route.Name),
+	}
+}
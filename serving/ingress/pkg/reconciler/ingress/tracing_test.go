@@ -0,0 +1,172 @@
+package ingress
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"go.opencensus.io/trace"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	. "knative.dev/pkg/reconciler/testing"
+)
+
+// defaultSampler is the sampler OpenCensus uses if none is configured.
+var defaultSampler = trace.ProbabilitySampler(1e-4)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+func TestTracingConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		endpoint   string
+		sampleRate string
+		want       tracingConfig
+		wantErr    bool
+	}{{
+		name: "unset",
+		want: tracingConfig{sampleRate: defaultTracingSampleRate},
+	}, {
+		name:     "endpoint",
+		endpoint: "otel-collector.observability:55678",
+		want:     tracingConfig{endpoint: "otel-collector.observability:55678", sampleRate: defaultTracingSampleRate},
+	}, {
+		name:       "sample rate",
+		endpoint:   "otel-collector.observability:55678",
+		sampleRate: "0.25",
+		want:       tracingConfig{endpoint: "otel-collector.observability:55678", sampleRate: 0.25},
+	}, {
+		name:       "garbage sample rate",
+		sampleRate: "often",
+		wantErr:    true,
+	}, {
+		name:       "negative sample rate",
+		sampleRate: "-0.1",
+		wantErr:    true,
+	}, {
+		name:       "sample rate above 1",
+		sampleRate: "1.5",
+		wantErr:    true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.endpoint != "" {
+				os.Setenv(tracingEndpointEnvKey, test.endpoint)
+				defer os.Unsetenv(tracingEndpointEnvKey)
+			}
+			if test.sampleRate != "" {
+				os.Setenv(tracingSampleRateEnvKey, test.sampleRate)
+				defer os.Unsetenv(tracingSampleRateEnvKey)
+			}
+
+			got, err := tracingConfigFromEnv()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("tracingConfigFromEnv() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("tracingConfigFromEnv() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetupTracingDisabled(t *testing.T) {
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: defaultSampler})
+
+	if err := setupTracing(context.Background(), tracingConfig{}); err != nil {
+		t.Fatalf("setupTracing() = %v", err)
+	}
+	// The sampler of other libraries in the process must not be changed.
+	if _, span := trace.StartSpan(context.Background(), "test"); !span.IsRecordingEvents() {
+		t.Error("setupTracing() changed the sampler although tracing is disabled")
+	}
+}
+
+func TestReconcileSpans(t *testing.T) {
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	defer trace.UnregisterExporter(recorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: defaultSampler})
+
+	table := TableTest{{
+		Name:                    "create route",
+		SkipNamespaceValidation: true,
+		Key:                     ingNamespace + "/" + ingName,
+		Objects:                 []runtime.Object{ing(ingNamespace, ingName)},
+		WantCreates:             []runtime.Object{route(ingressNamespace, routeName)},
+		WantEvents:              []string{routeCreated(routeName, routeHost)},
+	}}
+	table.Test(t, newFactory(nil))
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	var root *trace.SpanData
+	for _, span := range recorder.spans {
+		if span.Name == "ReconcileKind" {
+			root = span
+		}
+	}
+	if root == nil {
+		t.Fatal("No ReconcileKind span was recorded")
+	}
+	if got := root.Attributes[ingressNamespaceAttribute]; got != ingNamespace {
+		t.Errorf("%s = %v, want: %s", ingressNamespaceAttribute, got, ingNamespace)
+	}
+	if got := root.Attributes[ingressNameAttribute]; got != ingName {
+		t.Errorf("%s = %v, want: %s", ingressNameAttribute, got, ingName)
+	}
+
+	allowed := map[string]bool{
+		ingressNamespaceAttribute: true,
+		ingressNameAttribute:      true,
+		routeNamespaceAttribute:   true,
+		routeNameAttribute:        true,
+		routeCountAttribute:       true,
+	}
+	got := make(map[string]*trace.SpanData)
+	for _, span := range recorder.spans {
+		if span.TraceID != root.TraceID || span == root {
+			continue
+		}
+		got[span.Name] = span
+		// Only names are recorded, which keeps certificates out of traces.
+		for key := range span.Attributes {
+			if !allowed[key] {
+				t.Errorf("Span %s has unexpected attribute %q", span.Name, key)
+			}
+		}
+	}
+
+	for _, name := range []string{"GenerateRoutes", "ReconcileGatewayIndirection", "ReconcileRoute"} {
+		span, ok := got[name]
+		if !ok {
+			t.Errorf("No %s span was recorded", name)
+		} else if span.ParentSpanID != root.SpanID {
+			t.Errorf("%s is not a child of ReconcileKind", name)
+		}
+	}
+	create, ok := got["CreateRoute"]
+	if !ok {
+		t.Fatal("No CreateRoute span was recorded")
+	}
+	if create.ParentSpanID != got["ReconcileRoute"].SpanID {
+		t.Error("CreateRoute is not a child of ReconcileRoute")
+	}
+	if name := create.Attributes[routeNameAttribute]; name != routeName {
+		t.Errorf("%s = %v, want: %s", routeNameAttribute, name, routeName)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	fakerouteclientset "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/clientset/versioned/fake"
 	routev1listers "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/listers/route/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	networking "knative.dev/networking/pkg/apis/networking/v1alpha1"
 	fakenetworkingclientset "knative.dev/networking/pkg/client/clientset/versioned/fake"
@@ -15,6 +18,7 @@ import (
 var clientSetSchemes = []func(*runtime.Scheme) error{
 	fakenetworkingclientset.AddToScheme,
 	fakerouteclientset.AddToScheme,
+	kubescheme.AddToScheme,
 }
 
 type Listers struct {
@@ -68,3 +72,8 @@ func (l *Listers) GetIngressLister() networkinglisters.IngressLister {
 func (l *Listers) GetRouteLister() routev1listers.RouteLister {
 	return routev1listers.NewRouteLister(l.IndexerFor(&routev1.Route{}))
 }
+
+// GetServiceLister get lister for Service resource.
+func (l *Listers) GetServiceLister() corev1listers.ServiceLister {
+	return corev1listers.NewServiceLister(l.IndexerFor(&corev1.Service{}))
+}
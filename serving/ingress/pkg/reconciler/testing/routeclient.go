@@ -0,0 +1,154 @@
+package testing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	fakerouteclientset "github.com/openshift-knative/serverless-operator/serving/ingress/pkg/client/clientset/versioned/fake"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// RouteNamespace is the namespace of the Routes generated by the scenarios of FakeRouteClient.
+const RouteNamespace = "knative-serving-ingress"
+
+// FakeRouteClient collects the Routes and reactors of common scenarios the Route client runs into.
+// The objects and reactors can be passed to a TableRow, or backed by a fake clientset directly.
+type FakeRouteClient struct {
+	routes   []*routev1.Route
+	reactors []ktesting.ReactionFunc
+}
+
+// FakeRouteClientOption adds a scenario to a FakeRouteClient.
+type FakeRouteClientOption func(*FakeRouteClient)
+
+// NewFakeRouteClient returns a FakeRouteClient with the given scenarios.
+func NewFakeRouteClient(opts ...FakeRouteClientOption) *FakeRouteClient {
+	c := &FakeRouteClient{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithAdmittedRoutes adds n Routes named route-<i> that have been admitted by a router.
+func WithAdmittedRoutes(n int) FakeRouteClientOption {
+	return func(c *FakeRouteClient) {
+		for i := 0; i < n; i++ {
+			route := c.route(fmt.Sprintf("route-%d", i))
+			setAdmitted(route, corev1.ConditionTrue, "", "")
+		}
+	}
+}
+
+// WithFailedRoute makes the router reject the Route with the given name for the given reason,
+// for example HostAlreadyClaimed. The rejection happened long enough ago to have passed any
+// grace period. The Route is added if it doesn't exist yet.
+func WithFailedRoute(name, reason string) FakeRouteClientOption {
+	return func(c *FakeRouteClient) {
+		setAdmitted(c.route(name), corev1.ConditionFalse, reason, "route rejected by the router: "+reason)
+	}
+}
+
+// WithConflictOnUpdate makes the first update of a Route fail with a conflict, as it happens
+// when the informer cache is stale. Subsequent updates pass.
+func WithConflictOnUpdate() FakeRouteClientOption {
+	return func(c *FakeRouteClient) {
+		conflicted := false
+		c.reactors = append(c.reactors, func(action ktesting.Action) (bool, runtime.Object, error) {
+			if conflicted || !action.Matches("update", "routes") {
+				return false, nil, nil
+			}
+			conflicted = true
+			name := action.(ktesting.UpdateAction).GetObject().(*routev1.Route).Name
+			return true, nil, apierrs.NewConflict(routev1.Resource("routes"), name,
+				errors.New("the object has been modified; please apply your changes to the latest version and try again"))
+		})
+	}
+}
+
+// WithQuotaExceeded makes all creations of Routes fail, because the ResourceQuota of the
+// namespace doesn't allow for any more Routes.
+func WithQuotaExceeded() FakeRouteClientOption {
+	return func(c *FakeRouteClient) {
+		c.reactors = append(c.reactors, func(action ktesting.Action) (bool, runtime.Object, error) {
+			if !action.Matches("create", "routes") {
+				return false, nil, nil
+			}
+			name := action.(ktesting.CreateAction).GetObject().(*routev1.Route).Name
+			return true, nil, apierrs.NewForbidden(routev1.Resource("routes"), name,
+				errors.New("exceeded quota: routes, requested: count/routes.route.openshift.io=1, used: count/routes.route.openshift.io=10, limited: count/routes.route.openshift.io=10"))
+		})
+	}
+}
+
+// Routes returns the Routes of the scenarios.
+func (c *FakeRouteClient) Routes() []*routev1.Route {
+	return c.routes
+}
+
+// Objects returns the Routes of the scenarios, to be used as objects of a TableRow.
+func (c *FakeRouteClient) Objects() []runtime.Object {
+	objs := make([]runtime.Object, 0, len(c.routes))
+	for _, route := range c.routes {
+		objs = append(objs, route)
+	}
+	return objs
+}
+
+// Reactors returns the reactors of the scenarios, to be used as reactors of a TableRow.
+func (c *FakeRouteClient) Reactors() []ktesting.ReactionFunc {
+	return c.reactors
+}
+
+// Clientset returns a fake clientset serving the Routes and reactors of the scenarios.
+func (c *FakeRouteClient) Clientset() *fakerouteclientset.Clientset {
+	cs := fakerouteclientset.NewSimpleClientset(c.Objects()...)
+	for _, reactor := range c.reactors {
+		cs.PrependReactor("*", "*", reactor)
+	}
+	return cs
+}
+
+// route returns the Route with the given name, adding it if necessary.
+func (c *FakeRouteClient) route(name string) *routev1.Route {
+	for _, route := range c.routes {
+		if route.Name == name {
+			return route
+		}
+	}
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: RouteNamespace,
+		},
+		Spec: routev1.RouteSpec{
+			Host: name + ".apps.example.com",
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: "kourier",
+			},
+		},
+	}
+	c.routes = append(c.routes, route)
+	return route
+}
+
+func setAdmitted(route *routev1.Route, status corev1.ConditionStatus, reason, message string) {
+	route.Status.Ingress = []routev1.RouteIngress{{
+		Host:       route.Spec.Host,
+		RouterName: "default",
+		Conditions: []routev1.RouteIngressCondition{{
+			Type:               routev1.RouteAdmitted,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			LastTransitionTime: &metav1.Time{Time: time.Unix(0, 0)},
+		}},
+	}}
+}